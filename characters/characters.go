@@ -0,0 +1,9 @@
+// Package characters embeds the built-in familiar character definitions.
+package characters
+
+import "embed"
+
+// FS holds the built-in character JSON files, one file per character.
+//
+//go:embed *.json
+var FS embed.FS
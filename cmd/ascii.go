@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/MagikIO/familiar-says/internal/ascii"
+	"github.com/spf13/cobra"
+)
+
+// asciiMode holds the --ascii flag, shared by all commands.
+var asciiMode bool
+
+// setupASCII turns on ASCII mode if --ascii is set. If it was set neither
+// on the command line nor by the config, ASCII mode follows the locale.
+func setupASCII(cmd *cobra.Command) {
+	if !cmd.Flags().Changed("ascii") {
+		asciiMode = ascii.Detect(os.Getenv)
+	}
+	ascii.SetEnabled(asciiMode)
+}
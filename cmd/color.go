@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/MagikIO/familiar-says/internal/canvas"
+	customerrors "github.com/MagikIO/familiar-says/internal/errors"
+	"golang.org/x/term"
+)
+
+// colorMode holds the --color flag, shared by all commands.
+var colorMode string
+
+// setupColor validates --color and sets the color profile output is
+// rendered with. Links in messages become OSC 8 hyperlinks in terminal
+// output if the terminal supports them.
+func setupColor() error {
+	colorMode = strings.ToLower(colorMode)
+	if !slices.Contains(canvas.ColorModes(), colorMode) {
+		return customerrors.NewValidationError("color", colorMode, "must be one of: "+strings.Join(canvas.ColorModes(), ", "))
+	}
+	isTerminal := term.IsTerminal(int(os.Stdout.Fd()))
+	canvas.SetColorMode(colorMode, isTerminal)
+	canvas.SetHyperlinks(outputFormat == outputText && canvas.HyperlinksSupported(isTerminal, os.Getenv))
+	return nil
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MagikIO/familiar-says/internal/canvas"
+	"github.com/MagikIO/familiar-says/internal/character"
+	customerrors "github.com/MagikIO/familiar-says/internal/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Convert flags
+	convertOutput string
+	convertFormat string
+)
+
+var convertCmd = &cobra.Command{
+	Use:   "convert <character>",
+	Short: "Convert a character between JSON and cowsay .cow formats",
+	Long: `Convert a character, such as a classic cowsay .cow file, to the
+familiar-says JSON character format, or export any character to a .cow file
+for use with stock cowsay.
+
+The character can be a file path or the name of any character on the
+search path. The output is written to stdout unless --output is given.
+The format defaults to JSON, or .cow when --output ends in .cow.
+
+Colors and animations cannot be expressed in .cow files; they are dropped
+with a warning.`,
+	Example: `  familiar-says convert tux.cow > tux.json
+  familiar-says convert tux.cow -o ~/.config/familiar-says/characters/tux.json
+  familiar-says convert cat --to cow > cat.cow`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConvert,
+}
+
+func init() {
+	convertCmd.Flags().StringVarP(&convertOutput, "output", "o", "", "File to write the converted character to (default stdout)")
+	convertCmd.Flags().StringVar(&convertFormat, "to", "", "Output format (json, cow)")
+	rootCmd.AddCommand(convertCmd)
+}
+
+func runConvert(cmd *cobra.Command, args []string) error {
+	format := strings.ToLower(convertFormat)
+	if format == "" {
+		format = "json"
+		if strings.EqualFold(filepath.Ext(convertOutput), ".cow") {
+			format = "cow"
+		}
+	}
+	if format != "json" && format != "cow" {
+		return customerrors.NewValidationError("to", convertFormat, "must be json or cow")
+	}
+
+	char, err := character.LoadCharacter(args[0])
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	if format == "cow" {
+		var warnings []string
+		data, warnings = canvas.FormatCow(char)
+		for _, warning := range warnings {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s\n", warning)
+		}
+	} else if data, err = encodeCharacterJSON(char); err != nil {
+		return err
+	}
+
+	if convertOutput == "" {
+		_, err := cmd.OutOrStdout().Write(data)
+		return err
+	}
+	if err := os.WriteFile(convertOutput, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %q: %w", convertOutput, err)
+	}
+	return nil
+}
+
+// encodeCharacterJSON encodes char as indented JSON. HTML escaping is disabled
+// so art characters such as < and > stay readable.
+func encodeCharacterJSON(char *canvas.Character) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(char); err != nil {
+		return nil, fmt.Errorf("failed to encode character %q: %w", char.Name, err)
+	}
+	return buf.Bytes(), nil
+}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/MagikIO/familiar-says/internal/animation"
 	"github.com/MagikIO/familiar-says/internal/bubble"
@@ -15,6 +17,7 @@ import (
 	"github.com/MagikIO/familiar-says/internal/effects"
 	customerrors "github.com/MagikIO/familiar-says/internal/errors"
 	"github.com/MagikIO/familiar-says/internal/personality"
+	"github.com/MagikIO/familiar-says/internal/textwrap"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 )
@@ -37,6 +40,7 @@ var (
 	listCharacters  bool
 	listBubbles     bool
 	multipanel      bool
+	panelSpecs      []string
 
 	// Character color flags
 	outlineColor string
@@ -56,6 +60,13 @@ var (
 	// Code bubble flags
 	codeLanguage string
 	codeStyle    string
+	codeFile     string
+
+	// Text formatting
+	alignName      string
+	renderMarkdown bool
+	maxLines       int
+	overflowName   string
 	
 	// Custom template
 	customTemplate string
@@ -71,13 +82,15 @@ var rootCmd = &cobra.Command{
 - Dynamic colors and visual effects
 - Built-in character familiars (cat, owl, dragon, etc.)
 - Multi-panel layouts`,
-	RunE: runSay,
+	Args:    cobra.ArbitraryArgs,
+	PreRunE: loadConfig,
+	RunE:    runSay,
 }
 
 func init() {
 	// Add flags
-	rootCmd.Flags().StringVarP(&themeName, "theme", "t", "default", "Theme to use (default, rainbow, cyber, retro)")
-	rootCmd.Flags().StringVarP(&moodName, "mood", "m", "neutral", "Mood expression (happy, sad, angry, surprised, bored, excited, neutral, sleepy)")
+	rootCmd.Flags().StringVarP(&themeName, "theme", "t", "default", "Theme to use (default, rainbow, cyber, retro, a custom theme name, or a theme JSON file)")
+	rootCmd.Flags().StringVarP(&moodName, "mood", "m", "neutral", "Mood expression (happy, sad, angry, surprised, bored, excited, neutral, sleepy, or a mood defined by the theme or character)")
 	rootCmd.Flags().StringVarP(&characterName, "character", "c", "", "Character to use (cat, owl, fox, bunny, penguin, dragon, robot, bat, turtle, default)")
 	rootCmd.Flags().IntVarP(&bubbleWidth, "width", "w", 40, "Width of speech bubble")
 	rootCmd.Flags().BoolVarP(&animate, "animate", "a", false, "Enable typing animation")
@@ -91,7 +104,8 @@ func init() {
 	rootCmd.Flags().BoolVarP(&listEffects, "list-effects", "E", false, "List available effects")
 	rootCmd.Flags().BoolVarP(&listCharacters, "list-characters", "C", false, "List available characters")
 	rootCmd.Flags().BoolVar(&listBubbles, "list-bubbles", false, "List available bubble styles")
-	rootCmd.Flags().BoolVarP(&multipanel, "multipanel", "p", false, "Enable multi-panel mode (experimental)")
+	rootCmd.Flags().BoolVarP(&multipanel, "multipanel", "p", false, "Render each |-separated part of the message as its own panel")
+	rootCmd.Flags().StringArrayVar(&panelSpecs, "panel", nil, `Add a panel in the form "character[,key=value...]:text" (keys: mood, bubble-style, outline-color, eye-color, mouth-color); repeatable`)
 
 	// Character color flags
 	rootCmd.Flags().StringVar(&outlineColor, "outline-color", "", "Color for character outline/body (hex, ANSI, or name)")
@@ -111,7 +125,32 @@ func init() {
 	// Code bubble flags
 	rootCmd.Flags().StringVar(&codeLanguage, "code-language", "", "Language for syntax highlighting (go, python, javascript, etc.)")
 	rootCmd.Flags().StringVar(&codeStyle, "code-style", "monokai", "Syntax highlighting theme (monokai, dracula, github, etc.)")
-	
+	rootCmd.Flags().StringVar(&codeFile, "file", "", "Show a source file in a code bubble, detecting the language from its extension")
+
+	// Output flags
+	rootCmd.Flags().StringVar(&outputFormat, "output", outputText, "Output format (text, svg, html, png, gif, markdown, slack, json)")
+	rootCmd.Flags().StringVar(&outputFile, "output-file", "", "Write output to a file instead of stdout")
+	rootCmd.Flags().BoolVar(&htmlStandalone, "html-standalone", false, "Wrap HTML output in a complete page")
+	rootCmd.Flags().BoolVar(&htmlClasses, "html-classes", false, "Style HTML output with CSS classes instead of inline styles")
+	rootCmd.Flags().BoolVar(&chatEmoji, "emoji", false, "Replace unicode decorations with emoji in markdown and slack output")
+	rootCmd.Flags().BoolVar(&slackAPI, "slack-api", false, "Escape &, < and > in slack output for posting through Slack's API")
+	rootCmd.Flags().IntVar(&imageScale, "scale", 2, "Pixel scale for PNG and GIF output")
+	rootCmd.Flags().IntVar(&imagePadding, "padding", 16, "Padding around SVG, PNG and GIF output in pixels")
+	rootCmd.Flags().StringVar(&imageFontFile, "font", "", "TrueType or OpenType font file for characters the embedded PNG and GIF fonts lack, such as CJK")
+	rootCmd.Flags().StringVar(&imageBackground, "background", "", `Background color for exported output (hex, ANSI, name, or "none" for transparent)`)
+	rootCmd.Flags().StringVar(&recordFile, "record", "", `Record the output and animations to an asciicast v2 file ("-" for stdout) instead of playing them`)
+
+	// Color flags, shared with subcommands
+	rootCmd.PersistentFlags().StringVar(&colorMode, "color", canvas.ColorAuto, "Color output (auto, always, never, 16, 256, truecolor)")
+	rootCmd.PersistentFlags().BoolVar(&asciiMode, "ascii", false, "Transliterate unicode decorations to ASCII (default from the locale)")
+	rootCmd.PersistentFlags().BoolVar(&hyphenate, "hyphenate", false, "Mark words broken across bubble lines with a hyphen")
+
+	// Text formatting
+	rootCmd.Flags().StringVar(&alignName, "align", "", "Text alignment in the bubble (left, center, right, justify; default from the bubble template)")
+	rootCmd.Flags().BoolVar(&renderMarkdown, "markdown", false, "Render inline Markdown (bold, italic, code, strikethrough, lists) in the message")
+	rootCmd.Flags().IntVar(&maxLines, "max-lines", 0, "Lines of text in the bubble before it overflows (0 = no limit)")
+	rootCmd.Flags().StringVar(&overflowName, "overflow", canvas.OverflowTruncate, "What to do with text past --max-lines (truncate, split, page)")
+
 	// Custom template
 	rootCmd.Flags().StringVar(&customTemplate, "custom-bubble", "", "Path to custom bubble template JSON file or template name in ~/.config/familiar-says/bubbles/")
 }
@@ -121,7 +160,10 @@ func Execute() error {
 	return rootCmd.Execute()
 }
 
-func runSay(cmd *cobra.Command, args []string) error {
+// loadConfig applies the config file, with the --profile profile, and
+// FAMILIAR_SAYS_* environment variables to the flags of cmd that were not
+// set on the command line. Settings for flags cmd does not have are skipped.
+func loadConfig(cmd *cobra.Command, args []string) error {
 	// Load config file if it exists
 	cfg, loadErr := config.Load()
 	if loadErr != nil {
@@ -148,20 +190,39 @@ func runSay(cmd *cobra.Command, args []string) error {
 	config.ApplyToFlags(mergedConfig, cmd)
 
 	// CLI flags already have highest precedence (handled by cobra)
+	return nil
+}
 
+func runSay(cmd *cobra.Command, args []string) error {
 	// Handle list commands
 	if listThemes {
+		themes := personality.AllThemes()
+		warnUserThemes()
 		fmt.Println("Available themes:")
-		for _, t := range personality.AllThemes() {
-			fmt.Printf("  - %s\n", t)
+		for _, t := range themes {
+			if personality.IsBuiltinTheme(t) {
+				fmt.Printf("  - %s\n", t)
+			} else {
+				fmt.Printf("  - %s (custom)\n", t)
+			}
 		}
 		return nil
 	}
 
 	if listMoods {
-		fmt.Println("Available moods:")
-		for _, m := range personality.AllMoods() {
-			fmt.Printf("  - %s\n", m)
+		theme, err := personality.GetOrLoadTheme(themeName)
+		warnUserThemes()
+		if err != nil {
+			return err
+		}
+		char, err := loadSelectedCharacter()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Available moods for %s with theme %s:\n", char.Name, theme.Name)
+		for _, m := range character.AvailableMoods(char, theme) {
+			expr := character.ResolveExpression(char, theme, m)
+			fmt.Printf("  - %-10s eyes %q, tongue %q\n", m, expr.Eyes, expr.Tongue)
 		}
 		return nil
 	}
@@ -176,8 +237,16 @@ func runSay(cmd *cobra.Command, args []string) error {
 
 	if listCharacters {
 		fmt.Println("Available characters:")
-		for _, c := range character.ListCharacters() {
-			fmt.Printf("  - %s\n", c)
+		for _, entry := range character.ListEntries() {
+			line := fmt.Sprintf("  - %s (%s)", entry.Name, entry.Source)
+			if len(entry.Shadows) > 0 {
+				shadowed := make([]string, len(entry.Shadows))
+				for i, src := range entry.Shadows {
+					shadowed[i] = src.String()
+				}
+				line += fmt.Sprintf(" [shadows %s]", strings.Join(shadowed, ", "))
+			}
+			fmt.Println(line)
 		}
 		return nil
 	}
@@ -220,11 +289,39 @@ func runSay(cmd *cobra.Command, args []string) error {
 	if err := validateFlags(); err != nil {
 		return fmt.Errorf("invalid flags: %w", err)
 	}
+	if err := setupColor(); err != nil {
+		return fmt.Errorf("invalid flags: %w", err)
+	}
+	setupASCII(cmd)
+	setupWrap()
 
 	// Get message
 	var message string
-	if len(args) > 0 {
+	messageGiven := true
+	if codeFile != "" {
+		data, err := os.ReadFile(codeFile)
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+		message = strings.TrimRight(string(data), "\n")
+		if codeLanguage == "" {
+			codeLanguage = bubble.LanguageForFile(codeFile)
+		}
+		// Files are shown as code unless another bubble style was chosen
+		if !cmd.Flags().Changed("bubble-style") {
+			bubbleStyleName = "code"
+		}
+	} else if len(args) > 0 {
 		message = strings.Join(args, " ")
+	} else if len(panelSpecs) > 0 {
+		// Panels carry their own text; only read stdin if it is piped
+		messageGiven = false
+		if stat, err := os.Stdin.Stat(); err == nil && (stat.Mode()&os.ModeCharDevice) == 0 {
+			if data, err := io.ReadAll(os.Stdin); err == nil {
+				message = pipedMessage(data)
+				messageGiven = message != ""
+			}
+		}
 	} else {
 		// Read from stdin if available
 		stat, err := os.Stdin.Stat()
@@ -238,7 +335,7 @@ func runSay(cmd *cobra.Command, args []string) error {
 				fmt.Fprintf(os.Stderr, "Warning: failed to read stdin: %v\n", err)
 				message = "Hello from familiar-says!"
 			} else {
-				message = strings.TrimSpace(string(data))
+				message = pipedMessage(data)
 				if message == "" {
 					message = "Hello from familiar-says!"
 				}
@@ -249,8 +346,12 @@ func runSay(cmd *cobra.Command, args []string) error {
 	}
 
 	// Get theme and mood
-	theme := personality.GetTheme(themeName)
-	mood := personality.Mood(moodName)
+	theme, err := personality.GetOrLoadTheme(themeName)
+	warnUserThemes()
+	if err != nil {
+		return err
+	}
+	mood := personality.Mood(strings.ToLower(moodName))
 
 	// Create renderer
 	renderer := character.NewRenderer(theme, mood, bubbleWidth)
@@ -268,6 +369,12 @@ func runSay(cmd *cobra.Command, args []string) error {
 	if customTemplate != "" {
 		renderer.CustomTemplate = customTemplate
 	}
+	renderer.CodeLanguage = codeLanguage
+	renderer.CodeStyle = codeStyle
+	renderer.Align = textAlignment()
+	renderer.Markdown = renderMarkdown
+	renderer.MaxLines = maxLines
+	renderer.Overflow = bubbleOverflow()
 
 	// Determine bubble style (--think is deprecated, --bubble-style takes precedence)
 	bubbleStyleVal := bubble.ParseStyle(bubbleStyleName)
@@ -302,24 +409,23 @@ func runSay(cmd *cobra.Command, args []string) error {
 		canvasBubbleStyle = canvas.BubbleStyleCode
 	}
 
-	// Get expression for mood
-	expr := theme.GetExpression(mood)
-
 	// Check if character animation is requested
-	wantCharAnim := (actionName != "" && actionName != "none") || idleAnim
+	panelMode := multipanel || len(panelSpecs) > 0
+	exporting := outputFormat != outputText
+	wantCharAnim := ((actionName != "" && actionName != "none") || idleAnim) && !panelMode && (!exporting || exportAnimates())
 
 	// Load character if specified
-	var char *canvas.Character
-	if characterName != "" {
-		var loadErr error
-		char, loadErr = character.LoadCharacter(characterName)
-		if loadErr != nil {
-			return fmt.Errorf("failed to load character: %w", loadErr)
-		}
-	} else {
-		char, _ = canvas.GetBuiltinCharacter("default")
+	char, err := loadSelectedCharacter()
+	if err != nil {
+		return err
 	}
 
+	// Get expression for mood, preferring the character's own expressions
+	if !panelMode {
+		warnUnknownMood(char, theme, mood)
+	}
+	expr := renderer.Expression(char)
+
 	// Handle character animation mode
 	if wantCharAnim {
 		// Determine which animation to use
@@ -344,33 +450,25 @@ func runSay(cmd *cobra.Command, args []string) error {
 		}
 
 		if anim != nil {
-			// Configure character animation
-			config := animation.CharacterAnimationConfig{
-				Character:    char,
-				Animation:    anim,
-				BubbleText:   message,
-				BubbleWidth:  bubbleWidth,
-				BubbleStyle:  canvasBubbleStyle,
-				BubbleColor:  theme.BubbleStyle,
-				CharColor:    theme.CharacterStyle,
-				DefaultEyes:  expr.Eyes,
-				DefaultMouth: expr.Tongue,
-				Duration:     time.Duration(animDuration) * time.Millisecond,
-				Effect:       effects.Effect(effect),
+			config := characterAnimationConfig(char, anim, message, canvasBubbleStyle, tailDir, theme, expr)
+
+			// Export the animation frames instead of playing them
+			if exporting {
+				warnUnsupportedExport()
+				return writeAnimationExport(config, anim.Loop)
 			}
 
-			// Apply character color overrides
-			if outlineColor != "" || eyeColor != "" || mouthColor != "" {
-				config.CharColors = &canvas.CharacterColors{
-					Outline: outlineColor,
-					Eyes:    eyeColor,
-					Mouth:   mouthColor,
-				}
+			// Record the animation on a virtual clock instead of playing it
+			if recordFile != "" {
+				return writeRecording(animation.RecordViews(config))
 			}
 
-			// Enable typing animation if --animate is set
-			if animate {
-				config.TypingSpeed = time.Duration(animSpeed) * time.Millisecond
+			// Page through long text while the character animates
+			if pageInteractively(panelMode, config) {
+				if err := animation.PlayPager(config); err != nil {
+					return fmt.Errorf("pager failed: %w", err)
+				}
+				return nil
 			}
 
 			// Run the character animation
@@ -382,15 +480,57 @@ func runSay(cmd *cobra.Command, args []string) error {
 		// Fall through to static rendering if no animation found
 	}
 
+	// Page through long text with the still character
+	if config := characterAnimationConfig(char, nil, message, canvasBubbleStyle, tailDir, theme, expr); pageInteractively(panelMode, config) {
+		if err := animation.PlayPager(config); err != nil {
+			return fmt.Errorf("pager failed: %w", err)
+		}
+		return nil
+	}
+
 	// Static rendering path (original behavior)
-	var output []string
-	output = renderer.RenderWithTailDirection(message, char, bubbleStyleVal, tailDir)
+	var scene *canvas.Canvas
+	var scenes []character.SceneInfo
+	if panelMode {
+		scene, scenes, err = composePanels(renderer, message, messageGiven, bubbleStyleVal)
+		if err != nil {
+			return err
+		}
+	} else {
+		var info character.SceneInfo
+		scene, info = renderer.ComposeScene(message, char, bubbleStyleVal, tailDir)
+		scenes = []character.SceneInfo{info}
+	}
+
+	// Export formats render the composed cells directly
+	if exporting {
+		warnUnsupportedExport()
+		if (actionName != "" && actionName != "none") || idleAnim {
+			if !exportAnimates() {
+				fmt.Fprintf(os.Stderr, "Warning: character animations are not supported with --output %s and will be ignored\n", outputFormat)
+			}
+		}
+		if animate && exportTypes() && !panelMode {
+			// Record the typing animation of the still character
+			config := characterAnimationConfig(char, nil, message, canvasBubbleStyle, tailDir, theme, expr)
+			return writeAnimationExport(config, false)
+		}
+		return writeExport(scene, theme.Name, scenes)
+	}
+	output := scene.Render()
 
 	// Apply visual effects (for effects that apply to full output)
 	effectType := effects.Effect(effect)
 	output = effects.Apply(output, effectType)
 
 	// Handle typing animation
+	if recordFile != "" {
+		animType := animation.AnimationNone
+		if animate {
+			animType = animation.AnimationTyping
+		}
+		return writeRecording(animation.RecordTyping(output, animType, time.Duration(animSpeed)*time.Millisecond))
+	}
 	if animate {
 		speed := time.Duration(animSpeed) * time.Millisecond
 		if err := animation.Animate(output, animation.AnimationTyping, speed); err != nil {
@@ -405,6 +545,124 @@ func runSay(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// characterAnimationConfig configures the character animation anim, or a
+// still character if anim is nil, from the command-line flags.
+func characterAnimationConfig(char *canvas.Character, anim *canvas.AnimationSequence, message string, bubbleStyle canvas.BubbleStyle, tailDir canvas.TailDirection, theme personality.Theme, expr personality.Expression) animation.CharacterAnimationConfig {
+	config := animation.CharacterAnimationConfig{
+		Character:      char,
+		Animation:      anim,
+		BubbleText:     message,
+		BubbleWidth:    bubbleWidth,
+		BubbleStyle:    bubbleStyle,
+		BubbleColor:    theme.BubbleStyle,
+		CharColor:      theme.CharacterStyle,
+		DefaultEyes:    expr.Eyes,
+		DefaultMouth:   expr.Tongue,
+		Duration:       time.Duration(animDuration) * time.Millisecond,
+		Effect:         effects.Effect(effect),
+		CodeLanguage:   codeLanguage,
+		CodeStyle:      codeStyle,
+		Align:          textAlignment(),
+		Markdown:       renderMarkdown,
+		MaxLines:       maxLines,
+		Overflow:       bubbleOverflow(),
+		TailDirection:  tailDir,
+		CustomTemplate: customTemplate,
+	}
+
+	// Apply character color overrides
+	if outlineColor != "" || eyeColor != "" || mouthColor != "" {
+		config.CharColors = &canvas.CharacterColors{
+			Outline: outlineColor,
+			Eyes:    eyeColor,
+			Mouth:   mouthColor,
+		}
+	}
+
+	// Enable typing animation if --animate is set
+	if animate {
+		config.TypingSpeed = time.Duration(animSpeed) * time.Millisecond
+	}
+	return config
+}
+
+// pipedMessage returns the message read from stdin without its leading and
+// trailing blank lines, keeping the indentation of its first line, or "" if
+// it is blank.
+func pipedMessage(data []byte) string {
+	message := strings.TrimRightFunc(string(data), unicode.IsSpace)
+	start := strings.IndexFunc(message, func(r rune) bool { return !unicode.IsSpace(r) })
+	if start < 0 {
+		return ""
+	}
+	return message[strings.LastIndexByte(message[:start], '\n')+1:]
+}
+
+// warnUserThemes warns about theme files in the user's config directory
+// that could not be loaded.
+func warnUserThemes() {
+	if err := personality.UserThemesError(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// warnUnknownMood warns that char falls back to the neutral expression if
+// neither theme nor char defines mood.
+func warnUnknownMood(char *canvas.Character, theme personality.Theme, mood personality.Mood) {
+	if !character.HasMood(char, theme, mood) {
+		fmt.Fprintf(os.Stderr, "Warning: mood '%s' is not defined by theme '%s' or character '%s', using neutral. Use --list-moods to see available moods\n", mood, theme.Name, char.Name)
+	}
+}
+
+// loadSelectedCharacter loads the character chosen with --character, or the
+// default character if none was given.
+func loadSelectedCharacter() (*canvas.Character, error) {
+	if characterName == "" {
+		char, _ := canvas.GetBuiltinCharacter("default")
+		return char, nil
+	}
+	char, err := character.LoadCharacter(characterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load character: %w", err)
+	}
+	return char, nil
+}
+
+// composePanels composes the --panel flags, followed by the |-separated parts of
+// the message, side by side. Panels default to the global character, mood,
+// bubble style and colors. It also returns the scene of each panel.
+func composePanels(renderer *character.Renderer, message string, messageGiven bool, style bubble.Style) (*canvas.Canvas, []character.SceneInfo, error) {
+	base := character.Panel{CharacterName: characterName, Style: style}
+
+	var panels []character.Panel
+	for _, spec := range panelSpecs {
+		panel, err := parsePanelSpec(spec, base)
+		if err != nil {
+			return nil, nil, err
+		}
+		panels = append(panels, panel)
+	}
+	if messageGiven {
+		messagePanels, err := splitMessagePanels(message, base)
+		if err != nil {
+			return nil, nil, err
+		}
+		panels = append(panels, messagePanels...)
+	}
+
+	warnUnknownPanelMoods(panels, renderer)
+
+	if term.IsTerminal(int(os.Stdout.Fd())) {
+		renderer.BubbleWidth = panelBubbleWidth(bubbleWidth, len(panels), getTerminalWidth())
+	}
+
+	result, scenes, err := renderer.ComposeMultiPanelScenes(panels)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load character: %w", err)
+	}
+	return result, scenes, nil
+}
+
 // validateFlags validates command-line flags
 func validateFlags() error {
 	// Validate width
@@ -444,7 +702,31 @@ func validateFlags() error {
 		return customerrors.NewValidationError("duration", animDuration, "must be non-negative")
 	}
 
-	return nil
+	// Validate syntax highlighting options
+	if codeLanguage != "" && !bubble.IsValidLanguage(codeLanguage) {
+		return customerrors.NewValidationError("code-language", codeLanguage, "unknown language")
+	}
+	if codeStyle != "" && !bubble.IsValidStyle(codeStyle) {
+		return customerrors.NewValidationError("code-style", codeStyle, "unknown highlighting style")
+	}
+
+	// Validate text alignment
+	if _, ok := textwrap.ParseAlignment(alignName); !ok {
+		return customerrors.NewValidationError("align", alignName, "must be left, center, right or justify")
+	}
+
+	// Validate overflow
+	if maxLines < 0 {
+		return customerrors.NewValidationError("max-lines", maxLines, "must be non-negative")
+	}
+	if maxLines == 1 && overflowName == canvas.OverflowTruncate {
+		return customerrors.NewValidationError("max-lines", maxLines, "must be at least 2 to truncate, leaving a line for the ellipsis")
+	}
+	if !slices.Contains(canvas.Overflows(), overflowName) {
+		return customerrors.NewValidationError("overflow", overflowName, "must be one of: "+strings.Join(canvas.Overflows(), ", "))
+	}
+
+	return validateOutputFlags()
 }
 
 // getTerminalWidth attempts to detect the terminal width, falling back to a default
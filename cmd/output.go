@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/MagikIO/familiar-says/internal/animation"
+	"github.com/MagikIO/familiar-says/internal/asciicast"
+	"github.com/MagikIO/familiar-says/internal/canvas"
+	"github.com/MagikIO/familiar-says/internal/character"
+	customerrors "github.com/MagikIO/familiar-says/internal/errors"
+	"github.com/MagikIO/familiar-says/internal/export"
+	"golang.org/x/term"
+)
+
+// Output formats for --output
+const (
+	outputText = "text"
+	outputSVG  = "svg"
+	outputHTML = "html"
+	outputPNG  = "png"
+	outputGIF  = "gif"
+
+	outputMarkdown = "markdown"
+	outputSlack    = "slack"
+	outputJSON     = "json"
+)
+
+// outputFormats lists the values accepted by --output.
+var outputFormats = []string{outputText, outputSVG, outputHTML, outputPNG, outputGIF, outputMarkdown, outputSlack, outputJSON}
+
+var (
+	// Output flags
+	outputFormat   string
+	outputFile     string
+	htmlStandalone bool
+	htmlClasses    bool
+	chatEmoji      bool
+	slackAPI       bool
+
+	// Image flags
+	imageScale      int
+	imagePadding    int
+	imageBackground string
+	imageFontFile   string
+	imageFonts      []*export.Font // Loaded from --font
+
+	// Recording flags
+	recordFile string
+)
+
+// validateOutputFlags checks the --output flag.
+func validateOutputFlags() error {
+	outputFormat = strings.ToLower(outputFormat)
+	if !slices.Contains(outputFormats, outputFormat) {
+		return customerrors.NewValidationError("output", outputFormat, "must be one of: "+strings.Join(outputFormats, ", "))
+	}
+	if imageScale < 1 || imageScale > 16 {
+		return customerrors.NewValidationError("scale", imageScale, "must be between 1 and 16")
+	}
+	if imagePadding < 0 {
+		return customerrors.NewValidationError("padding", imagePadding, "must be non-negative")
+	}
+	if imageBackground != "" && imageBackground != "none" && !canvas.ValidateColor(imageBackground) {
+		return customerrors.NewColorParseError(imageBackground, nil)
+	}
+	if imageFontFile != "" {
+		if outputFormat != outputPNG && outputFormat != outputGIF {
+			return customerrors.NewValidationError("font", imageFontFile, "only applies to --output png or gif")
+		}
+		f, err := export.LoadFont(imageFontFile)
+		if err != nil {
+			return fmt.Errorf("failed to load font: %w", err)
+		}
+		imageFonts = []*export.Font{f}
+	}
+	if chatEmoji && outputFormat != outputMarkdown && outputFormat != outputSlack {
+		return customerrors.NewValidationError("emoji", chatEmoji, "only applies to --output markdown or slack")
+	}
+	if slackAPI && outputFormat != outputSlack {
+		return customerrors.NewValidationError("slack-api", slackAPI, "only applies to --output slack")
+	}
+	if recordFile != "" && outputFormat != outputText {
+		return customerrors.NewValidationError("record", recordFile, "cannot be combined with --output")
+	}
+	return nil
+}
+
+// exportBackground resolves --background to a hex color, "none", or "" for
+// the format's default.
+func exportBackground() string {
+	if imageBackground == "" || imageBackground == "none" {
+		return imageBackground
+	}
+	return export.ColorHex(canvas.ParseColor(imageBackground))
+}
+
+// exportPadding converts --padding to the export options' convention, where
+// zero means the default and negative means none.
+func exportPadding() int {
+	if imagePadding == 0 {
+		return -1
+	}
+	return imagePadding
+}
+
+// exportAnimates reports whether the --output format can show character
+// animations.
+func exportAnimates() bool {
+	return outputFormat == outputHTML || outputFormat == outputGIF
+}
+
+// exportTypes reports whether the --output format can show the typing
+// animation.
+func exportTypes() bool {
+	return outputFormat == outputGIF
+}
+
+// warnUnsupportedExport warns about effects and typing, which the --output
+// format cannot show.
+func warnUnsupportedExport() {
+	if effect != "" && effect != "none" {
+		fmt.Fprintf(os.Stderr, "Warning: effects are not supported with --output %s and will be ignored\n", outputFormat)
+	}
+	if animate && !exportTypes() {
+		fmt.Fprintf(os.Stderr, "Warning: typing animation is not supported with --output %s and will be ignored\n", outputFormat)
+	}
+}
+
+// pngOptions returns the image export options set by flags.
+func pngOptions() export.PNGOptions {
+	return export.PNGOptions{Scale: imageScale, Padding: exportPadding(), Background: exportBackground(), Fonts: imageFonts}
+}
+
+// htmlOptions returns the HTML export options set by flags.
+func htmlOptions() export.HTMLOptions {
+	return export.HTMLOptions{Standalone: htmlStandalone, Classes: htmlClasses, Background: exportBackground()}
+}
+
+// writeExport writes the composed canvas in the chosen --output format to
+// --output-file, or stdout if none was given. The theme name and scenes of
+// the composed characters describe the canvas in JSON output.
+func writeExport(c *canvas.Canvas, theme string, scenes []character.SceneInfo) error {
+	if outputFormat == outputPNG || outputFormat == outputGIF {
+		warnMissingGlyphs(c)
+	}
+	return writeOutputFile(func(w io.Writer) error {
+		switch outputFormat {
+		case outputSVG:
+			return export.WriteSVG(w, c, export.SVGOptions{Padding: float64(exportPadding()), Background: exportBackground()})
+		case outputHTML:
+			return export.WriteHTML(w, c, htmlOptions())
+		case outputPNG:
+			return export.WritePNG(w, c, pngOptions())
+		case outputGIF:
+			return export.WriteGIF(w, []export.Frame{{Canvas: c}}, false, pngOptions())
+		case outputMarkdown:
+			return export.WriteMarkdown(w, c, export.ChatOptions{Emoji: chatEmoji})
+		case outputSlack:
+			return export.WriteSlack(w, c, export.ChatOptions{Emoji: chatEmoji, SlackAPI: slackAPI})
+		case outputJSON:
+			return export.WriteJSON(w, c, theme, jsonPanels(scenes))
+		}
+		return nil
+	})
+}
+
+// warnMissingGlyphs warns about characters in canvases that image output
+// draws as placeholder boxes because no font has them.
+func warnMissingGlyphs(canvases ...*canvas.Canvas) {
+	var missing []rune
+	for _, c := range canvases {
+		for _, r := range export.MissingGlyphs(c, pngOptions()) {
+			if !slices.Contains(missing, r) {
+				missing = append(missing, r)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: no font has glyphs for %q, they are drawn as boxes in %s output. Use --font to load a font that has them\n", string(missing), outputFormat)
+	}
+}
+
+// jsonPanels converts composed scenes to JSON panel descriptions.
+func jsonPanels(scenes []character.SceneInfo) []export.JSONPanel {
+	panels := make([]export.JSONPanel, len(scenes))
+	for i, scene := range scenes {
+		panels[i] = export.JSONPanel{
+			Character: scene.Character,
+			Mood:      string(scene.Mood),
+			Template:  scene.Template,
+			Bounds:    export.NewJSONBounds(scene.Layout),
+		}
+	}
+	return panels
+}
+
+// writeAnimationExport writes a character animation in the chosen --output
+// format. GIFs are recorded tick by tick, typing included, while HTML cycles
+// the animation's own frames.
+func writeAnimationExport(config animation.CharacterAnimationConfig, loop bool) error {
+	var frames []animation.SceneFrame
+	if outputFormat == outputGIF {
+		frames = animation.Record(config)
+	} else {
+		frames = animation.NewCharacterModel(config).Frames()
+	}
+
+	exported := make([]export.Frame, len(frames))
+	canvases := make([]*canvas.Canvas, len(frames))
+	for i, frame := range frames {
+		exported[i] = export.Frame{Canvas: frame.Canvas, Duration: frame.Duration}
+		canvases[i] = frame.Canvas
+	}
+	if outputFormat == outputGIF {
+		warnMissingGlyphs(canvases...)
+	}
+	return writeOutputFile(func(w io.Writer) error {
+		if outputFormat == outputGIF {
+			return export.WriteGIF(w, exported, loop, pngOptions())
+		}
+		return export.WriteHTMLAnimation(w, exported, loop, htmlOptions())
+	})
+}
+
+// writeOutputFile calls write with --output-file, or stdout if none was
+// given.
+func writeOutputFile(write func(w io.Writer) error) error {
+	w := io.Writer(os.Stdout)
+	if (outputFile == "" || outputFile == "-") && (outputFormat == outputPNG || outputFormat == outputGIF) && term.IsTerminal(int(os.Stdout.Fd())) {
+		return fmt.Errorf("refusing to write %s data to a terminal, use --output-file or redirect the output", outputFormat)
+	}
+	if outputFile != "" && outputFile != "-" {
+		f, err := os.Create(outputFile)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := write(w); err != nil {
+		return fmt.Errorf("failed to write %s output: %w", outputFormat, err)
+	}
+	return nil
+}
+
+// writeRecording writes recorded frames to --record as an asciicast v2
+// file, or to stdout for "-".
+func writeRecording(frames []animation.ViewFrame) error {
+	cast := make([]asciicast.Frame, len(frames))
+	for i, frame := range frames {
+		cast[i] = asciicast.Frame{View: frame.View, Duration: frame.Duration}
+	}
+
+	w := io.Writer(os.Stdout)
+	if recordFile != "-" {
+		f, err := os.Create(recordFile)
+		if err != nil {
+			return fmt.Errorf("failed to create recording file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := asciicast.Write(w, cast, "familiar-says"); err != nil {
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/MagikIO/familiar-says/internal/bubble"
+	"github.com/MagikIO/familiar-says/internal/canvas"
+	"github.com/MagikIO/familiar-says/internal/character"
+	customerrors "github.com/MagikIO/familiar-says/internal/errors"
+	"github.com/MagikIO/familiar-says/internal/personality"
+)
+
+// panelSeparator splits a message into panels in --multipanel mode.
+const panelSeparator = "|"
+
+// minPanelBubbleWidth is the narrowest bubble a panel is squeezed to.
+const minPanelBubbleWidth = 10
+
+// parsePanelSpec parses a --panel value of the form
+// "character[,key=value...]:text". Supported keys are mood, bubble-style,
+// outline-color, eye-color and mouth-color. Unset values come from base.
+func parsePanelSpec(spec string, base character.Panel) (character.Panel, error) {
+	head, text, ok := strings.Cut(spec, ":")
+	if !ok {
+		return base, customerrors.NewValidationError("panel", spec, `must be in the form "character[,key=value...]:text"`)
+	}
+
+	panel := base
+	panel.Text = strings.TrimSpace(text)
+
+	parts := strings.Split(head, ",")
+	if name := strings.TrimSpace(parts[0]); name != "" {
+		panel.CharacterName = name
+	}
+
+	for _, opt := range parts[1:] {
+		key, value, ok := strings.Cut(opt, "=")
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		if !ok || value == "" {
+			return base, customerrors.NewValidationError("panel", spec, fmt.Sprintf("option %q must be key=value", opt))
+		}
+
+		switch key {
+		case "mood":
+			panel.Mood = personality.Mood(strings.ToLower(value))
+		case "bubble-style", "style":
+			if !slices.Contains(bubble.AllStyles(), strings.ToLower(value)) {
+				return base, customerrors.NewValidationError("panel bubble-style", value, "unknown bubble style. Use --list-bubbles to see available styles")
+			}
+			panel.Style = bubble.ParseStyle(strings.ToLower(value))
+		case "outline-color", "eye-color", "mouth-color":
+			if !canvas.ValidateColor(value) {
+				return base, customerrors.NewColorParseError(value, customerrors.ErrInvalidColorFormat)
+			}
+			colors := canvas.MergeColors(panel.Colors, nil)
+			if colors == nil {
+				colors = &canvas.CharacterColors{}
+			}
+			switch key {
+			case "outline-color":
+				colors.Outline = value
+			case "eye-color":
+				colors.Eyes = value
+			default:
+				colors.Mouth = value
+			}
+			panel.Colors = colors
+		default:
+			return base, customerrors.NewValidationError("panel", spec, fmt.Sprintf("unknown option %q (use mood, bubble-style, outline-color, eye-color or mouth-color)", key))
+		}
+	}
+
+	return panel, nil
+}
+
+// splitMessagePanels splits a --multipanel message on "|". A segment that
+// starts with a panel spec naming a known character, such as "owl:Hoot" or
+// "cat,mood=happy:Hi", is parsed like --panel; other segments are plain text
+// for the base panel settings.
+func splitMessagePanels(message string, base character.Panel) ([]character.Panel, error) {
+	var panels []character.Panel
+	for _, segment := range strings.Split(message, panelSeparator) {
+		segment = strings.TrimSpace(segment)
+		if head, _, ok := strings.Cut(segment, ":"); ok && isPanelHead(head) {
+			panel, err := parsePanelSpec(segment, base)
+			if err != nil {
+				return nil, err
+			}
+			panels = append(panels, panel)
+			continue
+		}
+
+		panel := base
+		panel.Text = segment
+		panels = append(panels, panel)
+	}
+	return panels, nil
+}
+
+// warnUnknownPanelMoods warns once about each character and mood of panels
+// that falls back to the neutral expression, like a single character.
+func warnUnknownPanelMoods(panels []character.Panel, renderer *character.Renderer) {
+	warned := make(map[[2]string]bool)
+	for _, panel := range panels {
+		name, mood := panel.CharacterName, renderer.Mood
+		if name == "" {
+			name = "default"
+		}
+		if panel.Mood != "" {
+			mood = panel.Mood
+		}
+		key := [2]string{name, string(mood)}
+		char, err := character.LoadCharacter(name)
+		if err != nil || warned[key] {
+			continue // Load errors are reported when composing
+		}
+		warned[key] = true
+		warnUnknownMood(char, renderer.Theme, mood)
+	}
+}
+
+// isPanelHead reports whether head looks like the character part of a panel
+// spec rather than ordinary text ending in a colon.
+func isPanelHead(head string) bool {
+	if head == "" || strings.ContainsAny(head, " \t") {
+		return false
+	}
+	name, _, _ := strings.Cut(head, ",")
+	return character.Exists(name)
+}
+
+// panelBubbleWidth returns the bubble width for n panels side by side,
+// shrinking the requested width so the panels fit within the terminal.
+func panelBubbleWidth(requested, n, terminalWidth int) int {
+	if n <= 1 {
+		return requested
+	}
+
+	// Each bubble adds a border and padding of two columns per side
+	width := (terminalWidth-canvas.PanelGap*(n-1))/n - 4
+	if width > requested {
+		width = requested
+	}
+	if width < minPanelBubbleWidth {
+		width = minPanelBubbleWidth
+	}
+	return width
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MagikIO/familiar-says/internal/animation"
+	"github.com/MagikIO/familiar-says/internal/bubble"
+	"github.com/MagikIO/familiar-says/internal/character"
+	"github.com/MagikIO/familiar-says/internal/dialogue"
+	customerrors "github.com/MagikIO/familiar-says/internal/errors"
+	"github.com/MagikIO/familiar-says/internal/personality"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	// Play flags
+	playTheme       string
+	playWidth       int
+	playSpeed       int
+	playPause       int
+	playBubbleStyle string
+	playKeepBubbles bool
+	playIdle        bool
+)
+
+var playCmd = &cobra.Command{
+	Use:   "play <script>",
+	Short: "Play a dialogue script between several characters",
+	Long: `Play a dialogue script: the characters stand side by side and speak in
+turn, each line typed out in the speaker's bubble.
+
+A script has one line per row in the form SPEAKER [MOOD] (ACTION): TEXT,
+where the mood and action are optional:
+
+  # Lines starting with # are comments
+  @title First day at the guild
+  @cast Whiskers = cat
+  @cast Sage = owl
+
+  Whiskers [happy] (wave): Welcome aboard!
+  Sage [wise]: Let me show you around.
+      Indented rows continue the previous line.
+
+@cast maps a speaker to a character name or file; speakers without one use
+their lowercased name as the character.
+
+Keys: space pauses, n/enter/right shows the next line, b/left goes back,
+q quits. When output is not a terminal, each step is printed in turn.`,
+	Example: `  familiar-says play skit.txt
+  familiar-says play skit.txt --pause 0 --keep-bubbles
+  familiar-says play skit.txt --theme cyber --idle`,
+	Args:    cobra.ExactArgs(1),
+	PreRunE: loadConfig,
+	RunE:    runPlay,
+}
+
+func init() {
+	playCmd.Flags().StringVarP(&playTheme, "theme", "t", "default", "Theme to use (default, rainbow, cyber, retro, a custom theme name, or a theme JSON file)")
+	playCmd.Flags().IntVarP(&playWidth, "width", "w", 30, "Width of each speech bubble")
+	playCmd.Flags().IntVarP(&playSpeed, "speed", "s", 40, "Typing speed in milliseconds per character (0 = no typing)")
+	playCmd.Flags().IntVar(&playPause, "pause", 1500, "Milliseconds to wait after each line before the next (0 = wait for a key)")
+	playCmd.Flags().StringVar(&playBubbleStyle, "bubble-style", "say", "Bubble style (say, think, shout, whisper, song, code)")
+	playCmd.Flags().BoolVar(&playKeepBubbles, "keep-bubbles", false, "Keep each character's last line on screen while others speak")
+	playCmd.Flags().BoolVar(&playIdle, "idle", false, "Play idle animations while characters are not acting")
+	playCmd.Flags().StringVar(&profileName, "profile", "", "Configuration profile to use")
+	rootCmd.AddCommand(playCmd)
+}
+
+func runPlay(cmd *cobra.Command, args []string) error {
+	if playWidth <= 0 || playWidth > 1000 {
+		return customerrors.NewValidationError("width", playWidth, "must be between 1 and 1000")
+	}
+	if playSpeed < 0 || playSpeed > 10000 {
+		return customerrors.NewValidationError("speed", playSpeed, "must be between 0 and 10000ms")
+	}
+	if playPause < 0 {
+		return customerrors.NewValidationError("pause", playPause, "must be non-negative")
+	}
+	style := strings.ToLower(playBubbleStyle)
+	if !slices.Contains(bubble.AllStyles(), style) {
+		return customerrors.NewValidationError("bubble-style", playBubbleStyle, "unknown bubble style. Use --list-bubbles to see available styles")
+	}
+	if err := setupColor(); err != nil {
+		return err
+	}
+	setupASCII(cmd)
+	setupWrap()
+
+	theme, err := personality.GetOrLoadTheme(playTheme)
+	warnUserThemes()
+	if err != nil {
+		return err
+	}
+
+	script, err := dialogue.ParseFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	interactive := term.IsTerminal(int(os.Stdout.Fd()))
+	width := playWidth
+	if interactive {
+		width = panelBubbleWidth(playWidth, len(script.Cast), getTerminalWidth())
+	}
+
+	config, warnings, err := dialogue.Build(script, dialogue.Options{
+		Theme:       theme,
+		BubbleWidth: width,
+		BubbleStyle: character.BubbleStyleToCanvasStyle(bubble.ParseStyle(style)),
+		TypingSpeed: time.Duration(playSpeed) * time.Millisecond,
+		LinePause:   time.Duration(playPause) * time.Millisecond,
+		KeepBubbles: playKeepBubbles,
+		Idle:        playIdle,
+	})
+	if err != nil {
+		return err
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+
+	if !interactive {
+		for i, scene := range animation.DialogueScenes(config) {
+			if i > 0 {
+				fmt.Println()
+			}
+			for _, line := range scene {
+				fmt.Println(line)
+			}
+		}
+		return nil
+	}
+
+	if err := animation.PlayDialogue(config); err != nil {
+		return fmt.Errorf("dialogue playback failed: %w", err)
+	}
+	return nil
+}
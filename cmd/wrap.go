@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/MagikIO/familiar-says/internal/animation"
+	"github.com/MagikIO/familiar-says/internal/canvas"
+	"github.com/MagikIO/familiar-says/internal/textwrap"
+	"golang.org/x/term"
+)
+
+// hyphenate holds the --hyphenate flag, shared by all commands.
+var hyphenate bool
+
+// setupWrap applies --hyphenate to text wrapping.
+func setupWrap() {
+	textwrap.SetHyphenate(hyphenate)
+}
+
+// textAlignment returns the --align alignment, or "" for the bubble
+// template's own alignment if the flag is empty.
+func textAlignment() textwrap.Alignment {
+	if alignName == "" {
+		return ""
+	}
+	align, _ := textwrap.ParseAlignment(alignName)
+	return align
+}
+
+// bubbleOverflow returns the --overflow mode for text that is not paged
+// interactively: pages are shown as split bubbles when they cannot be
+// turned.
+func bubbleOverflow() string {
+	if overflowName == canvas.OverflowPage {
+		return canvas.OverflowSplit
+	}
+	return overflowName
+}
+
+// pageInteractively reports whether long text is paged through with keys:
+// --overflow page with --max-lines, a single character, text output to a
+// terminal and more than one page of text in config.
+func pageInteractively(panelMode bool, config animation.CharacterAnimationConfig) bool {
+	return overflowName == canvas.OverflowPage && maxLines > 0 && !panelMode &&
+		outputFormat == outputText && recordFile == "" && term.IsTerminal(int(os.Stdout.Fd())) &&
+		config.Pages() > 1
+}
@@ -1,13 +1,24 @@
 package animation
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/MagikIO/familiar-says/internal/ascii"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+// cursor returns the typing cursor, transliterated in ASCII mode.
+func cursor() rune {
+	r, _ := utf8.DecodeRuneInString(ascii.Apply("▋"))
+	return r
+}
+
 // TickMsg is sent on each animation frame
 type TickMsg time.Time
 
@@ -115,7 +126,7 @@ func (m Model) View() string {
 			remaining := m.CurrentIndex - charCount
 			partial := line[:remaining]
 			if m.ShowCursor && m.CursorBlink {
-				partial += "▋"
+				partial += string(cursor())
 			}
 			result = append(result, partial)
 			break
@@ -134,13 +145,22 @@ func tick(d time.Duration) tea.Cmd {
 
 // Animate runs the animation and returns the final output
 func Animate(content []string, animType AnimationType, speed time.Duration) error {
+	return AnimateContext(context.Background(), os.Stdout, content, animType, speed)
+}
+
+// AnimateContext runs the animation, writing frames to w until it completes
+// or ctx is cancelled.
+func AnimateContext(ctx context.Context, w io.Writer, content []string, animType AnimationType, speed time.Duration) error {
 	if animType == AnimationNone {
-		fmt.Println(strings.Join(content, "\n"))
-		return nil
+		_, err := fmt.Fprintln(w, strings.Join(content, "\n"))
+		return err
 	}
 
-	p := tea.NewProgram(New(content, animType, speed))
+	p := tea.NewProgram(New(content, animType, speed), tea.WithContext(ctx), tea.WithOutput(w))
 	if _, err := p.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		return fmt.Errorf("animation rendering failed: %w", err)
 	}
 	return nil
@@ -1,11 +1,15 @@
 package animation
 
 import (
+	"context"
+	"io"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/MagikIO/familiar-says/internal/canvas"
 	"github.com/MagikIO/familiar-says/internal/effects"
+	"github.com/MagikIO/familiar-says/internal/textwrap"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -15,20 +19,29 @@ type CharacterTickMsg time.Time
 
 // CharacterAnimationConfig holds configuration for character animation.
 type CharacterAnimationConfig struct {
-	Character    *canvas.Character
-	Animation    *canvas.AnimationSequence
-	BubbleText   string
-	BubbleWidth  int
-	BubbleStyle  canvas.BubbleStyle
-	BubbleColor  lipgloss.Style
-	CharColors   *canvas.CharacterColors
-	CharColor    lipgloss.Style
-	DefaultEyes  string
-	DefaultMouth string
-	TypingSpeed  time.Duration // 0 = no typing animation
-	Duration     time.Duration // 0 = until keypress
-	FrameRate    time.Duration // Character animation frame rate (default 50ms)
-	Effect       effects.Effect // Visual effect to apply
+	Character      *canvas.Character
+	Animation      *canvas.AnimationSequence
+	BubbleText     string
+	BubbleWidth    int
+	BubbleStyle    canvas.BubbleStyle
+	BubbleColor    lipgloss.Style
+	CharColors     *canvas.CharacterColors
+	CharColor      lipgloss.Style
+	DefaultEyes    string
+	DefaultMouth   string
+	TypingSpeed    time.Duration        // 0 = no typing animation
+	Duration       time.Duration        // 0 = until keypress
+	FrameRate      time.Duration        // Character animation frame rate (default 50ms)
+	Effect         effects.Effect       // Visual effect to apply
+	CodeLanguage   string               // Language for code bubbles (detected if empty)
+	CodeStyle      string               // Syntax highlighting style for code bubbles
+	Align          textwrap.Alignment   // Text alignment in the bubble (the style's if empty)
+	Markdown       bool                 // Render inline Markdown in the bubble text
+	MaxLines       int                  // Lines of text in the bubble before it overflows (0 = no limit)
+	Overflow       string               // What happens to text past MaxLines (see canvas.Overflows)
+	Page           int                  // Page of the text shown with canvas.OverflowPage
+	TailDirection  canvas.TailDirection // Direction the bubble tail points (default down)
+	CustomTemplate string               // Custom bubble template name or path (overrides BubbleStyle)
 }
 
 // CharacterModel is a Bubble Tea model for character animation with optional typing.
@@ -37,7 +50,6 @@ type CharacterModel struct {
 	framePlayer  *FramePlayer
 	charStyles   canvas.CharacterStyles
 	bubbleCanvas *canvas.Canvas
-	connCanvas   *canvas.Canvas
 
 	// Typing animation state
 	typingEnabled  bool
@@ -80,26 +92,13 @@ func NewCharacterModel(config CharacterAnimationConfig) CharacterModel {
 	}
 
 	// Pre-render static bubble
-	bubbleCanvas := canvas.RenderBubble(
-		config.BubbleText,
-		config.BubbleWidth,
-		config.BubbleStyle,
-		config.BubbleColor,
-	)
-
-	// Generate connector
-	connectorChar := "\\"
-	if config.BubbleStyle == canvas.BubbleStyleThink {
-		connectorChar = "o"
-	}
-	connCanvas := generateConnectorCanvas(connectorChar, 2, config.Character.GetAnchorX(), config.CharColor)
+	bubbleCanvas := canvas.RenderSceneBubble(config.BubbleText, config.compositorConfig())
 
 	return CharacterModel{
 		config:        config,
 		framePlayer:   framePlayer,
 		charStyles:    charStyles,
 		bubbleCanvas:  bubbleCanvas,
-		connCanvas:    connCanvas,
 		typingEnabled: config.TypingSpeed > 0,
 		typingIndex:   0,
 		typingDone:    config.TypingSpeed == 0,
@@ -107,6 +106,25 @@ func NewCharacterModel(config CharacterAnimationConfig) CharacterModel {
 	}
 }
 
+// compositorConfig returns the compositor configuration of the bubble.
+func (c CharacterAnimationConfig) compositorConfig() canvas.CompositorConfig {
+	return canvas.CompositorConfig{
+		BubbleWidth:    c.BubbleWidth,
+		BubbleStyle:    c.BubbleStyle,
+		BubbleColor:    c.BubbleColor,
+		CodeLanguage:   c.CodeLanguage,
+		CodeStyle:      c.CodeStyle,
+		Align:          c.Align,
+		Markdown:       c.Markdown,
+		MaxLines:       c.MaxLines,
+		Overflow:       c.Overflow,
+		Page:           c.Page,
+		CharColor:      c.CharColor,
+		TailDirection:  c.TailDirection,
+		CustomTemplate: c.CustomTemplate,
+	}
+}
+
 // Init initializes the model.
 func (m CharacterModel) Init() tea.Cmd {
 	m.startTime = time.Now()
@@ -137,18 +155,7 @@ func (m CharacterModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		// Advance typing animation
-		if m.typingEnabled && !m.typingDone {
-			if m.lastTypingTick.IsZero() || now.Sub(m.lastTypingTick) >= m.config.TypingSpeed {
-				m.typingIndex++
-				m.lastTypingTick = now
-
-				// Check if typing is complete
-				totalChars := m.getTotalChars()
-				if m.typingIndex >= totalChars {
-					m.typingDone = true
-				}
-			}
-		}
+		m.advanceTyping(now)
 
 		// Advance character animation
 		if m.framePlayer != nil {
@@ -186,29 +193,7 @@ func (m CharacterModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // View renders the current state.
 func (m CharacterModel) View() string {
-	// Render current character frame
-	var charCanvas *canvas.Canvas
-	if m.framePlayer != nil {
-		charCanvas = m.framePlayer.Tick(0) // Get current frame without advancing
-	} else {
-		charCanvas = m.config.Character.ToCanvasStyled(
-			m.config.DefaultEyes,
-			m.config.DefaultMouth,
-			m.charStyles,
-		)
-	}
-
-	// Compose: bubble + connector + character
-	result := canvas.Stack(m.bubbleCanvas, m.connCanvas, 0)
-	result = canvas.Stack(result, charCanvas, 0)
-
-	// Get rendered lines
-	lines := result.Render()
-
-	// Apply typing effect if enabled and not done
-	if m.typingEnabled && !m.typingDone {
-		lines = m.applyTypingEffect(lines)
-	}
+	lines := m.compose().Render()
 
 	// Apply visual effects
 	if m.config.Effect != "" && m.config.Effect != effects.EffectNone {
@@ -218,59 +203,151 @@ func (m CharacterModel) View() string {
 	return strings.Join(lines, "\n")
 }
 
-// applyTypingEffect applies the typing reveal effect to the output.
-func (m CharacterModel) applyTypingEffect(lines []string) []string {
-	result := make([]string, 0, len(lines))
-	charCount := 0
+// SceneFrame is a composed frame of a character animation and how long it
+// is shown.
+type SceneFrame struct {
+	Canvas   *canvas.Canvas
+	Duration time.Duration
+}
 
-	for _, line := range lines {
-		if charCount >= m.typingIndex {
-			break
-		}
+// Frames composes every frame of the animation with the bubble and
+// connector, for exporting the animation. Typing and effects are not
+// included. Without an animation there is a single frame with no duration.
+func (m CharacterModel) Frames() []SceneFrame {
+	compose := func(char *canvas.Canvas) *canvas.Canvas {
+		return canvas.ComposeBubbleFrame(m.bubbleCanvas, m.config.Character, char, m.config.compositorConfig())
+	}
+
+	if m.framePlayer == nil || m.framePlayer.TotalFrames() == 0 {
+		return []SceneFrame{{Canvas: compose(m.characterCanvas())}}
+	}
+
+	frames := make([]SceneFrame, 0, m.framePlayer.TotalFrames())
+	for i, frame := range m.framePlayer.GetAnimation().Frames {
+		frames = append(frames, SceneFrame{
+			Canvas:   compose(m.framePlayer.renderFrame(i)),
+			Duration: time.Duration(frame.DurationMs) * time.Millisecond,
+		})
+	}
+	return frames
+}
 
-		lineLen := len(line)
-		if charCount+lineLen <= m.typingIndex {
-			result = append(result, line)
-			charCount += lineLen
-		} else {
-			// Partial line
-			remaining := m.typingIndex - charCount
-			if remaining > lineLen {
-				remaining = lineLen
+// compose places the bubble, connector and current character frame for the
+// tail direction, with the typing reveal applied while typing is in
+// progress.
+func (m CharacterModel) compose() *canvas.Canvas {
+	result := canvas.ComposeBubbleFrame(m.bubbleCanvas, m.config.Character, m.characterCanvas(), m.config.compositorConfig())
+	if m.typingEnabled && !m.typingDone {
+		result = revealCells(result, m.typingIndex)
+	}
+	return result
+}
+
+// revealCells returns a copy of c showing only its first n cells in reading
+// order, followed by a cursor. Blank cells after the end of each row's
+// content are not counted.
+func revealCells(c *canvas.Canvas, n int) *canvas.Canvas {
+	result := canvas.NewCanvas(c.Width, c.Height)
+	shown := 0
+rows:
+	for y := 0; y < c.Height; y++ {
+		end := rowContentEnd(c, y)
+		for x := 0; x < end; x++ {
+			cell := c.Cells[y][x]
+			if cell.Grapheme == "" {
+				continue // Wide cluster continuations are revealed with their cluster
+			}
+			if shown == n {
+				result.Set(x, y, cursor(), lipgloss.NewStyle())
+				break rows
 			}
-			partial := line[:remaining]
-			partial += "▋" // Cursor
-			result = append(result, partial)
-			break
+			result.Cells[y][x] = cell
+			if x+1 < c.Width && c.Cells[y][x+1].Grapheme == "" {
+				result.Cells[y][x+1] = c.Cells[y][x+1]
+			}
+			shown++
 		}
 	}
-
 	return result
 }
 
+// rowContentEnd returns the index just past the last visible cell of row y.
+func rowContentEnd(c *canvas.Canvas, y int) int {
+	for x := c.Width; x > 0; x-- {
+		cell := c.Cells[y][x-1]
+		if !cell.Transparent && cell.Grapheme != " " && cell.Grapheme != "" {
+			return x
+		}
+	}
+	return 0
+}
+
 // getTotalChars returns the total character count for typing animation.
 func (m CharacterModel) getTotalChars() int {
-	// Render a frame to get total chars
-	var charCanvas *canvas.Canvas
+	result := canvas.ComposeBubbleFrame(m.bubbleCanvas, m.config.Character, m.characterCanvas(), m.config.compositorConfig())
+
+	total := 0
+	for y := 0; y < result.Height; y++ {
+		for x := 0; x < rowContentEnd(result, y); x++ {
+			if result.Cells[y][x].Grapheme != "" {
+				total++
+			}
+		}
+	}
+	return total
+}
+
+// characterCanvas returns the current character frame without advancing it.
+func (m CharacterModel) characterCanvas() *canvas.Canvas {
 	if m.framePlayer != nil {
-		charCanvas = m.framePlayer.Tick(0)
-	} else {
-		charCanvas = m.config.Character.ToCanvasStyled(
-			m.config.DefaultEyes,
-			m.config.DefaultMouth,
-			m.charStyles,
-		)
+		return m.framePlayer.Tick(0)
 	}
+	return m.config.Character.ToCanvasStyled(
+		m.config.DefaultEyes,
+		m.config.DefaultMouth,
+		m.charStyles,
+	)
+}
 
-	result := canvas.Stack(m.bubbleCanvas, m.connCanvas, 0)
-	result = canvas.Stack(result, charCanvas, 0)
+// playAnimation switches to anim from its first frame, showing eyes and mouth
+// wherever a frame does not override them. A nil anim shows the still character.
+func (m *CharacterModel) playAnimation(anim *canvas.AnimationSequence, eyes, mouth string) {
+	m.config.Animation = anim
+	m.config.DefaultEyes = eyes
+	m.config.DefaultMouth = mouth
+	m.framePlayer = nil
+	if anim != nil {
+		m.framePlayer = NewFramePlayer(m.config.Character, anim, m.charStyles, eyes, mouth)
+	}
+}
 
-	lines := result.Render()
-	total := 0
-	for _, line := range lines {
-		total += len(line)
+// advanceTyping reveals one more character if TypingSpeed has passed since
+// the last one.
+func (m *CharacterModel) advanceTyping(now time.Time) {
+	if !m.typingEnabled || m.typingDone {
+		return
+	}
+	if m.lastTypingTick.IsZero() || now.Sub(m.lastTypingTick) >= m.config.TypingSpeed {
+		m.typingIndex++
+		m.lastTypingTick = now
+
+		// Check if typing is complete
+		if m.typingIndex >= m.getTotalChars() {
+			m.typingDone = true
+		}
+	}
+}
+
+// advanceFrame advances the character animation by one frame. Finished
+// non-looping animations return to the still character.
+func (m *CharacterModel) advanceFrame() {
+	if m.framePlayer == nil {
+		return
+	}
+	m.framePlayer.Tick(m.config.FrameRate)
+	if m.framePlayer.IsComplete() {
+		m.playAnimation(nil, m.config.DefaultEyes, m.config.DefaultMouth)
 	}
-	return total
 }
 
 // tick returns a command that sends a CharacterTickMsg.
@@ -280,23 +357,19 @@ func (m CharacterModel) tick() tea.Cmd {
 	})
 }
 
-// generateConnectorCanvas creates connector lines between bubble and character.
-func generateConnectorCanvas(char string, length int, anchorX int, style lipgloss.Style) *canvas.Canvas {
-	lines := make([]string, length)
-	for i := 0; i < length; i++ {
-		indent := anchorX + i
-		if indent < 0 {
-			indent = 0
-		}
-		lines[i] = strings.Repeat(" ", indent) + char
-	}
-	return canvas.FromLines(lines, style)
-}
-
 // AnimateCharacter runs the character animation and returns when complete.
 func AnimateCharacter(config CharacterAnimationConfig) error {
-	p := tea.NewProgram(NewCharacterModel(config))
+	return AnimateCharacterContext(context.Background(), os.Stdout, config)
+}
+
+// AnimateCharacterContext runs the character animation, writing frames to w
+// until it completes or ctx is cancelled.
+func AnimateCharacterContext(ctx context.Context, w io.Writer, config CharacterAnimationConfig) error {
+	p := tea.NewProgram(NewCharacterModel(config), tea.WithContext(ctx), tea.WithOutput(w))
 	if _, err := p.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		return err
 	}
 	return nil
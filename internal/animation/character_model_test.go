@@ -0,0 +1,74 @@
+package animation
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MagikIO/familiar-says/internal/canvas"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// TestCharacterModelFrames tests composing every animation frame for export
+func TestCharacterModelFrames(t *testing.T) {
+	char := &canvas.Character{
+		Name: "test",
+		Art:  []string{"( @@ )"},
+		Eyes: &canvas.Slot{Line: 0, Col: 2, Width: 2, Placeholder: "@@"},
+	}
+	anim := &canvas.AnimationSequence{
+		Frames: []canvas.AnimationFrame{
+			{DurationMs: 100, Eyes: "oo"},
+			{DurationMs: 250, Eyes: "--"},
+		},
+	}
+
+	frames := NewCharacterModel(CharacterAnimationConfig{
+		Character:  char,
+		Animation:  anim,
+		BubbleText: "Hi",
+	}).Frames()
+	if len(frames) != 2 {
+		t.Fatalf("Expected 2 frames, got %d", len(frames))
+	}
+
+	wantEyes := []string{"( oo )", "( -- )"}
+	wantDurations := []time.Duration{100 * time.Millisecond, 250 * time.Millisecond}
+	for i, frame := range frames {
+		text := strings.Join(frame.Canvas.RenderPlain(), "\n")
+		if !strings.Contains(text, "< Hi >") || !strings.Contains(text, wantEyes[i]) {
+			t.Errorf("Frame %d doesn't show the bubble and %q:\n%s", i, wantEyes[i], text)
+		}
+		if frame.Duration != wantDurations[i] {
+			t.Errorf("Frame %d duration = %v, want %v", i, frame.Duration, wantDurations[i])
+		}
+	}
+
+	still := NewCharacterModel(CharacterAnimationConfig{Character: char, BubbleText: "Hi"}).Frames()
+	if len(still) != 1 || still[0].Duration != 0 {
+		t.Errorf("Expected a single still frame, got %d frames", len(still))
+	}
+}
+
+// TestCharacterModelLayout tests that animation frames are laid out like
+// still scenes, with the tail direction and custom template
+func TestCharacterModelLayout(t *testing.T) {
+	char := &canvas.Character{Name: "test", Art: []string{"( oo )"}}
+	anim := &canvas.AnimationSequence{Frames: []canvas.AnimationFrame{{DurationMs: 100}}}
+
+	for _, tail := range []canvas.TailDirection{canvas.TailDown, canvas.TailUp, canvas.TailLeft, canvas.TailRight} {
+		config := CharacterAnimationConfig{
+			Character:      char,
+			Animation:      anim,
+			BubbleText:     "Hi",
+			BubbleWidth:    40,
+			TailDirection:  tail,
+			CustomTemplate: "shout",
+		}
+		got := strings.Join(NewCharacterModel(config).Frames()[0].Canvas.RenderPlain(), "\n")
+		still := canvas.ComposeFrame("Hi", char, char.ToCanvas("", "", lipgloss.NewStyle()), config.compositorConfig())
+		if want := strings.Join(still.RenderPlain(), "\n"); got != want {
+			t.Errorf("Tail %v frame:\n%s\nwant:\n%s", tail, got, want)
+		}
+	}
+}
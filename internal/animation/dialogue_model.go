@@ -0,0 +1,370 @@
+package animation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MagikIO/familiar-says/internal/ansi"
+	"github.com/MagikIO/familiar-says/internal/ascii"
+	"github.com/MagikIO/familiar-says/internal/canvas"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// DialogueTickMsg is sent on each dialogue animation frame.
+type DialogueTickMsg time.Time
+
+// DialogueCastMember is a character taking part in a dialogue.
+type DialogueCastMember struct {
+	Character    *canvas.Character
+	Idle         *canvas.AnimationSequence // Optional animation while not acting
+	CharColors   *canvas.CharacterColors
+	DefaultEyes  string
+	DefaultMouth string
+}
+
+// DialogueLine is a single line spoken by a cast member.
+type DialogueLine struct {
+	Speaker int // Index into DialogueConfig.Cast
+	Text    string
+	Eyes    string
+	Mouth   string
+	Action  *canvas.AnimationSequence // Optional animation played while speaking
+}
+
+// DialogueConfig holds configuration for dialogue playback.
+type DialogueConfig struct {
+	Title       string
+	Cast        []DialogueCastMember
+	Lines       []DialogueLine
+	BubbleWidth int
+	BubbleStyle canvas.BubbleStyle
+	BubbleColor lipgloss.Style
+	CharColor   lipgloss.Style
+	TypingSpeed time.Duration // 0 = show each line at once
+	LinePause   time.Duration // Pause before the next line; 0 = wait for a key
+	KeepBubbles bool          // Characters keep their last bubble while others speak
+	FrameRate   time.Duration // Character animation frame rate (default 50ms)
+}
+
+// DialogueModel is a Bubble Tea model that plays a dialogue line by line.
+// Each cast member is driven by its own CharacterModel.
+type DialogueModel struct {
+	config DialogueConfig
+	actors []CharacterModel
+
+	line       int
+	typed      int // Runes of the current line revealed so far
+	lastTyping time.Time
+	lineDoneAt time.Time
+	paused     bool
+	done       bool
+}
+
+// NewDialogueModel creates a new dialogue model positioned at the first line.
+func NewDialogueModel(config DialogueConfig) DialogueModel {
+	if config.FrameRate == 0 {
+		config.FrameRate = 50 * time.Millisecond
+	}
+	if config.BubbleWidth <= 0 {
+		config.BubbleWidth = 40
+	}
+
+	actors := make([]CharacterModel, len(config.Cast))
+	for i, member := range config.Cast {
+		actors[i] = NewCharacterModel(CharacterAnimationConfig{
+			Character:    member.Character,
+			Animation:    member.Idle,
+			BubbleWidth:  config.BubbleWidth,
+			CharColors:   member.CharColors,
+			CharColor:    config.CharColor,
+			DefaultEyes:  member.DefaultEyes,
+			DefaultMouth: member.DefaultMouth,
+			FrameRate:    config.FrameRate,
+		})
+	}
+
+	m := DialogueModel{config: config, actors: actors}
+	m.goTo(0)
+	return m
+}
+
+// Init initializes the model.
+func (m DialogueModel) Init() tea.Cmd {
+	return m.tick()
+}
+
+// Update handles messages.
+func (m DialogueModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case DialogueTickMsg:
+		if m.done {
+			return m, tea.Quit
+		}
+		if m.paused {
+			return m, m.tick()
+		}
+
+		now := time.Time(msg)
+		m.advanceTyping(now)
+		for i := range m.actors {
+			m.actors[i].advanceFrame()
+			// Return to idle once an action has finished
+			if m.actors[i].config.Animation == nil && m.config.Cast[i].Idle != nil {
+				m.actors[i].playAnimation(m.config.Cast[i].Idle, m.actors[i].config.DefaultEyes, m.actors[i].config.DefaultMouth)
+			}
+		}
+
+		// Auto-advance after the line has been shown for LinePause
+		if m.typingDone() && m.config.LinePause > 0 {
+			if m.lineDoneAt.IsZero() {
+				m.lineDoneAt = now
+			} else if now.Sub(m.lineDoneAt) >= m.config.LinePause {
+				if !m.next() {
+					return m, tea.Quit
+				}
+			}
+		}
+
+		return m, m.tick()
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			m.done = true
+			return m, tea.Quit
+		case " ", "p":
+			m.paused = !m.paused
+			m.lastTyping = time.Time{}
+			m.lineDoneAt = time.Time{}
+		case "n", "right", "enter", "l":
+			if !m.typingDone() {
+				m.typed = m.lineLen()
+			} else if !m.next() {
+				return m, tea.Quit
+			}
+		case "b", "left", "h":
+			m.goTo(max(m.line-1, 0))
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the current state.
+func (m DialogueModel) View() string {
+	lines := m.render(m.typingDone())
+	if !m.done {
+		lines = append(lines, "", m.statusLine())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// render composes the stage: every cast member side by side, the speaker
+// with the (partially typed) current line.
+func (m DialogueModel) render(fullLine bool) []string {
+	if len(m.config.Lines) == 0 {
+		return nil
+	}
+
+	current := m.config.Lines[m.line]
+	panels := make([]canvas.PanelConfig, len(m.actors))
+	for i, actor := range m.actors {
+		panel := canvas.PanelConfig{
+			Character:   actor.config.Character,
+			BubbleStyle: m.config.BubbleStyle,
+			CharColors:  m.config.Cast[i].CharColors,
+			Frame:       actor.characterCanvas(),
+			NoBubble:    true,
+		}
+
+		if i == current.Speaker {
+			panel.Text = current.Text
+			if !fullLine {
+				panel.Text = typedText(current.Text, m.typed) + string(cursor())
+			}
+			panel.NoBubble = false
+		} else if last := m.lastSpoken(i); m.config.KeepBubbles && last >= 0 {
+			panel.Text = m.config.Lines[last].Text
+			panel.NoBubble = false
+		}
+		panels[i] = panel
+	}
+
+	config := canvas.DefaultConfig()
+	config.BubbleWidth = m.config.BubbleWidth
+	config.BubbleColor = m.config.BubbleColor
+	config.CharColor = m.config.CharColor
+
+	var lines []string
+	if m.config.Title != "" {
+		lines = append(lines, lipgloss.NewStyle().Bold(true).Render(m.config.Title), "")
+	}
+	return append(lines, canvas.ComposeMultiPanel(panels, config).Render()...)
+}
+
+// statusLine describes the playback position and the available keys.
+func (m DialogueModel) statusLine() string {
+	state := ""
+	if m.paused {
+		state = " (paused)"
+	}
+	status := fmt.Sprintf("line %d/%d%s · space pause · n next · b back · q quit",
+		m.line+1, len(m.config.Lines), state)
+	status = ascii.Apply(status)
+	return lipgloss.NewStyle().Faint(true).Render(status)
+}
+
+// goTo jumps to line i, restarting typing. The speaker plays the line's
+// action and expression; everyone else idles with their last expression.
+func (m *DialogueModel) goTo(i int) {
+	if i < 0 || i >= len(m.config.Lines) {
+		return
+	}
+	m.line = i
+	m.typed = 0
+	m.lastTyping = time.Time{}
+	m.lineDoneAt = time.Time{}
+	if m.config.TypingSpeed == 0 {
+		m.typed = m.lineLen()
+	}
+
+	current := m.config.Lines[i]
+	for c := range m.actors {
+		member := m.config.Cast[c]
+		if c == current.Speaker {
+			anim := current.Action
+			if anim == nil {
+				anim = member.Idle
+			}
+			m.actors[c].playAnimation(anim, current.Eyes, current.Mouth)
+			continue
+		}
+
+		eyes, mouth := member.DefaultEyes, member.DefaultMouth
+		if last := m.lastSpoken(c); last >= 0 {
+			eyes, mouth = m.config.Lines[last].Eyes, m.config.Lines[last].Mouth
+		}
+		// Leave a running idle alone so it doesn't restart on every line
+		actor := &m.actors[c]
+		if actor.config.Animation != member.Idle || actor.config.DefaultEyes != eyes || actor.config.DefaultMouth != mouth {
+			actor.playAnimation(member.Idle, eyes, mouth)
+		}
+	}
+}
+
+// next moves to the following line. It reports false at the end of the
+// dialogue, marking the model done.
+func (m *DialogueModel) next() bool {
+	if m.line+1 >= len(m.config.Lines) {
+		m.done = true
+		return false
+	}
+	m.goTo(m.line + 1)
+	return true
+}
+
+// advanceTyping reveals more of the current line according to TypingSpeed.
+func (m *DialogueModel) advanceTyping(now time.Time) {
+	if m.typingDone() {
+		return
+	}
+	if m.lastTyping.IsZero() {
+		m.lastTyping = now
+		return
+	}
+	if elapsed := now.Sub(m.lastTyping); elapsed >= m.config.TypingSpeed {
+		m.typed += int(elapsed / m.config.TypingSpeed)
+		if m.typed > m.lineLen() {
+			m.typed = m.lineLen()
+		}
+		m.lastTyping = now
+	}
+}
+
+// typingDone reports whether the current line is fully shown.
+func (m DialogueModel) typingDone() bool {
+	return m.typed >= m.lineLen()
+}
+
+// lineLen returns the number of grapheme clusters in the current line.
+func (m DialogueModel) lineLen() int {
+	if len(m.config.Lines) == 0 {
+		return 0
+	}
+	n := 0
+	for range ansi.Graphemes(m.config.Lines[m.line].Text) {
+		n++
+	}
+	return n
+}
+
+// typedText returns the first n grapheme clusters of text, so accents and
+// emoji sequences are typed as one.
+func typedText(text string, n int) string {
+	end := 0
+	for g := range ansi.Graphemes(text) {
+		if n == 0 {
+			break
+		}
+		end += len(g)
+		n--
+	}
+	return text[:end]
+}
+
+// lastSpoken returns the index of the most recent line, up to the current
+// one, spoken by cast member c, or -1 if they have not spoken yet.
+func (m DialogueModel) lastSpoken(c int) int {
+	for i := m.line; i >= 0; i-- {
+		if m.config.Lines[i].Speaker == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// tick returns a command that sends a DialogueTickMsg.
+func (m DialogueModel) tick() tea.Cmd {
+	return tea.Tick(m.config.FrameRate, func(t time.Time) tea.Msg {
+		return DialogueTickMsg(t)
+	})
+}
+
+// DialogueScenes renders the stage after each line without animation, for
+// output that is not a terminal.
+func DialogueScenes(config DialogueConfig) [][]string {
+	m := NewDialogueModel(config)
+	scenes := make([][]string, 0, len(config.Lines))
+	for i := range config.Lines {
+		m.goTo(i)
+		scenes = append(scenes, m.render(true))
+	}
+	return scenes
+}
+
+// PlayDialogue plays a dialogue on stdout until it ends or the user quits.
+func PlayDialogue(config DialogueConfig) error {
+	return PlayDialogueContext(context.Background(), os.Stdout, config)
+}
+
+// PlayDialogueContext plays a dialogue, writing frames to w until it ends,
+// the user quits or ctx is cancelled.
+func PlayDialogueContext(ctx context.Context, w io.Writer, config DialogueConfig) error {
+	if len(config.Lines) == 0 {
+		return fmt.Errorf("dialogue has no lines")
+	}
+
+	p := tea.NewProgram(NewDialogueModel(config), tea.WithContext(ctx), tea.WithOutput(w))
+	if _, err := p.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	return nil
+}
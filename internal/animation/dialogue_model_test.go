@@ -0,0 +1,187 @@
+package animation
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MagikIO/familiar-says/internal/canvas"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// testDialogue returns a two character dialogue config.
+func testDialogue(t *testing.T) DialogueConfig {
+	t.Helper()
+	cat, ok := canvas.GetBuiltinCharacter("cat")
+	if !ok {
+		t.Fatal("Failed to load cat")
+	}
+	owl, ok := canvas.GetBuiltinCharacter("owl")
+	if !ok {
+		t.Fatal("Failed to load owl")
+	}
+
+	return DialogueConfig{
+		Cast: []DialogueCastMember{
+			{Character: cat, DefaultEyes: "oo", DefaultMouth: "  "},
+			{Character: owl, DefaultEyes: "oo", DefaultMouth: "  "},
+		},
+		Lines: []DialogueLine{
+			{Speaker: 0, Text: "Hello owl", Eyes: "^^", Mouth: "  "},
+			{Speaker: 1, Text: "Hoo there", Eyes: "OO", Mouth: "  "},
+			{Speaker: 0, Text: "Bye now", Eyes: "--", Mouth: "  "},
+		},
+		BubbleWidth: 20,
+		TypingSpeed: 10 * time.Millisecond,
+	}
+}
+
+// key returns a key message for the given key string.
+func key(s string) tea.KeyMsg {
+	switch s {
+	case "left":
+		return tea.KeyMsg{Type: tea.KeyLeft}
+	case " ":
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+// update sends msg to m and returns the updated dialogue model.
+func update(m DialogueModel, msg tea.Msg) (DialogueModel, tea.Cmd) {
+	next, cmd := m.Update(msg)
+	return next.(DialogueModel), cmd
+}
+
+// TestDialogueScenes tests that each scene shows the current speaker
+func TestDialogueScenes(t *testing.T) {
+	tests := []struct {
+		name        string
+		keepBubbles bool
+		wantScene1  []string
+		avoidScene1 []string
+	}{
+		{"idle listeners", false, []string{"Hoo there"}, []string{"Hello owl"}},
+		{"keep bubbles", true, []string{"Hoo there", "Hello owl"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testDialogue(t)
+			config.KeepBubbles = tt.keepBubbles
+			scenes := DialogueScenes(config)
+			if len(scenes) != 3 {
+				t.Fatalf("Got %d scenes, want 3", len(scenes))
+			}
+
+			scene := strings.Join(scenes[1], "\n")
+			for _, want := range tt.wantScene1 {
+				if !strings.Contains(scene, want) {
+					t.Errorf("Scene 2 missing %q:\n%s", want, scene)
+				}
+			}
+			for _, avoid := range tt.avoidScene1 {
+				if strings.Contains(scene, avoid) {
+					t.Errorf("Scene 2 should not contain %q:\n%s", avoid, scene)
+				}
+			}
+			if !strings.Contains(scene, "OO") {
+				t.Errorf("Scene 2 should show the owl's expression:\n%s", scene)
+			}
+		})
+	}
+}
+
+// TestDialogueKeys tests next, previous, pause and quit
+func TestDialogueKeys(t *testing.T) {
+	m := NewDialogueModel(testDialogue(t))
+
+	// The first next completes typing, the second advances
+	m, _ = update(m, key("n"))
+	if m.line != 0 || !m.typingDone() {
+		t.Fatalf("Expected line 0 fully typed, got line %d typed %d", m.line, m.typed)
+	}
+	m, _ = update(m, key("n"))
+	if m.line != 1 || m.typingDone() {
+		t.Fatalf("Expected line 1 typing, got line %d typed %d", m.line, m.typed)
+	}
+
+	m, _ = update(m, key("left"))
+	if m.line != 0 {
+		t.Errorf("Expected previous to return to line 0, got %d", m.line)
+	}
+
+	m, _ = update(m, key(" "))
+	if !m.paused || !strings.Contains(m.View(), "paused") {
+		t.Error("Expected space to pause playback")
+	}
+	typed := m.typed
+	m, _ = update(m, DialogueTickMsg(time.Now()))
+	m, _ = update(m, DialogueTickMsg(time.Now().Add(time.Second)))
+	if m.typed != typed {
+		t.Error("Typing should not advance while paused")
+	}
+
+	m, cmd := update(m, key("q"))
+	if !m.done || cmd == nil {
+		t.Error("Expected q to quit")
+	}
+}
+
+// TestDialogueAutoAdvance tests typing and advancing on ticks
+func TestDialogueAutoAdvance(t *testing.T) {
+	config := testDialogue(t)
+	config.LinePause = 100 * time.Millisecond
+	m := NewDialogueModel(config)
+
+	start := time.Now()
+	m, _ = update(m, DialogueTickMsg(start))
+	m, _ = update(m, DialogueTickMsg(start.Add(30*time.Millisecond)))
+	if m.typed != 3 {
+		t.Errorf("Expected 3 runes typed after 30ms, got %d", m.typed)
+	}
+	if !strings.Contains(m.View(), "Hel▋") {
+		t.Errorf("Expected partially typed line:\n%s", m.View())
+	}
+
+	m, _ = update(m, DialogueTickMsg(start.Add(time.Second)))
+	if !m.typingDone() {
+		t.Fatal("Expected line to be fully typed")
+	}
+	m, _ = update(m, DialogueTickMsg(start.Add(1050*time.Millisecond)))
+	if m.line != 0 {
+		t.Error("Should wait LinePause before advancing")
+	}
+	m, _ = update(m, DialogueTickMsg(start.Add(1200*time.Millisecond)))
+	if m.line != 1 {
+		t.Errorf("Expected to advance to line 1, got %d", m.line)
+	}
+
+	// Finishing the last line ends playback
+	m.goTo(2)
+	m, _ = update(m, key("n"))
+	m, cmd := update(m, key("n"))
+	if !m.done || cmd == nil {
+		t.Error("Expected playback to end after the last line")
+	}
+}
+
+// TestTypedText tests that typing reveals whole grapheme clusters
+func TestTypedText(t *testing.T) {
+	tests := []struct {
+		text string
+		n    int
+		want string
+	}{
+		{"hello", 2, "he"},
+		{"cafe\u0301!", 4, "cafe\u0301"},
+		{"\U0001f44d\U0001f3fd ok", 1, "\U0001f44d\U0001f3fd"},
+		{"hi", 5, "hi"},
+	}
+
+	for _, tt := range tests {
+		if got := typedText(tt.text, tt.n); got != tt.want {
+			t.Errorf("typedText(%q, %d) = %q, want %q", tt.text, tt.n, got, tt.want)
+		}
+	}
+}
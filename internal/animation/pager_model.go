@@ -0,0 +1,129 @@
+package animation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/MagikIO/familiar-says/internal/ascii"
+	"github.com/MagikIO/familiar-says/internal/canvas"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// PagerModel is a Bubble Tea model that pages through bubble text longer
+// than MaxLines, one bubble at a time. The character is driven by a
+// CharacterModel, which keeps animating while pages are turned.
+type PagerModel struct {
+	config CharacterAnimationConfig
+	actor  CharacterModel
+	pages  int
+	done   bool
+}
+
+// NewPagerModel creates a new pager model showing config.Page.
+func NewPagerModel(config CharacterAnimationConfig) PagerModel {
+	config.Overflow = canvas.OverflowPage
+	m := PagerModel{config: config, actor: NewCharacterModel(config)}
+	m.config = m.actor.config // With defaults applied
+	m.pages = m.config.Pages()
+	m.goTo(config.Page)
+	return m
+}
+
+// Pages returns how many pages of at most MaxLines lines the bubble text
+// takes up. Text that fits on one page needs no pager.
+func (c CharacterAnimationConfig) Pages() int {
+	return canvas.PageCount(c.BubbleText, c.compositorConfig())
+}
+
+// Init initializes the model.
+func (m PagerModel) Init() tea.Cmd {
+	return m.actor.tick()
+}
+
+// Update handles messages.
+func (m PagerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case CharacterTickMsg:
+		if m.done {
+			return m, tea.Quit
+		}
+		m.actor.advanceTyping(time.Time(msg))
+		m.actor.advanceFrame()
+		return m, m.actor.tick()
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			m.done = true
+			return m, tea.Quit
+		case " ", "n", "right", "l", "j", "down", "pgdown", "enter":
+			if !m.actor.typingDone {
+				m.actor.typingDone = true
+			} else if m.config.Page+1 >= m.pages {
+				m.done = true
+				return m, tea.Quit
+			} else {
+				m.goTo(m.config.Page + 1)
+			}
+		case "b", "left", "h", "k", "up", "pgup":
+			m.goTo(m.config.Page - 1)
+		case "home", "g":
+			m.goTo(0)
+		case "end", "G":
+			m.goTo(m.pages - 1)
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the current state.
+func (m PagerModel) View() string {
+	view := m.actor.View()
+	if m.done {
+		return view
+	}
+	return view + "\n\n" + m.statusLine()
+}
+
+// statusLine describes the current page and the available keys.
+func (m PagerModel) statusLine() string {
+	status := fmt.Sprintf("page %d/%d · n next · b back · q quit", m.config.Page+1, m.pages)
+	status = ascii.Apply(status)
+	return lipgloss.NewStyle().Faint(true).Render(status)
+}
+
+// goTo shows page i of the text, clamped to the pages there are, and types
+// it in again if typing is enabled.
+func (m *PagerModel) goTo(i int) {
+	m.config.Page = min(max(i, 0), m.pages-1)
+	m.actor.config.Page = m.config.Page
+	m.actor.bubbleCanvas = canvas.RenderSceneBubble(m.config.BubbleText, m.config.compositorConfig())
+	m.actor.typingIndex = 0
+	m.actor.typingDone = !m.actor.typingEnabled
+	m.actor.lastTypingTick = time.Time{}
+}
+
+// PlayPager pages through the bubble text on stdout until the user quits or
+// turns past the last page.
+func PlayPager(config CharacterAnimationConfig) error {
+	return PlayPagerContext(context.Background(), os.Stdout, config)
+}
+
+// PlayPagerContext pages through the bubble text, writing frames to w until
+// the user quits, turns past the last page or ctx is cancelled. Keys are read
+// from the terminal even when the text was piped to stdin.
+func PlayPagerContext(ctx context.Context, w io.Writer, config CharacterAnimationConfig) error {
+	p := tea.NewProgram(NewPagerModel(config), tea.WithContext(ctx), tea.WithOutput(w))
+	if _, err := p.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	return nil
+}
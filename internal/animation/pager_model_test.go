@@ -0,0 +1,126 @@
+package animation
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MagikIO/familiar-says/internal/canvas"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// updatePager sends msg to m and returns the updated pager model.
+func updatePager(m PagerModel, msg tea.Msg) (PagerModel, tea.Cmd) {
+	next, cmd := m.Update(msg)
+	return next.(PagerModel), cmd
+}
+
+// TestPagerModel tests turning the pages of long bubble text
+func TestPagerModel(t *testing.T) {
+	cat, ok := canvas.GetBuiltinCharacter("cat")
+	if !ok {
+		t.Fatal("Failed to load cat")
+	}
+
+	m := NewPagerModel(CharacterAnimationConfig{
+		Character:    cat,
+		BubbleText:   "one\ntwo\nthree\nfour\nfive",
+		BubbleWidth:  20,
+		DefaultEyes:  "oo",
+		DefaultMouth: "  ",
+		MaxLines:     2,
+	})
+	if m.pages != 3 {
+		t.Fatalf("Expected 3 pages, got %d", m.pages)
+	}
+
+	steps := []struct {
+		key   string
+		shown string
+		page  int
+	}{
+		{"", "two", 1},
+		{"n", "four", 2},
+		{"n", "five", 3},
+		{"b", "three", 2},
+		{"left", "one", 1},
+		{"b", "one", 1},
+		{"G", "five", 3},
+		{"g", "one", 1},
+	}
+	for _, step := range steps {
+		if step.key != "" {
+			m, _ = updatePager(m, key(step.key))
+		}
+		view := m.View()
+		if !strings.Contains(view, step.shown) {
+			t.Errorf("After %q the view doesn't show %q:\n%s", step.key, step.shown, view)
+		}
+		if want := fmt.Sprintf("page %d/3", step.page); !strings.Contains(view, want) {
+			t.Errorf("After %q the status doesn't show %q:\n%s", step.key, want, view)
+		}
+	}
+
+	// Turning past the last page quits
+	m, _ = updatePager(m, key("G"))
+	m, cmd := updatePager(m, key("n"))
+	if !m.done || cmd == nil {
+		t.Error("Expected turning past the last page to quit")
+	}
+	if strings.Contains(m.View(), "page ") {
+		t.Error("Expected no status line after quitting")
+	}
+}
+
+// TestCharacterAnimationConfigPages tests counting the pages of bubble text
+func TestCharacterAnimationConfigPages(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		maxLines int
+		want     int
+	}{
+		{"no limit", "one\ntwo\nthree", 0, 1},
+		{"fits", "one", 2, 1},
+		{"exactly fits", "one\ntwo", 2, 1},
+		{"overflows", "one\ntwo\nthree", 2, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := CharacterAnimationConfig{BubbleText: tt.text, BubbleWidth: 20, MaxLines: tt.maxLines}
+			if got := config.Pages(); got != tt.want {
+				t.Errorf("Pages() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestPagerModelTyping tests that a key finishes typing before turning the
+// page
+func TestPagerModelTyping(t *testing.T) {
+	cat, ok := canvas.GetBuiltinCharacter("cat")
+	if !ok {
+		t.Fatal("Failed to load cat")
+	}
+
+	m := NewPagerModel(CharacterAnimationConfig{
+		Character:   cat,
+		BubbleText:  "one\ntwo",
+		TypingSpeed: 10 * time.Millisecond,
+		MaxLines:    1,
+	})
+	if m.actor.typingDone {
+		t.Fatal("Expected the first page to be typed in")
+	}
+
+	m, _ = updatePager(m, key("n"))
+	if !m.actor.typingDone || m.config.Page != 0 {
+		t.Fatalf("Expected the first key to finish typing, got page %d", m.config.Page+1)
+	}
+	m, _ = updatePager(m, key("n"))
+	if m.actor.typingDone || m.config.Page != 1 {
+		t.Errorf("Expected the second page to be typed in, got page %d", m.config.Page+1)
+	}
+}
@@ -0,0 +1,99 @@
+package animation
+
+import (
+	"time"
+)
+
+// maxRecording caps the length of a recording, in case an animation never
+// reaches a stopping point.
+const maxRecording = 5 * time.Minute
+
+// ViewFrame is a frame as a Bubble Tea program would draw it to the
+// terminal, and how long it is shown.
+type ViewFrame struct {
+	View     string
+	Duration time.Duration
+}
+
+// Record plays a character animation headlessly on a virtual clock and
+// returns each composed frame, typing animation included. Every frame lasts
+// one FrameRate tick. Recording stops after config.Duration if it is set;
+// otherwise it stops once typing has finished and a non-looping animation
+// has completed, or a looping one has reached the end of a loop.
+func Record(config CharacterAnimationConfig) []SceneFrame {
+	var frames []SceneFrame
+	play(config, func(m CharacterModel, d time.Duration) {
+		frames = append(frames, SceneFrame{Canvas: m.compose(), Duration: d})
+	})
+	return frames
+}
+
+// RecordViews plays a character animation like Record, but returns the
+// rendered views, effects included.
+func RecordViews(config CharacterAnimationConfig) []ViewFrame {
+	var frames []ViewFrame
+	play(config, func(m CharacterModel, d time.Duration) {
+		frames = append(frames, ViewFrame{View: m.View(), Duration: d})
+	})
+	return frames
+}
+
+// RecordTyping plays a typing animation of content headlessly on a virtual
+// clock and returns each view. The last view shows the whole content and has
+// no duration.
+func RecordTyping(content []string, animType AnimationType, speed time.Duration) []ViewFrame {
+	m := New(content, animType, speed)
+	var frames []ViewFrame
+	for elapsed := time.Duration(0); !m.Done && elapsed < maxRecording; elapsed += m.Speed {
+		frames = append(frames, ViewFrame{View: m.View(), Duration: m.Speed})
+		updated, _ := m.Update(TickMsg(time.Unix(0, 0).Add(elapsed + m.Speed)))
+		m = updated.(Model)
+	}
+	return append(frames, ViewFrame{View: m.View()})
+}
+
+// play steps a character animation on a virtual clock, calling frame with
+// the model and duration of each frame shown.
+func play(config CharacterAnimationConfig, frame func(m CharacterModel, d time.Duration)) {
+	m := NewCharacterModel(config)
+	rate := m.config.FrameRate
+
+	var loop time.Duration
+	if m.framePlayer != nil {
+		for _, frame := range m.framePlayer.GetAnimation().Frames {
+			loop += time.Duration(frame.DurationMs) * time.Millisecond
+		}
+	}
+
+	start := time.Unix(0, 0)
+	var stopAt time.Duration
+	for elapsed := time.Duration(0); elapsed < maxRecording; elapsed += rate {
+		frame(m, rate)
+
+		next := elapsed + rate
+		if config.Duration > 0 {
+			if next >= config.Duration {
+				return
+			}
+		} else if m.typingDone {
+			if m.framePlayer == nil || loop == 0 {
+				return
+			}
+			if stopAt == 0 {
+				// Finish the loop that is playing when typing ends
+				stopAt = (next + loop - 1) / loop * loop
+			}
+			if next >= stopAt {
+				return
+			}
+		}
+
+		updated, _ := m.Update(CharacterTickMsg(start.Add(next)))
+		m = updated.(CharacterModel)
+		if m.done {
+			// Non-looping animations hold their last frame
+			frame(m, rate)
+			return
+		}
+	}
+}
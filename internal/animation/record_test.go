@@ -0,0 +1,132 @@
+package animation
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MagikIO/familiar-says/internal/canvas"
+)
+
+// testRecordConfig returns a character blinking in a 200ms loop.
+func testRecordConfig() CharacterAnimationConfig {
+	return CharacterAnimationConfig{
+		Character: &canvas.Character{
+			Name: "test",
+			Art:  []string{"( @@ )"},
+			Eyes: &canvas.Slot{Line: 0, Col: 2, Width: 2, Placeholder: "@@"},
+		},
+		Animation: &canvas.AnimationSequence{
+			Frames: []canvas.AnimationFrame{
+				{DurationMs: 100, Eyes: "oo"},
+				{DurationMs: 100, Eyes: "--"},
+			},
+			Loop: true,
+		},
+		BubbleText: "Hi",
+		FrameRate:  50 * time.Millisecond,
+	}
+}
+
+// plain returns the plain text of a recorded frame.
+func plain(frame SceneFrame) string {
+	return strings.Join(frame.Canvas.RenderPlain(), "\n")
+}
+
+// TestRecord tests stepping an animation headlessly
+func TestRecord(t *testing.T) {
+	tests := []struct {
+		name       string
+		duration   time.Duration
+		wantFrames int
+	}{
+		{"one loop", 0, 4},
+		{"fixed duration", 300 * time.Millisecond, 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testRecordConfig()
+			config.Duration = tt.duration
+			frames := Record(config)
+			if len(frames) != tt.wantFrames {
+				t.Fatalf("Got %d frames, want %d", len(frames), tt.wantFrames)
+			}
+			for i, frame := range frames {
+				if frame.Duration != 50*time.Millisecond {
+					t.Errorf("Frame %d lasts %v, want 50ms", i, frame.Duration)
+				}
+			}
+			if !strings.Contains(plain(frames[0]), "( oo )") || !strings.Contains(plain(frames[2]), "( -- )") {
+				t.Errorf("Frames don't follow the animation:\n%s\n\n%s", plain(frames[0]), plain(frames[2]))
+			}
+		})
+	}
+}
+
+// TestRecordTyping tests that typing is recorded before the animation ends
+func TestRecordTyping(t *testing.T) {
+	config := testRecordConfig()
+	config.TypingSpeed = 50 * time.Millisecond
+	frames := Record(config)
+
+	first, last := plain(frames[0]), plain(frames[len(frames)-1])
+	if !strings.Contains(first, "▋") || strings.Contains(first, "Hi") {
+		t.Errorf("First frame should show the typing cursor only:\n%s", first)
+	}
+	if !strings.Contains(last, "< Hi >") || strings.Contains(last, "▋") {
+		t.Errorf("Last frame should show the whole bubble:\n%s", last)
+	}
+	// Recording ends on a loop boundary after typing finishes
+	if total := time.Duration(len(frames)) * config.FrameRate; total%(200*time.Millisecond) != 0 {
+		t.Errorf("Recording lasts %v, want a whole number of loops", total)
+	}
+}
+
+// TestRecordNonLooping tests that non-looping animations end on their last
+// frame
+func TestRecordNonLooping(t *testing.T) {
+	config := testRecordConfig()
+	config.Animation.Loop = false
+	frames := Record(config)
+
+	if last := plain(frames[len(frames)-1]); !strings.Contains(last, "( -- )") {
+		t.Errorf("Last frame should be the animation's last frame:\n%s", last)
+	}
+}
+
+// TestRecordViews tests that recorded views match the model's output
+func TestRecordViews(t *testing.T) {
+	config := testRecordConfig()
+	views := RecordViews(config)
+	frames := Record(config)
+
+	if len(views) != len(frames) {
+		t.Fatalf("Got %d views, want one per frame (%d)", len(views), len(frames))
+	}
+	if !strings.Contains(views[2].View, "( -- )") || views[2].Duration != config.FrameRate {
+		t.Errorf("Unexpected view: %+v", views[2])
+	}
+}
+
+// TestRecordTypingModel tests recording the typing animation of plain output
+func TestRecordTypingModel(t *testing.T) {
+	views := RecordTyping([]string{"ab", "c"}, AnimationTyping, 10*time.Millisecond)
+
+	want := []string{"", "a▋", "ab", "ab\nc"}
+	if len(views) != len(want) {
+		t.Fatalf("Got %d views, want %d: %+v", len(views), len(want), views)
+	}
+	for i, view := range views {
+		if view.View != want[i] {
+			t.Errorf("View %d = %q, want %q", i, view.View, want[i])
+		}
+	}
+	if views[0].Duration != 10*time.Millisecond || views[len(views)-1].Duration != 0 {
+		t.Errorf("Unexpected durations: %+v", views)
+	}
+
+	if still := RecordTyping([]string{"ab"}, AnimationNone, 0); len(still) != 1 || still[0].View != "ab" {
+		t.Errorf("Without typing, want a single full view, got %+v", still)
+	}
+}
@@ -0,0 +1,352 @@
+// Package ansi parses ANSI escape sequences in styled terminal text, such as
+// syntax highlighted code or colored command output, so it can be measured
+// and drawn cell by cell.
+package ansi
+
+import (
+	"iter"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/uniseg"
+)
+
+const (
+	esc = '\x1b'
+	bel = '\x07'
+)
+
+// Segment is a run of text drawn with a single style.
+type Segment struct {
+	Text  string
+	Style lipgloss.Style
+	Link  string // Target of the OSC 8 hyperlink the text is in, if any
+}
+
+// Token is either plain text or a complete escape sequence.
+type Token struct {
+	Text   string
+	Escape bool
+}
+
+// Tokenize splits s into plain text and escape sequences. CSI sequences end
+// at their final byte, OSC sequences at BEL or ST; any other escape consumes
+// the byte that follows it.
+func Tokenize(s string) []Token {
+	var tokens []Token
+	start := 0
+	for i := 0; i < len(s); {
+		if s[i] != esc {
+			i++
+			continue
+		}
+		if i > start {
+			tokens = append(tokens, Token{Text: s[start:i]})
+		}
+
+		end := sequenceEnd(s, i)
+		tokens = append(tokens, Token{Text: s[i:end], Escape: true})
+		i, start = end, end
+	}
+	if start < len(s) {
+		tokens = append(tokens, Token{Text: s[start:]})
+	}
+	return tokens
+}
+
+// sequenceEnd returns the index just past the escape sequence starting at i.
+func sequenceEnd(s string, i int) int {
+	if i+1 >= len(s) {
+		return len(s)
+	}
+
+	switch s[i+1] {
+	case '[': // CSI: parameters and intermediates, then a final byte
+		for j := i + 2; j < len(s); j++ {
+			if s[j] >= 0x40 && s[j] <= 0x7e {
+				return j + 1
+			}
+		}
+		return len(s)
+	case ']': // OSC: terminated by BEL or ESC \
+		for j := i + 2; j < len(s); j++ {
+			if s[j] == bel {
+				return j + 1
+			}
+			if s[j] == esc && j+1 < len(s) && s[j+1] == '\\' {
+				return j + 2
+			}
+		}
+		return len(s)
+	default:
+		return i + 2
+	}
+}
+
+// sgrParams returns the parameters of an SGR sequence ("\x1b[...m") and
+// whether seq is one.
+func sgrParams(seq string) (string, bool) {
+	if len(seq) < 3 || seq[1] != '[' || seq[len(seq)-1] != 'm' {
+		return "", false
+	}
+	return seq[2 : len(seq)-1], true
+}
+
+// HyperlinkTarget returns the URL an OSC 8 hyperlink sequence
+// ("\x1b]8;params;url\x1b\\") opens, "" for one that closes a link, and
+// whether seq is one.
+func HyperlinkTarget(seq string) (string, bool) {
+	body, ok := strings.CutPrefix(seq, "\x1b]8;")
+	if !ok {
+		return "", false
+	}
+	body = strings.TrimSuffix(strings.TrimSuffix(body, "\x1b\\"), string(bel))
+	_, url, ok := strings.Cut(body, ";")
+	return url, ok
+}
+
+// CloseHyperlink ends an OSC 8 hyperlink.
+const CloseHyperlink = "\x1b]8;;\x1b\\"
+
+// OpenHyperlink starts an OSC 8 hyperlink to url.
+func OpenHyperlink(url string) string {
+	return "\x1b]8;;" + url + "\x1b\\"
+}
+
+// Hyperlink wraps text in OSC 8 sequences linking it to url.
+func Hyperlink(text, url string) string {
+	return OpenHyperlink(url) + text + CloseHyperlink
+}
+
+// Strip removes all escape sequences from s.
+func Strip(s string) string {
+	if !strings.ContainsRune(s, esc) {
+		return s
+	}
+	var b strings.Builder
+	for _, t := range Tokenize(s) {
+		if !t.Escape {
+			b.WriteString(t.Text)
+		}
+	}
+	return b.String()
+}
+
+// StringWidth returns the display width of s, ignoring escape sequences.
+// Grapheme clusters, such as a letter with combining accents or an emoji
+// joined with ZWJ, are measured as a whole.
+func StringWidth(s string) int {
+	return runewidth.StringWidth(Strip(s))
+}
+
+// Graphemes returns an iterator over the grapheme clusters of s with their
+// display widths. A cluster is what a user sees as a single character: a
+// letter with its accents, or an emoji with its modifiers. s should not
+// contain escape sequences.
+func Graphemes(s string) iter.Seq2[string, int] {
+	return func(yield func(string, int) bool) {
+		state := -1
+		for s != "" {
+			var cluster string
+			cluster, s, _, state = uniseg.FirstGraphemeClusterInString(s, state)
+			if !yield(cluster, runewidth.StringWidth(cluster)) {
+				return
+			}
+		}
+	}
+}
+
+// Parse splits s into segments of text, applying SGR sequences on top of
+// base. A reset returns to base. OSC 8 hyperlinks set the segments' links;
+// other escape sequences are dropped.
+func Parse(s string, base lipgloss.Style) []Segment {
+	var segments []Segment
+	style := base
+	link := ""
+	for _, t := range Tokenize(s) {
+		if !t.Escape {
+			segments = append(segments, Segment{Text: t.Text, Style: style, Link: link})
+			continue
+		}
+		if params, ok := sgrParams(t.Text); ok {
+			style = ApplySGR(style, base, params)
+		} else if url, ok := HyperlinkTarget(t.Text); ok {
+			link = url
+		}
+	}
+	return segments
+}
+
+// ApplySGR applies the parameters of an SGR sequence, such as "1;31", to
+// style. Resets restore the corresponding attributes of base.
+func ApplySGR(style, base lipgloss.Style, params string) lipgloss.Style {
+	codes := strings.Split(params, ";")
+	for i := 0; i < len(codes); i++ {
+		code := codes[i]
+
+		// Colon separated colors, e.g. 38:2::255:0:0 or 38:5:196
+		if strings.Contains(code, ":") {
+			sub := strings.Split(code, ":")
+			if color, ok := extendedColor(sub[1:], true); ok {
+				style = setColor(style, sub[0], color)
+			}
+			continue
+		}
+
+		n, err := strconv.Atoi(code)
+		if code == "" {
+			n, err = 0, nil
+		}
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case n == 0:
+			style = base
+		case n == 1:
+			style = style.Bold(true)
+		case n == 2:
+			style = style.Faint(true)
+		case n == 3:
+			style = style.Italic(true)
+		case n == 4:
+			style = style.Underline(true)
+		case n == 5 || n == 6:
+			style = style.Blink(true)
+		case n == 7:
+			style = style.Reverse(true)
+		case n == 9:
+			style = style.Strikethrough(true)
+		case n == 22:
+			style = style.Bold(base.GetBold()).Faint(base.GetFaint())
+		case n == 23:
+			style = style.Italic(base.GetItalic())
+		case n == 24:
+			style = style.Underline(base.GetUnderline())
+		case n == 25:
+			style = style.Blink(base.GetBlink())
+		case n == 27:
+			style = style.Reverse(base.GetReverse())
+		case n == 29:
+			style = style.Strikethrough(base.GetStrikethrough())
+		case n >= 30 && n <= 37:
+			style = style.Foreground(lipgloss.Color(strconv.Itoa(n - 30)))
+		case n >= 90 && n <= 97:
+			style = style.Foreground(lipgloss.Color(strconv.Itoa(n - 90 + 8)))
+		case n == 39:
+			style = style.Foreground(base.GetForeground())
+		case n >= 40 && n <= 47:
+			style = style.Background(lipgloss.Color(strconv.Itoa(n - 40)))
+		case n >= 100 && n <= 107:
+			style = style.Background(lipgloss.Color(strconv.Itoa(n - 100 + 8)))
+		case n == 49:
+			style = style.Background(base.GetBackground())
+		case n == 38 || n == 48 || n == 58:
+			// Semicolon separated colors: 38;5;n or 38;2;r;g;b
+			color, ok := extendedColor(codes[i+1:], false)
+			if ok {
+				style = setColor(style, code, color)
+			}
+			if i+1 < len(codes) && codes[i+1] == "5" {
+				i += 2
+			} else if i+1 < len(codes) && codes[i+1] == "2" {
+				i += 4
+			}
+		}
+	}
+	return style
+}
+
+// extendedColor parses the arguments of a 38/48 color: "5;n" for the 256
+// color palette or "2;r;g;b" for true color. The colon form may include an
+// empty color space id before the components.
+func extendedColor(args []string, colon bool) (lipgloss.Color, bool) {
+	if len(args) < 2 {
+		return "", false
+	}
+
+	switch args[0] {
+	case "5":
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 0 || n > 255 {
+			return "", false
+		}
+		return lipgloss.Color(strconv.Itoa(n)), true
+	case "2":
+		rgb := args[1:]
+		if colon && len(rgb) == 4 {
+			rgb = rgb[1:] // Skip the color space id
+		}
+		if len(rgb) < 3 {
+			return "", false
+		}
+		var hex strings.Builder
+		hex.WriteByte('#')
+		for _, c := range rgb[:3] {
+			v, err := strconv.Atoi(c)
+			if err != nil || v < 0 || v > 255 {
+				return "", false
+			}
+			hex.WriteString(strconv.FormatInt(int64(v)|0x100, 16)[1:])
+		}
+		return lipgloss.Color(hex.String()), true
+	}
+	return "", false
+}
+
+// setColor sets the foreground for code 38 and the background for 48.
+// Underline colors (58) are not supported by lipgloss and are ignored.
+func setColor(style lipgloss.Style, code string, color lipgloss.Color) lipgloss.Style {
+	switch code {
+	case "38":
+		return style.Foreground(color)
+	case "48":
+		return style.Background(color)
+	}
+	return style
+}
+
+// CarryStyles makes each line self-contained when styled text is split
+// across lines: styles and hyperlinks left open at the end of a line are
+// closed and reopened at the start of the next.
+func CarryStyles(lines []string) []string {
+	result := make([]string, len(lines))
+	active := "" // SGR sequences in effect since the last reset
+	link := ""   // OSC 8 sequence of the open hyperlink
+	for i, line := range lines {
+		result[i] = link + active + line
+		for _, t := range Tokenize(line) {
+			if !t.Escape {
+				continue
+			}
+			if url, ok := HyperlinkTarget(t.Text); ok {
+				link = ""
+				if url != "" {
+					link = t.Text
+				}
+				continue
+			}
+			params, ok := sgrParams(t.Text)
+			if !ok {
+				continue
+			}
+			if first, _, _ := strings.Cut(params, ";"); first == "" || first == "0" {
+				active = ""
+				if params == "" || params == "0" {
+					continue
+				}
+			}
+			active += t.Text
+		}
+		if active != "" {
+			result[i] += "\x1b[0m"
+		}
+		if link != "" {
+			result[i] += "\x1b]8;;\x1b\\"
+		}
+	}
+	return result
+}
@@ -0,0 +1,240 @@
+package ansi
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// TestStrip tests removing escape sequences
+func TestStrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"plain", "Hello", "Hello"},
+		{"sgr", "\x1b[1;31mRed\x1b[0m text", "Red text"},
+		{"cursor movement", "a\x1b[2Kb\x1b[10;5Hc", "abc"},
+		{"osc with bel", "\x1b]0;title\x07shown", "shown"},
+		{"osc with st", "\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\", "link"},
+		{"two byte escape", "\x1b(Bok", "Bok"},
+		{"truncated", "ok\x1b[31", "ok"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Strip(tt.input); got != tt.want {
+				t.Errorf("Strip(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestStringWidth tests measuring styled text
+func TestStringWidth(t *testing.T) {
+	if got := StringWidth("\x1b[32m日本\x1b[0m!"); got != 5 {
+		t.Errorf("StringWidth = %d, want 5", got)
+	}
+}
+
+// TestParse tests converting SGR sequences to styles
+func TestParse(t *testing.T) {
+	base := lipgloss.NewStyle().Foreground(lipgloss.Color("7"))
+
+	tests := []struct {
+		name  string
+		input string
+		check func(t *testing.T, style lipgloss.Style)
+	}{
+		{"basic foreground", "\x1b[31mx", func(t *testing.T, s lipgloss.Style) {
+			if s.GetForeground() != lipgloss.Color("1") {
+				t.Errorf("Foreground = %v, want 1", s.GetForeground())
+			}
+		}},
+		{"bright background", "\x1b[102mx", func(t *testing.T, s lipgloss.Style) {
+			if s.GetBackground() != lipgloss.Color("10") {
+				t.Errorf("Background = %v, want 10", s.GetBackground())
+			}
+		}},
+		{"256 colors", "\x1b[1;38;5;196mx", func(t *testing.T, s lipgloss.Style) {
+			if s.GetForeground() != lipgloss.Color("196") || !s.GetBold() {
+				t.Errorf("Got foreground %v bold %v, want 196 bold", s.GetForeground(), s.GetBold())
+			}
+		}},
+		{"true color", "\x1b[48;2;255;128;0mx", func(t *testing.T, s lipgloss.Style) {
+			if s.GetBackground() != lipgloss.Color("#ff8000") {
+				t.Errorf("Background = %v, want #ff8000", s.GetBackground())
+			}
+		}},
+		{"colon true color", "\x1b[38:2::0:0:255mx", func(t *testing.T, s lipgloss.Style) {
+			if s.GetForeground() != lipgloss.Color("#0000ff") {
+				t.Errorf("Foreground = %v, want #0000ff", s.GetForeground())
+			}
+		}},
+		{"default foreground", "\x1b[31;39mx", func(t *testing.T, s lipgloss.Style) {
+			if s.GetForeground() != lipgloss.Color("7") {
+				t.Errorf("Foreground = %v, want base 7", s.GetForeground())
+			}
+		}},
+		{"attributes", "\x1b[3;4;9mx", func(t *testing.T, s lipgloss.Style) {
+			if !s.GetItalic() || !s.GetUnderline() || !s.GetStrikethrough() {
+				t.Error("Expected italic, underline and strikethrough")
+			}
+		}},
+		{"reset", "\x1b[1;31m\x1b[mx", func(t *testing.T, s lipgloss.Style) {
+			if s.GetBold() || s.GetForeground() != lipgloss.Color("7") {
+				t.Error("Reset should return to the base style")
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			segments := Parse(tt.input, base)
+			if len(segments) != 1 || segments[0].Text != "x" {
+				t.Fatalf("Expected a single \"x\" segment, got %+v", segments)
+			}
+			tt.check(t, segments[0].Style)
+		})
+	}
+}
+
+// TestParseSegments tests splitting text at style changes
+func TestParseSegments(t *testing.T) {
+	segments := Parse("a\x1b[31mb\x1b[0mc", lipgloss.NewStyle())
+
+	var texts []string
+	for _, seg := range segments {
+		texts = append(texts, seg.Text)
+	}
+	if !slices.Equal(texts, []string{"a", "b", "c"}) {
+		t.Fatalf("Segments = %q, want [a b c]", texts)
+	}
+	if segments[1].Style.GetForeground() != lipgloss.Color("1") {
+		t.Error("Second segment should be red")
+	}
+	if segments[2].Style.GetForeground() != (lipgloss.NoColor{}) {
+		t.Error("Third segment should be unstyled")
+	}
+}
+
+// TestParseHyperlinks tests OSC 8 hyperlinks in parsed text
+func TestParseHyperlinks(t *testing.T) {
+	input := "see " + Hyperlink("the docs", "https://example.com") + "\x1b]8;id=x;https://b.example\x07b\x1b]8;;\x07."
+	segments := Parse(input, lipgloss.NewStyle())
+
+	var got [][2]string
+	for _, seg := range segments {
+		got = append(got, [2]string{seg.Text, seg.Link})
+	}
+	want := [][2]string{{"see ", ""}, {"the docs", "https://example.com"}, {"b", "https://b.example"}, {".", ""}}
+	if !slices.Equal(got, want) {
+		t.Errorf("Segments = %q, want %q", got, want)
+	}
+	if w := StringWidth(input); w != 14 {
+		t.Errorf("StringWidth = %d, want 14", w)
+	}
+}
+
+// TestGraphemes tests splitting text into grapheme clusters
+func TestGraphemes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		clusters []string
+		widths   []int
+	}{
+		{"ascii", "ab", []string{"a", "b"}, []int{1, 1}},
+		{"combining marks", "\u2022\u0301\ufe35\u2022\u0300", []string{"\u2022\u0301", "\ufe35", "\u2022\u0300"}, []int{1, 2, 1}},
+		{"skin tone", "\U0001f44d\U0001f3fd!", []string{"\U0001f44d\U0001f3fd", "!"}, []int{2, 1}},
+		{"zwj sequence", "\U0001f469\u200d\U0001f4bb", []string{"\U0001f469\u200d\U0001f4bb"}, []int{2}},
+		{"wide", "\u4e16\u754c", []string{"\u4e16", "\u754c"}, []int{2, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var clusters []string
+			var widths []int
+			for g, w := range Graphemes(tt.input) {
+				clusters = append(clusters, g)
+				widths = append(widths, w)
+			}
+			if !slices.Equal(clusters, tt.clusters) || !slices.Equal(widths, tt.widths) {
+				t.Errorf("Graphemes(%q) = %q %v, want %q %v", tt.input, clusters, widths, tt.clusters, tt.widths)
+			}
+			if got, want := StringWidth(tt.input), sum(tt.widths); got != want {
+				t.Errorf("StringWidth(%q) = %d, want %d", tt.input, got, want)
+			}
+		})
+	}
+}
+
+// sum adds up ints.
+func sum(ns []int) int {
+	total := 0
+	for _, n := range ns {
+		total += n
+	}
+	return total
+}
+
+// TestTokenize tests splitting text from escape sequences
+func TestTokenize(t *testing.T) {
+	got := Tokenize("a\x1b[1mb\x1b]8;;https://example.com\x1b\\c\x07\x1b]8;;\x07")
+	want := []Token{
+		{Text: "a"},
+		{Text: "\x1b[1m", Escape: true},
+		{Text: "b"},
+		{Text: "\x1b]8;;https://example.com\x1b\\", Escape: true},
+		{Text: "c\x07"},
+		{Text: "\x1b]8;;\x07", Escape: true},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Tokenize = %+v, want %+v", got, want)
+	}
+}
+
+// TestCarryStyles tests reopening styles across wrapped lines
+func TestCarryStyles(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  []string
+	}{
+		{
+			name:  "plain",
+			lines: []string{"one", "two"},
+			want:  []string{"one", "two"},
+		},
+		{
+			name:  "open style",
+			lines: []string{"\x1b[31mred", "still red\x1b[0m", "plain"},
+			want:  []string{"\x1b[31mred\x1b[0m", "\x1b[31mstill red\x1b[0m", "plain"},
+		},
+		{
+			name:  "stacked styles",
+			lines: []string{"\x1b[1mbold \x1b[32mgreen", "end"},
+			want:  []string{"\x1b[1mbold \x1b[32mgreen\x1b[0m", "\x1b[1m\x1b[32mend\x1b[0m"},
+		},
+		{
+			name:  "open hyperlink",
+			lines: []string{"\x1b]8;;https://example.com\x1b\\a", "b\x1b]8;;\x1b\\ c"},
+			want:  []string{"\x1b]8;;https://example.com\x1b\\a\x1b]8;;\x1b\\", "\x1b]8;;https://example.com\x1b\\b\x1b]8;;\x1b\\ c"},
+		},
+		{
+			name:  "reset with new style",
+			lines: []string{"\x1b[1ma\x1b[0;34mb", "c"},
+			want:  []string{"\x1b[1ma\x1b[0;34mb\x1b[0m", "\x1b[0;34mc\x1b[0m"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CarryStyles(tt.lines); !slices.Equal(got, tt.want) {
+				t.Errorf("CarryStyles(%q) = %q, want %q", tt.lines, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Package ascii transliterates the unicode decorations of bubbles,
+// expressions and effects to plain ASCII for terminals and log viewers that
+// cannot show them, such as serial consoles.
+package ascii
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// table maps decoration runes to ASCII text of the same display width, so
+// transliterated bubbles and characters stay aligned.
+var table = map[rune]string{
+	// Box drawing
+	'─': "-", '━': "-", '═': "=", '│': "|", '┃': "|", '║': "|",
+	'┌': "+", '┐': "+", '└': "+", '┘': "+", '╭': "+", '╮': "+", '╰': "+", '╯': "+",
+	'╔': "+", '╗': "+", '╚': "+", '╝': "+", '├': "+", '┤': "+", '┬': "+", '┴': "+", '┼': "+",
+	'▋': "_", '█': "#", '░': ":", '▒': "%", '▓': "#",
+
+	// Music
+	'♩': "d", '♪': "d", '♫': "J", '♬': "J",
+
+	// Stars and sparkles
+	'☆': "*", '★': "*", '✦': "*", '✧': "*", '✩': "*", '✪': "*", '✫': "*",
+	'✬': "*", '✭': "*", '✮': "*", '✯': "*", '✨': "**", '⭐': "**", '🌟': "**", '💫': "*~",
+
+	// Confetti
+	'·': ".", '°': "o", '•': "o", '◦': "o", '∘': "o", '○': "o",
+
+	// Faces
+	'◕': "o", '◉': "@", '‿': "_", 'ಠ': "0", '︵': "/\\",
+	'\u0300': "", '\u0301': "", // Combining accents take no space
+
+	// Punctuation
+	'…': ".", '—': "-", '–': "-", '‘': "'", '’': "'", '“': "\"", '”': "\"",
+}
+
+// enabled is whether ASCII mode is on.
+var enabled atomic.Bool
+
+// SetEnabled turns ASCII mode on or off for all output.
+func SetEnabled(on bool) {
+	enabled.Store(on)
+}
+
+// Enabled reports whether ASCII mode is on.
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Apply returns s transliterated if ASCII mode is on, and s unchanged
+// otherwise.
+func Apply(s string) string {
+	if !Enabled() {
+		return s
+	}
+	return Transliterate(s)
+}
+
+// Transliterate replaces every non-ASCII rune in s with its ASCII
+// equivalent from the mapping table. Runes without one are replaced with a
+// "?" for each cell they cover.
+func Transliterate(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch text, ok := table[r]; {
+		case r < 0x80:
+			b.WriteRune(r)
+		case ok:
+			b.WriteString(text)
+		default:
+			b.WriteString(strings.Repeat("?", runewidth.RuneWidth(r)))
+		}
+	}
+	return b.String()
+}
+
+// Detect reports whether the locale rules out unicode output: the first of
+// LC_ALL, LC_CTYPE and LANG that is set names a character set other than
+// UTF-8. An unset locale is assumed to support unicode.
+func Detect(getenv func(string) string) bool {
+	for _, key := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		locale := getenv(key)
+		if locale == "" {
+			continue
+		}
+		locale = strings.ToLower(locale)
+		return !strings.Contains(locale, "utf-8") && !strings.Contains(locale, "utf8")
+	}
+	return false
+}
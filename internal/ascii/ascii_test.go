@@ -0,0 +1,91 @@
+package ascii
+
+import (
+	"testing"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// TestTableWidths tests that every replacement is as wide as its rune
+func TestTableWidths(t *testing.T) {
+	for r, text := range table {
+		if got, want := len(text), runewidth.RuneWidth(r); got != want {
+			t.Errorf("%q maps to %q of width %d, want width %d", r, text, got, want)
+		}
+		for _, c := range text {
+			if c >= 0x80 {
+				t.Errorf("%q maps to non-ASCII %q", r, text)
+			}
+		}
+	}
+}
+
+// TestTransliterate tests replacing unicode with ASCII
+func TestTransliterate(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"plain <text>", "plain <text>"},
+		{"╔══╗", "+==+"},
+		{"│ code │", "| code |"},
+		{"✨ dream ✨", "** dream **"},
+		{"◕‿◕", "o_o"},
+		{"•\u0301︵•\u0300", "o/\\o"},
+		{"♪ la ♫", "d la J"},
+		{"\x1b[31m★\x1b[0m", "\x1b[31m*\x1b[0m"},
+		{"世 ☃", "?? ?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := Transliterate(tt.input)
+			if got != tt.want {
+				t.Errorf("Transliterate(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+			if runewidth.StringWidth(got) != runewidth.StringWidth(tt.input) {
+				t.Errorf("Transliterate(%q) changed the width", tt.input)
+			}
+		})
+	}
+}
+
+// TestApply tests that text is only transliterated in ASCII mode
+func TestApply(t *testing.T) {
+	defer SetEnabled(Enabled())
+
+	SetEnabled(false)
+	if got := Apply("☆"); got != "☆" {
+		t.Errorf("Apply with ASCII mode off = %q, want it unchanged", got)
+	}
+	SetEnabled(true)
+	if got := Apply("☆"); got != "*" {
+		t.Errorf("Apply with ASCII mode on = %q, want %q", got, "*")
+	}
+}
+
+// TestDetect tests detecting ASCII terminals from the locale
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{"unset", nil, false},
+		{"utf-8", map[string]string{"LANG": "en_US.UTF-8"}, false},
+		{"utf8", map[string]string{"LANG": "C.utf8"}, false},
+		{"C", map[string]string{"LANG": "C"}, true},
+		{"POSIX", map[string]string{"LC_CTYPE": "POSIX", "LANG": "en_US.UTF-8"}, true},
+		{"latin-1", map[string]string{"LANG": "de_DE.ISO-8859-1"}, true},
+		{"LC_ALL wins", map[string]string{"LC_ALL": "en_US.UTF-8", "LC_CTYPE": "C"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			if got := Detect(getenv); got != tt.want {
+				t.Errorf("Detect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Package asciicast writes terminal animations as asciicast v2 recordings,
+// which asciinema and its web player can replay.
+package asciicast
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MagikIO/familiar-says/internal/ansi"
+)
+
+// Frame is a rendered view and how long it is shown.
+type Frame struct {
+	View     string
+	Duration time.Duration
+}
+
+// header is the first line of an asciicast v2 file.
+type header struct {
+	Version int    `json:"version"`
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+	Title   string `json:"title,omitempty"`
+}
+
+// Terminal control sequences used to redraw frames in place.
+const (
+	hideCursor = "\x1b[?25l"
+	showCursor = "\x1b[?25h"
+	clearLine  = "\x1b[K"
+	clearBelow = "\x1b[J"
+)
+
+// Write writes frames to w as an asciicast v2 recording. Each frame is drawn
+// over the previous one in place, as a Bubble Tea program draws inline, and
+// identical consecutive frames are merged. The terminal size is the largest
+// frame plus a line for the final newline.
+func Write(w io.Writer, frames []Frame, title string) error {
+	if len(frames) == 0 {
+		return errors.New("no frames to record")
+	}
+
+	width, height := 1, 1
+	for _, frame := range frames {
+		for _, line := range strings.Split(frame.View, "\n") {
+			width = max(width, ansi.StringWidth(line))
+		}
+		height = max(height, strings.Count(frame.View, "\n")+2)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(header{Version: 2, Width: width, Height: height, Title: title}); err != nil {
+		return err
+	}
+	event := func(at time.Duration, data string) error {
+		seconds := json.Number(strconv.FormatFloat(at.Seconds(), 'f', 6, 64))
+		return enc.Encode([]any{seconds, "o", data})
+	}
+
+	var at time.Duration
+	var previous []string
+	for i, frame := range frames {
+		lines := strings.Split(frame.View, "\n")
+		if i > 0 && frame.View == frames[i-1].View {
+			at += frame.Duration
+			continue
+		}
+
+		var out strings.Builder
+		if previous == nil {
+			out.WriteString(hideCursor)
+		} else {
+			// Return to the start of the previous frame
+			if len(previous) > 1 {
+				fmt.Fprintf(&out, "\x1b[%dA", len(previous)-1)
+			}
+			out.WriteString("\r")
+		}
+		for j, line := range lines {
+			if j > 0 {
+				out.WriteString("\r\n")
+			}
+			out.WriteString(line + clearLine)
+		}
+		out.WriteString(clearBelow)
+
+		if err := event(at, out.String()); err != nil {
+			return err
+		}
+		at += frame.Duration
+		previous = lines
+	}
+	return event(at, "\r\n"+showCursor)
+}
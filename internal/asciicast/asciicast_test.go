@@ -0,0 +1,61 @@
+package asciicast
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestWrite tests the header and timed output events
+func TestWrite(t *testing.T) {
+	frames := []Frame{
+		{View: "Hi", Duration: 50 * time.Millisecond},
+		{View: "Hi", Duration: 50 * time.Millisecond},
+		{View: "Hello\n\x1b[31mthere\x1b[0m", Duration: 250 * time.Millisecond},
+		{View: "Bye"},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, frames, "demo"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	var h header
+	if err := json.Unmarshal([]byte(lines[0]), &h); err != nil {
+		t.Fatalf("Invalid header %q: %v", lines[0], err)
+	}
+	if want := (header{Version: 2, Width: 5, Height: 3, Title: "demo"}); h != want {
+		t.Errorf("Header = %+v, want %+v", h, want)
+	}
+
+	// The repeated frame is merged, and a last event ends the recording
+	wantTimes := []float64{0, 0.1, 0.35, 0.35}
+	if len(lines)-1 != len(wantTimes) {
+		t.Fatalf("Got %d events, want %d:\n%s", len(lines)-1, len(wantTimes), buf.String())
+	}
+	var data []string
+	for i, line := range lines[1:] {
+		var ev []any
+		if err := json.Unmarshal([]byte(line), &ev); err != nil || len(ev) != 3 {
+			t.Fatalf("Invalid event %q: %v", line, err)
+		}
+		if ev[0].(float64) != wantTimes[i] || ev[1] != "o" {
+			t.Errorf("Event %d = %v, want time %v", i, ev, wantTimes[i])
+		}
+		data = append(data, ev[2].(string))
+	}
+
+	// The last frame moves back up over the two line frame before it
+	if !strings.HasPrefix(data[2], "\x1b[1A\r") || !strings.Contains(data[2], "Bye") {
+		t.Errorf("Frame should redraw in place, got %q", data[2])
+	}
+	if !strings.Contains(data[1], "Hello\x1b[K\r\n\x1b[31mthere") {
+		t.Errorf("Lines should end with CRLF, got %q", data[1])
+	}
+
+	if err := Write(&bytes.Buffer{}, nil, ""); err == nil {
+		t.Error("Expected an error without frames")
+	}
+}
@@ -2,7 +2,9 @@ package bubble
 
 import (
 	"strings"
-	"unicode/utf8"
+
+	"github.com/MagikIO/familiar-says/internal/ansi"
+	"github.com/MagikIO/familiar-says/internal/textwrap"
 )
 
 // Style represents the bubble style
@@ -51,7 +53,7 @@ func (b *Bubble) Render() []string {
 
 	maxLen := 0
 	for _, line := range lines {
-		if l := utf8.RuneCountInString(line); l > maxLen {
+		if l := ansi.StringWidth(line); l > maxLen {
 			maxLen = l
 		}
 	}
@@ -85,35 +87,12 @@ func (b *Bubble) Render() []string {
 
 // wrapText wraps text to fit within width
 func (b *Bubble) wrapText(text string, width int) []string {
-	words := strings.Fields(text)
-	if len(words) == 0 {
-		return []string{}
-	}
-
-	lines := []string{}
-	current := ""
-
-	for _, word := range words {
-		if current == "" {
-			current = word
-		} else if utf8.RuneCountInString(current+" "+word) <= width {
-			current += " " + word
-		} else {
-			lines = append(lines, current)
-			current = word
-		}
-	}
-
-	if current != "" {
-		lines = append(lines, current)
-	}
-
-	return lines
+	return textwrap.Wrap(text, width)
 }
 
 // padRight pads a string to the right with spaces
 func (b *Bubble) padRight(s string, width int) string {
-	l := utf8.RuneCountInString(s)
+	l := ansi.StringWidth(s)
 	if l >= width {
 		return s
 	}
@@ -3,6 +3,8 @@ package bubble
 import (
 	"strings"
 	"testing"
+
+	"github.com/MagikIO/familiar-says/internal/ansi"
 )
 
 func TestNew(t *testing.T) {
@@ -103,6 +105,26 @@ func TestRenderSingleLine(t *testing.T) {
 	}
 }
 
+func TestRenderGraphemes(t *testing.T) {
+	b := New("caf\u00e9 cafe\u0301 \U0001f469\u200d\U0001f4bb \u4e16\u754c", 9, StyleSay)
+	lines := b.Render()
+
+	// Borders are one column shorter than the content lines
+	want := ansi.StringWidth(lines[0]) + 1
+	for _, line := range lines[1 : len(lines)-1] {
+		if w := ansi.StringWidth(line); w != want {
+			t.Errorf("Line %q is %d wide, want %d", line, w, want)
+		}
+	}
+
+	lines = RenderLines("\u2022\u0301\ufe35\u2022\u0300 so sad", 40, StyleSay)
+	for _, line := range lines {
+		if w := ansi.StringWidth(line); w != ansi.StringWidth(lines[0]) {
+			t.Errorf("Template line %q is %d wide, want %d", line, w, ansi.StringWidth(lines[0]))
+		}
+	}
+}
+
 func TestRenderMultiLine(t *testing.T) {
 	b := New("Hello World this is a test", 10, StyleSay)
 	lines := b.Render()
@@ -2,14 +2,29 @@ package bubble
 
 import (
 	"bytes"
+	"path/filepath"
 	"strings"
 
+	"github.com/MagikIO/familiar-says/internal/ansi"
+	"github.com/MagikIO/familiar-says/internal/ascii"
 	"github.com/alecthomas/chroma/v2"
 	"github.com/alecthomas/chroma/v2/formatters"
 	"github.com/alecthomas/chroma/v2/lexers"
 	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/muesli/termenv"
 )
 
+// tabWidth is the number of columns between tab stops in code bubbles.
+const tabWidth = 4
+
+// Span is a run of text drawn with a single style.
+type Span struct {
+	Text  string
+	Style lipgloss.Style
+}
+
 // HighlightConfig configures syntax highlighting for code bubbles
 type HighlightConfig struct {
 	Language string // Language to highlight (e.g., "go", "python", "javascript")
@@ -28,17 +43,7 @@ func DefaultHighlightConfig() HighlightConfig {
 // It returns ANSI-colored text suitable for terminal output.
 func HighlightCode(code string, config HighlightConfig) string {
 	// Get lexer
-	var lexer chroma.Lexer
-	if config.Language != "" {
-		lexer = lexers.Get(config.Language)
-	}
-	if lexer == nil {
-		lexer = lexers.Analyse(code)
-	}
-	if lexer == nil {
-		lexer = lexers.Fallback
-	}
-	lexer = chroma.Coalesce(lexer)
+	lexer := getLexer(code, config.Language)
 
 	// Get style
 	style := styles.Get(config.Style)
@@ -46,8 +51,8 @@ func HighlightCode(code string, config HighlightConfig) string {
 		style = styles.Fallback
 	}
 
-	// Use terminal256 formatter for ANSI output
-	formatter := formatters.Get("terminal256")
+	// Match the formatter to the color profile output is rendered with
+	formatter := formatters.Get(terminalFormatter(lipgloss.ColorProfile()))
 	if formatter == nil {
 		formatter = formatters.Fallback
 	}
@@ -67,6 +72,19 @@ func HighlightCode(code string, config HighlightConfig) string {
 	return buf.String()
 }
 
+// terminalFormatter returns the chroma formatter for a color profile.
+func terminalFormatter(profile termenv.Profile) string {
+	switch profile {
+	case termenv.TrueColor:
+		return "terminal16m"
+	case termenv.ANSI256:
+		return "terminal256"
+	case termenv.ANSI:
+		return "terminal16"
+	}
+	return "noop"
+}
+
 // HighlightCodeLines applies syntax highlighting and returns lines.
 // This is useful for bubble rendering where we need individual lines.
 func HighlightCodeLines(code string, config HighlightConfig) []string {
@@ -74,9 +92,15 @@ func HighlightCodeLines(code string, config HighlightConfig) []string {
 	return strings.Split(highlighted, "\n")
 }
 
-// DetectLanguage attempts to detect the programming language from code content.
+// minLanguageConfidence is the lowest chroma analysis score a detected
+// language needs. Weaker guesses, such as GDScript for any line starting
+// with "func", are wrong more often than not.
+const minLanguageConfidence = 0.5
+
+// DetectLanguage attempts to detect the programming language from code
+// content. It returns "" unless the language is detected with confidence.
 func DetectLanguage(code string) string {
-	lexer := lexers.Analyse(code)
+	lexer := analyseLexer(code)
 	if lexer != nil {
 		config := lexer.Config()
 		if config != nil && len(config.Aliases) > 0 {
@@ -107,7 +131,8 @@ func IsValidLanguage(lang string) bool {
 
 // IsValidStyle checks if a style name is valid.
 func IsValidStyle(style string) bool {
-	return styles.Get(style) != nil
+	_, ok := styles.Registry[style]
+	return ok
 }
 
 // RenderCodeBubble renders a code block with optional syntax highlighting.
@@ -126,87 +151,256 @@ func RenderCodeBubble(code string, width int, config HighlightConfig) []string {
 }
 
 // CodeBubbleWithLanguage creates a code bubble with language header.
+// Lines and indentation are kept as written; lines wider than width are
+// truncated.
 func CodeBubbleWithLanguage(code, language string, width int, config HighlightConfig) []string {
+	spans := CodeBubbleSpans(code, language, width, config, GetTemplate("code"))
+
+	result := make([]string, len(spans))
+	for i, line := range spans {
+		var b strings.Builder
+		for _, span := range line {
+			b.WriteString(span.Style.Render(span.Text))
+		}
+		result[i] = b.String()
+	}
+	return result
+}
+
+// CodeBubbleSpans lays out a code bubble using tmpl's borders, returning
+// each line as styled spans. The language is detected from the code when
+// neither language nor config.Language is set, and shown in the top border.
+func CodeBubbleSpans(code, language string, width int, config HighlightConfig, tmpl *BubbleTemplate) [][]Span {
 	if language == "" {
-		language = DetectLanguage(code)
+		language = config.Language
 	}
-	if config.Language == "" {
-		config.Language = language
+	// Code that is already colored keeps its own colors
+	colored := strings.ContainsRune(code, '\x1b')
+	if language == "" && !colored {
+		language = DetectLanguage(code)
 	}
-	
-	tmpl := GetTemplate("code")
-	
+	config.Language = language
+
 	// Apply syntax highlighting
-	displayCode := code
-	if tmpl.SyntaxHighlight && language != "" {
-		displayCode = HighlightCode(code, config)
+	var lines [][]Span
+	if tmpl.SyntaxHighlight && language != "" && !colored {
+		lines = HighlightSpans(code, config)
+	} else {
+		lines = plainSpans(code)
 	}
-	
-	// Calculate max line length
-	lines := strings.Split(displayCode, "\n")
+
+	langHeader := ""
+	if language != "" {
+		langHeader = " " + language + " "
+	}
+
+	// Calculate max line width, leaving room for the language header
 	maxLen := 0
 	for _, line := range lines {
-		// Strip ANSI codes for width calculation
-		stripped := stripANSI(line)
-		if len(stripped) > maxLen {
-			maxLen = len(stripped)
+		if w := spansWidth(line); w > maxLen {
+			maxLen = w
 		}
 	}
 	if maxLen > width {
 		maxLen = width
 	}
-	
-	result := []string{}
-	
-	// Top border with language indicator
-	langHeader := ""
-	if language != "" {
-		langHeader = " " + language + " "
-	}
-	topBorderLen := maxLen + 2 - len(langHeader)
-	if topBorderLen < 0 {
-		topBorderLen = 0
+	if w := runewidth.StringWidth(langHeader); maxLen < w {
+		maxLen = w
 	}
+
+	result := [][]Span{}
+
+	// Top border with language indicator
+	topBorderLen := maxLen + 2 - runewidth.StringWidth(langHeader)
 	topBorder := tmpl.TopLeftCorner + strings.Repeat(tmpl.TopBorder, topBorderLen/2) + langHeader + strings.Repeat(tmpl.TopBorder, (topBorderLen+1)/2) + tmpl.TopRightCorner
-	result = append(result, topBorder)
-	
-	// Content lines
+	result = append(result, []Span{{Text: topBorder}})
+
+	// Content lines, truncated or padded to the bubble width
 	for _, line := range lines {
-		// Pad to width (accounting for ANSI codes)
-		stripped := stripANSI(line)
-		padding := maxLen - len(stripped)
-		if padding < 0 {
-			padding = 0
-		}
-		paddedLine := line + strings.Repeat(" ", padding)
-		result = append(result, tmpl.SingleLeft+" "+paddedLine+" "+tmpl.SingleRight)
+		line = truncateSpans(line, maxLen)
+		row := []Span{{Text: tmpl.SingleLeft + " "}}
+		row = append(row, line...)
+		row = append(row, Span{Text: strings.Repeat(" ", maxLen-spansWidth(line)) + " " + tmpl.SingleRight})
+		result = append(result, row)
 	}
-	
+
 	// Bottom border
 	bottomBorder := tmpl.BottomLeftCorner + strings.Repeat(tmpl.BottomBorder, maxLen+2) + tmpl.BottomRightCorner
-	result = append(result, bottomBorder)
-	
+	result = append(result, []Span{{Text: bottomBorder}})
+
 	return result
 }
 
-// stripANSI removes ANSI escape codes from a string for width calculation.
-func stripANSI(s string) string {
-	var result strings.Builder
-	inEscape := false
-	
-	for _, r := range s {
-		if r == '\x1b' {
-			inEscape = true
-			continue
+// HighlightSpans tokenizes code and returns each line as spans styled from
+// the chroma style. Tabs are expanded so indentation keeps its width.
+func HighlightSpans(code string, config HighlightConfig) [][]Span {
+	code = expandTabs(strings.TrimRight(code, "\n"))
+
+	style := styles.Get(config.Style)
+	if style == nil {
+		style = styles.Fallback
+	}
+
+	iterator, err := getLexer(code, config.Language).Tokenise(nil, code)
+	if err != nil {
+		return plainSpans(code) // Return unhighlighted on error
+	}
+
+	lines := [][]Span{nil}
+	for _, token := range iterator.Tokens() {
+		tokenStyle := spanStyle(style.Get(token.Type))
+		for i, part := range strings.Split(token.Value, "\n") {
+			if i > 0 {
+				lines = append(lines, nil)
+			}
+			if part != "" {
+				lines[len(lines)-1] = append(lines[len(lines)-1], Span{Text: part, Style: tokenStyle})
+			}
 		}
-		if inEscape {
-			if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
-				inEscape = false
+	}
+
+	// Lexers may add a trailing newline
+	for len(lines) > 1 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// LanguageForFile returns the language for a file name based on its
+// extension, or "" if it is not recognized.
+func LanguageForFile(filename string) string {
+	lexer := lexers.Match(filepath.Base(filename))
+	if lexer == nil {
+		return ""
+	}
+	config := lexer.Config()
+	if len(config.Aliases) > 0 {
+		return config.Aliases[0]
+	}
+	return strings.ToLower(config.Name)
+}
+
+// getLexer returns the lexer for language, detecting it from code if needed.
+func getLexer(code, language string) chroma.Lexer {
+	var lexer chroma.Lexer
+	if language != "" {
+		lexer = lexers.Get(language)
+	}
+	if lexer == nil {
+		lexer = analyseLexer(code)
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	return chroma.Coalesce(lexer)
+}
+
+// analyseLexer returns the lexer chroma's analysers rate highest for code,
+// or nil if none reaches minLanguageConfidence.
+func analyseLexer(code string) chroma.Lexer {
+	var picked chroma.Lexer
+	highest := float32(0)
+	for _, lexer := range lexers.GlobalLexerRegistry.Lexers {
+		if analyser, ok := lexer.(chroma.Analyser); ok {
+			if score := analyser.AnalyseText(code); score > highest {
+				picked, highest = lexer, score
 			}
-			continue
 		}
-		result.WriteRune(r)
 	}
-	
-	return result.String()
+	if highest < minLanguageConfidence {
+		return nil
+	}
+	return picked
+}
+
+// spanStyle converts a chroma style entry to a lipgloss style. Backgrounds
+// are left to the bubble so padding and code share the same background.
+func spanStyle(entry chroma.StyleEntry) lipgloss.Style {
+	style := lipgloss.NewStyle()
+	if entry.Colour.IsSet() {
+		style = style.Foreground(lipgloss.Color(entry.Colour.String()))
+	}
+	if entry.Bold == chroma.Yes {
+		style = style.Bold(true)
+	}
+	if entry.Italic == chroma.Yes {
+		style = style.Italic(true)
+	}
+	if entry.Underline == chroma.Yes {
+		style = style.Underline(true)
+	}
+	return style
+}
+
+// plainSpans splits code into lines without highlighting. ANSI colors
+// already in the code are kept.
+func plainSpans(code string) [][]Span {
+	code = expandTabs(strings.TrimRight(code, "\n"))
+	var lines [][]Span
+	for _, line := range ansi.CarryStyles(strings.Split(code, "\n")) {
+		spans := []Span{}
+		for _, seg := range ansi.Parse(line, lipgloss.NewStyle()) {
+			spans = append(spans, Span{Text: seg.Text, Style: seg.Style})
+		}
+		lines = append(lines, spans)
+	}
+	return lines
+}
+
+// expandTabs replaces tabs with spaces up to the next tab stop.
+func expandTabs(s string) string {
+	if !strings.Contains(s, "\t") {
+		return s
+	}
+	var b strings.Builder
+	col := 0
+	for g, w := range ansi.Graphemes(s) {
+		switch g {
+		case "\t":
+			n := tabWidth - col%tabWidth
+			b.WriteString(strings.Repeat(" ", n))
+			col += n
+		case "\n":
+			b.WriteString(g)
+			col = 0
+		default:
+			b.WriteString(g)
+			col += w
+		}
+	}
+	return b.String()
+}
+
+// spansWidth returns the display width of a line of spans.
+func spansWidth(line []Span) int {
+	w := 0
+	for _, span := range line {
+		w += runewidth.StringWidth(span.Text)
+	}
+	return w
+}
+
+// truncateSpans cuts a line of spans to width columns, marking the cut
+// with an ellipsis.
+func truncateSpans(line []Span, width int) []Span {
+	if spansWidth(line) <= width {
+		return line
+	}
+
+	var result []Span
+	remaining := width - 1 // Leave room for the ellipsis
+	for _, span := range line {
+		if remaining <= 0 {
+			break
+		}
+		text := runewidth.Truncate(span.Text, remaining, "")
+		if text != "" {
+			result = append(result, Span{Text: text, Style: span.Style})
+		}
+		remaining -= runewidth.StringWidth(text)
+		if text != span.Text {
+			break
+		}
+	}
+	return append(result, Span{Text: strings.Repeat(" ", max(remaining, 0)) + ascii.Apply("…")})
 }
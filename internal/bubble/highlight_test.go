@@ -0,0 +1,162 @@
+package bubble
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+const sampleGo = "func main() {\n\tif true {\n\t\tprintln(\"hi\")\n\t}\n}\n"
+
+// TestHighlightSpans tests that highlighting keeps lines and indentation
+func TestHighlightSpans(t *testing.T) {
+	lines := HighlightSpans(sampleGo, HighlightConfig{Language: "go", Style: "monokai"})
+
+	want := []string{"func main() {", "    if true {", "        println(\"hi\")", "    }", "}"}
+	if len(lines) != len(want) {
+		t.Fatalf("Got %d lines, want %d", len(lines), len(want))
+	}
+	for i, line := range lines {
+		var text strings.Builder
+		for _, span := range line {
+			text.WriteString(span.Text)
+		}
+		if text.String() != want[i] {
+			t.Errorf("Line %d = %q, want %q", i, text.String(), want[i])
+		}
+	}
+
+	// The func keyword is colored by the style
+	if lines[0][0].Text != "func" || lines[0][0].Style.GetForeground() == nil {
+		t.Errorf("Expected styled keyword span, got %+v", lines[0][0])
+	}
+}
+
+// TestHighlightCodeProfile tests that highlighted code follows the color
+// profile
+func TestHighlightCodeProfile(t *testing.T) {
+	defer lipgloss.SetColorProfile(lipgloss.ColorProfile())
+	config := HighlightConfig{Language: "go", Style: "monokai"}
+
+	tests := []struct {
+		profile termenv.Profile
+		want    string
+	}{
+		{termenv.TrueColor, "\x1b[38;2;"},
+		{termenv.ANSI256, "\x1b[38;5;"},
+		{termenv.ANSI, "\x1b[9"},
+	}
+	for _, tt := range tests {
+		lipgloss.SetColorProfile(tt.profile)
+		if got := HighlightCode(sampleGo, config); !strings.Contains(got, tt.want) {
+			t.Errorf("Profile %v: want %q in %q", tt.profile, tt.want, got)
+		}
+	}
+
+	lipgloss.SetColorProfile(termenv.Ascii)
+	if got := HighlightCode(sampleGo, config); strings.Contains(got, "\x1b") {
+		t.Errorf("Expected no escape sequences without color, got %q", got)
+	}
+}
+
+// TestCodeBubbleSpans tests the code bubble layout
+func TestCodeBubbleSpans(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		language string
+		width    int
+		want     []string
+	}{
+		{
+			name:     "language header",
+			code:     "x = 1\n  y = 2",
+			language: "python",
+			width:    40,
+			want: []string{
+				"┌─ python ─┐",
+				"│ x = 1    │",
+				"│   y = 2  │",
+				"└──────────┘",
+			},
+		},
+		{
+			name:  "truncated lines",
+			code:  "short\na much longer line",
+			width: 10,
+			want: []string{
+				"┌────────────┐",
+				"│ short      │",
+				"│ a much lo… │",
+				"└────────────┘",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := CodeBubbleSpans(tt.code, tt.language, tt.width, DefaultHighlightConfig(), GetTemplate("code"))
+			if len(lines) != len(tt.want) {
+				t.Fatalf("Got %d lines, want %d", len(lines), len(tt.want))
+			}
+			for i, line := range lines {
+				var text strings.Builder
+				for _, span := range line {
+					text.WriteString(span.Text)
+				}
+				if text.String() != tt.want[i] {
+					t.Errorf("Line %d = %q, want %q", i, text.String(), tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// TestDetectLanguage tests that only confident guesses are used
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want string
+	}{
+		{"shebang", "#!/bin/bash\necho hi", "bash"},
+		{"go", "package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println(1) }", "go"},
+		{"weak guess", "func x() {}", ""},
+		{"plain text", "Hello there", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectLanguage(tt.code); got != tt.want {
+				t.Errorf("DetectLanguage(%q) = %q, want %q", tt.code, got, tt.want)
+			}
+		})
+	}
+
+	// Without a language the bubble has no header
+	lines := CodeBubbleSpans("func x() {}", "", 40, DefaultHighlightConfig(), GetTemplate("code"))
+	if top := lines[0][0].Text; top != "┌─────────────┐" {
+		t.Errorf("Top border = %q, want no language header", top)
+	}
+}
+
+// TestLanguageForFile tests detecting languages from file names
+func TestLanguageForFile(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     string
+	}{
+		{"main.go", "go"},
+		{"/src/app.py", "python"},
+		{"script.js", "js"},
+		{"notes.unknownext", ""},
+	}
+
+	for _, tt := range tests {
+		if got := LanguageForFile(tt.filename); got != tt.want {
+			t.Errorf("LanguageForFile(%q) = %q, want %q", tt.filename, got, tt.want)
+		}
+	}
+}
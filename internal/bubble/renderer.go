@@ -2,6 +2,10 @@ package bubble
 
 import (
 	"strings"
+
+	"github.com/MagikIO/familiar-says/internal/ansi"
+	"github.com/MagikIO/familiar-says/internal/markdown"
+	"github.com/MagikIO/familiar-says/internal/textwrap"
 )
 
 // RenderLines renders the bubble as string lines using the template system.
@@ -13,12 +17,16 @@ func RenderLines(text string, width int, style Style) []string {
 
 // RenderWithTemplate renders a bubble using a specific template.
 func RenderWithTemplate(text string, width int, tmpl *BubbleTemplate) []string {
+	if tmpl.Markdown {
+		text = markdown.Render(text)
+	}
+
 	// Apply prefix/suffix decorators if present
 	if tmpl.Prefix != "" || tmpl.Suffix != "" {
 		text = tmpl.Prefix + text + tmpl.Suffix
 	}
 
-	lines := wrapTextForBubble(text, width)
+	lines := ansi.CarryStyles(wrapTextForBubble(text, width, tmpl.Align, tmpl.Markdown))
 	if len(lines) == 0 {
 		lines = []string{""}
 	}
@@ -26,7 +34,7 @@ func RenderWithTemplate(text string, width int, tmpl *BubbleTemplate) []string {
 	// Calculate max line length
 	maxLen := 0
 	for _, line := range lines {
-		if l := runeCount(line); l > maxLen {
+		if l := textWidth(line); l > maxLen {
 			maxLen = l
 		}
 	}
@@ -52,10 +60,10 @@ func RenderWithTemplate(text string, width int, tmpl *BubbleTemplate) []string {
 
 	// Content lines
 	if len(lines) == 1 {
-		bubbleLines = append(bubbleLines, contentPrefix+tmpl.SingleLeft+" "+padRightRunes(lines[0], maxLen)+" "+tmpl.SingleRight+contentSuffix)
+		bubbleLines = append(bubbleLines, contentPrefix+tmpl.SingleLeft+" "+padRightWidth(lines[0], maxLen)+" "+tmpl.SingleRight+contentSuffix)
 	} else {
 		for i, line := range lines {
-			padded := padRightRunes(line, maxLen)
+			padded := padRightWidth(line, maxLen)
 			var left, right string
 			if i == 0 {
 				left, right = tmpl.MultiFirst[0], tmpl.MultiFirst[1]
@@ -147,46 +155,24 @@ func repeatString(s string, n int) string {
 	return result.String()
 }
 
-// wrapTextForBubble wraps text to fit within the given width.
-func wrapTextForBubble(text string, width int) []string {
-	if text == "" {
-		return []string{}
-	}
-
-	words := strings.Fields(text)
-	if len(words) == 0 {
-		return []string{}
-	}
-
-	lines := []string{}
-	current := ""
-
-	for _, word := range words {
-		if current == "" {
-			current = word
-		} else if runeCount(current+" "+word) <= width {
-			current += " " + word
-		} else {
-			lines = append(lines, current)
-			current = word
-		}
+// wrapTextForBubble wraps text to fit within the given width, keeping its
+// line breaks, and aligns the lines. Markdown list items get a hanging
+// indent if lists is set.
+func wrapTextForBubble(text string, width int, align textwrap.Alignment, lists bool) []string {
+	if lists {
+		return textwrap.WrapLists(text, width, align)
 	}
-
-	if current != "" {
-		lines = append(lines, current)
-	}
-
-	return lines
+	return textwrap.WrapAligned(text, width, align)
 }
 
-// runeCount returns the number of runes in a string
-func runeCount(s string) int {
-	return len([]rune(s))
+// textWidth returns the display width of a string
+func textWidth(s string) int {
+	return ansi.StringWidth(s)
 }
 
-// padRightRunes pads a string with spaces to reach the target width (in runes)
-func padRightRunes(s string, width int) string {
-	current := runeCount(s)
+// padRightWidth pads a string with spaces to reach the target display width
+func padRightWidth(s string, width int) string {
+	current := textWidth(s)
 	if current >= width {
 		return s
 	}
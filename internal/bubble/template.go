@@ -5,6 +5,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/MagikIO/familiar-says/internal/ascii"
+	"github.com/MagikIO/familiar-says/internal/textwrap"
 )
 
 // TailDirection specifies where the bubble tail points
@@ -85,6 +88,12 @@ type BubbleTemplate struct {
 	ExternalBottomLeft  string `json:"externalBottomLeft,omitempty"`  // e.g., "♫" floating outside bottom-left
 	ExternalBottomRight string `json:"externalBottomRight,omitempty"` // e.g., "♫" floating outside bottom-right
 
+	// Text alignment: left (default), center, right or justify
+	Align textwrap.Alignment `json:"align,omitempty"`
+
+	// Render inline Markdown in the text as styles
+	Markdown bool `json:"markdown,omitempty"`
+
 	// For code blocks
 	IsCodeBlock     bool   `json:"isCodeBlock,omitempty"`
 	CodeLanguage    string `json:"codeLanguage,omitempty"` // Optional language hint
@@ -226,8 +235,14 @@ var builtinTemplates = map[string]*BubbleTemplate{
 var templateCache = make(map[string]*BubbleTemplate)
 
 // GetTemplate returns a bubble template by name.
-// It first checks built-in templates, then custom templates.
+// It first checks built-in templates, then custom templates. In ASCII mode
+// the template is transliterated.
 func GetTemplate(name string) *BubbleTemplate {
+	return asciiTemplate(lookupTemplate(name))
+}
+
+// lookupTemplate returns a bubble template by name as defined.
+func lookupTemplate(name string) *BubbleTemplate {
 	// Normalize name
 	name = strings.ToLower(name)
 
@@ -400,7 +415,11 @@ func LoadTemplateFromFile(path string) (*BubbleTemplate, error) {
 func GetOrLoadTemplate(nameOrPath string) (*BubbleTemplate, error) {
 	// Check if it's a file path
 	if strings.HasSuffix(nameOrPath, ".json") || strings.Contains(nameOrPath, string(filepath.Separator)) {
-		return LoadTemplateFromFile(nameOrPath)
+		tmpl, err := LoadTemplateFromFile(nameOrPath)
+		if err != nil {
+			return nil, err
+		}
+		return asciiTemplate(tmpl), nil
 	}
 	
 	// Try as a template name
@@ -411,3 +430,24 @@ func GetOrLoadTemplate(nameOrPath string) (*BubbleTemplate, error) {
 	
 	return nil, os.ErrNotExist
 }
+
+// asciiTemplate returns a copy of tmpl with its borders, connector and
+// decorators transliterated in ASCII mode, and tmpl itself otherwise.
+func asciiTemplate(tmpl *BubbleTemplate) *BubbleTemplate {
+	if !ascii.Enabled() {
+		return tmpl
+	}
+
+	t := *tmpl
+	for _, field := range []*string{
+		&t.TopBorder, &t.BottomBorder,
+		&t.TopLeftCorner, &t.TopRightCorner, &t.BottomLeftCorner, &t.BottomRightCorner,
+		&t.SingleLeft, &t.SingleRight,
+		&t.MultiFirst[0], &t.MultiFirst[1], &t.MultiMiddle[0], &t.MultiMiddle[1], &t.MultiLast[0], &t.MultiLast[1],
+		&t.Connector, &t.Prefix, &t.Suffix, &t.BorderDecorator,
+		&t.ExternalTopLeft, &t.ExternalTopRight, &t.ExternalBottomLeft, &t.ExternalBottomRight,
+	} {
+		*field = ascii.Transliterate(*field)
+	}
+	return &t
+}
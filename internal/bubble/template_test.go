@@ -1,8 +1,12 @@
 package bubble
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
+
+	"github.com/MagikIO/familiar-says/internal/ascii"
+	"github.com/mattn/go-runewidth"
 )
 
 func TestGetTemplate(t *testing.T) {
@@ -30,6 +34,36 @@ func TestGetTemplate(t *testing.T) {
 	}
 }
 
+func TestGetTemplateASCII(t *testing.T) {
+	defer ascii.SetEnabled(ascii.Enabled())
+	ascii.SetEnabled(true)
+
+	for _, name := range []string{"code", "box", "angry", "song", "dream"} {
+		tmpl := GetTemplate(name)
+		orig := builtinTemplates[name]
+		pairs := [][2]string{
+			{tmpl.TopBorder, orig.TopBorder},
+			{tmpl.TopLeftCorner, orig.TopLeftCorner},
+			{tmpl.SingleLeft, orig.SingleLeft},
+			{tmpl.MultiMiddle[1], orig.MultiMiddle[1]},
+			{tmpl.Connector, orig.Connector},
+			{tmpl.Prefix, orig.Prefix},
+			{tmpl.ExternalTopLeft, orig.ExternalTopLeft},
+		}
+		for _, p := range pairs {
+			if p[0] != ascii.Transliterate(p[1]) || runewidth.StringWidth(p[0]) != runewidth.StringWidth(p[1]) {
+				t.Errorf("Template %s: got %q for %q", name, p[0], p[1])
+			}
+		}
+		if tmpl == orig {
+			t.Errorf("Template %s: builtin template should not be modified", name)
+		}
+	}
+	if builtinTemplates["box"].TopBorder != "═" {
+		t.Error("Builtin box template was modified")
+	}
+}
+
 func TestGetTemplateForStyle(t *testing.T) {
 	tests := []struct {
 		style    Style
@@ -337,6 +371,21 @@ func TestRenderWithTemplate(t *testing.T) {
 	}
 }
 
+func TestRenderWithTemplateAlign(t *testing.T) {
+	tmpl := *GetTemplate("say")
+	if err := json.Unmarshal([]byte(`{"align": "center"}`), &tmpl); err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+
+	lines := RenderWithTemplate("one two three four five", 9, &tmpl)
+	want := []string{"/  one two  \\", "|   three   |", "\\ four five /"}
+	for i, w := range want {
+		if lines[i+1] != w {
+			t.Errorf("Line %d = %q, want %q", i+1, lines[i+1], w)
+		}
+	}
+}
+
 func TestGenerateConnectorLines(t *testing.T) {
 	lines := GenerateConnectorLines(StyleSay, 2, 4, TailDown)
 
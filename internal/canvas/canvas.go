@@ -6,15 +6,29 @@ import (
 	"strings"
 	"unicode/utf8"
 
+	"github.com/MagikIO/familiar-says/internal/ansi"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mattn/go-runewidth"
 )
 
 // Cell represents a single character cell in the canvas with optional styling.
+// A cell holds a whole grapheme cluster, so accented letters and emoji
+// sequences stay together. Clusters wider than one column are followed by
+// continuation cells with an empty Grapheme.
 type Cell struct {
-	Rune        rune
+	Grapheme    string
 	Style       lipgloss.Style
-	Transparent bool // If true, overlay operations skip this cell
+	Transparent bool   // If true, overlay operations skip this cell
+	Link        string // URL the cell links to, if any
+}
+
+// Width returns the number of columns the cell's grapheme covers: 0 for
+// continuation cells and at least 1 otherwise.
+func (c Cell) Width() int {
+	if c.Grapheme == "" {
+		return 0
+	}
+	return max(runewidth.StringWidth(c.Grapheme), 1)
 }
 
 // Canvas is a 2D grid of cells that can be composed and rendered.
@@ -38,7 +52,7 @@ func NewCanvas(width, height int) *Canvas {
 		cells[y] = make([]Cell, width)
 		for x := range cells[y] {
 			cells[y][x] = Cell{
-				Rune:        ' ',
+				Grapheme:    " ",
 				Style:       lipgloss.NewStyle(),
 				Transparent: true,
 			}
@@ -59,7 +73,7 @@ func (c *Canvas) Set(x, y int, r rune, style lipgloss.Style) {
 		return
 	}
 	c.Cells[y][x] = Cell{
-		Rune:        r,
+		Grapheme:    string(r),
 		Style:       style,
 		Transparent: false,
 	}
@@ -68,27 +82,69 @@ func (c *Canvas) Set(x, y int, r rune, style lipgloss.Style) {
 // Get returns the cell at (x, y). Returns a transparent space if out of bounds.
 func (c *Canvas) Get(x, y int) Cell {
 	if x < 0 || x >= c.Width || y < 0 || y >= c.Height {
-		return Cell{Rune: ' ', Style: lipgloss.NewStyle(), Transparent: true}
+		return Cell{Grapheme: " ", Style: lipgloss.NewStyle(), Transparent: true}
 	}
 	return c.Cells[y][x]
 }
 
-// DrawString writes a string horizontally starting at (x, y).
-// Handles multi-byte runes correctly.
+// DrawString writes a string horizontally starting at (x, y), one grapheme
+// cluster per cell. ANSI SGR sequences in s are applied on top of style
+// instead of being drawn; other escape sequences are dropped.
 func (c *Canvas) DrawString(x, y int, s string, style lipgloss.Style) {
+	if !strings.ContainsRune(s, '\x1b') {
+		c.drawText(x, y, s, style, "")
+		return
+	}
+
 	col := x
-	for _, r := range s {
-		c.Set(col, y, r, style)
-		// Handle wide characters (CJK, emoji, etc.)
-		w := runewidth.RuneWidth(r)
-		if w > 1 {
-			// Fill extra cells with zero-width placeholder
-			for i := 1; i < w; i++ {
-				c.Set(col+i, y, 0, style) // 0 rune means "continuation of previous"
+	for _, seg := range ansi.Parse(s, style) {
+		col = c.drawText(col, y, seg.Text, seg.Style, seg.Link)
+	}
+}
+
+// drawText writes s starting at (x, y), linking it to link if set, and
+// returns the column after it.
+func (c *Canvas) drawText(x, y int, s string, style lipgloss.Style, link string) int {
+	col := x
+	for g, w := range ansi.Graphemes(s) {
+		if w == 0 {
+			// Zero-width clusters, such as a stray combining mark, join the
+			// cluster before them
+			if prev := c.clusterStart(col-1, y); prev >= x {
+				c.Cells[y][prev].Grapheme += g
 			}
+			continue
+		}
+		c.setCell(col, y, Cell{Grapheme: g, Style: style, Link: link})
+		// Wide clusters (CJK, emoji, etc.) continue into the next cells
+		for i := 1; i < w; i++ {
+			c.setCell(col+i, y, Cell{Style: style, Link: link})
 		}
 		col += w
 	}
+	return col
+}
+
+// clusterStart returns the column of the cell holding the cluster that
+// covers (x, y), or -1 if there is none.
+func (c *Canvas) clusterStart(x, y int) int {
+	if y < 0 || y >= c.Height || x >= c.Width {
+		return -1
+	}
+	for ; x >= 0; x-- {
+		if c.Cells[y][x].Grapheme != "" {
+			return x
+		}
+	}
+	return -1
+}
+
+// setCell places cell at (x, y), ignoring coordinates outside the canvas.
+func (c *Canvas) setCell(x, y int, cell Cell) {
+	if x < 0 || x >= c.Width || y < 0 || y >= c.Height {
+		return
+	}
+	c.Cells[y][x] = cell
 }
 
 // DrawLines writes multiple lines starting at (x, y).
@@ -108,7 +164,7 @@ func (c *Canvas) Overlay(other *Canvas, x, y int) {
 		for ox := 0; ox < other.Width; ox++ {
 			cell := other.Cells[oy][ox]
 			if !cell.Transparent {
-				c.Set(x+ox, y+oy, cell.Rune, cell.Style)
+				c.setCell(x+ox, y+oy, cell)
 			}
 		}
 	}
@@ -175,32 +231,79 @@ func Stack(top, bottom *Canvas, gap int) *Canvas {
 	return result
 }
 
-// Render converts the canvas to styled strings for terminal output.
+// Rect is a rectangle of cells on a canvas.
+type Rect struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// Empty reports whether the rectangle contains no cells.
+func (r Rect) Empty() bool {
+	return r.Width <= 0 || r.Height <= 0
+}
+
+// ContentBounds returns the smallest rectangle holding every visible cell,
+// that is every cell that is neither transparent nor a space. It is empty
+// if the canvas has no visible cells.
+func (c *Canvas) ContentBounds() Rect {
+	minX, minY, maxX, maxY := c.Width, c.Height, -1, -1
+	for y := 0; y < c.Height; y++ {
+		for x := 0; x < c.Width; x++ {
+			cell := c.Cells[y][x]
+			if cell.Transparent || cell.Grapheme == " " {
+				continue
+			}
+			minX, maxX = min(minX, x), max(maxX, x)
+			minY, maxY = min(minY, y), max(maxY, y)
+		}
+	}
+	if maxX < 0 {
+		return Rect{}
+	}
+	return Rect{X: minX, Y: minY, Width: maxX - minX + 1, Height: maxY - minY + 1}
+}
+
+// Render converts the canvas to styled strings for terminal output. Linked
+// cells are wrapped in OSC 8 hyperlinks if hyperlinks are enabled.
 func (c *Canvas) Render() []string {
 	lines := make([]string, c.Height)
+	hyperlinks := Hyperlinks()
 
 	for y := 0; y < c.Height; y++ {
 		var sb strings.Builder
+		link := ""
 		x := 0
 		for x < c.Width {
 			cell := c.Cells[y][x]
 
-			// Skip zero-width continuation markers
-			if cell.Rune == 0 {
+			// Skip continuation cells of wide clusters
+			if cell.Grapheme == "" {
 				x++
 				continue
 			}
 
-			// Render the rune with its style
-			styled := cell.Style.Render(string(cell.Rune))
+			// Open or close hyperlinks where the link changes
+			if hyperlinks && cell.Link != link {
+				if link != "" {
+					sb.WriteString(ansi.CloseHyperlink)
+				}
+				if cell.Link != "" {
+					sb.WriteString(ansi.OpenHyperlink(cell.Link))
+				}
+				link = cell.Link
+			}
+
+			// Render the cluster with its style
+			styled := cell.Style.Render(cell.Grapheme)
 			sb.WriteString(styled)
 
-			// Advance by the rune's display width
-			w := runewidth.RuneWidth(cell.Rune)
-			if w < 1 {
-				w = 1
-			}
-			x += w
+			// Advance by the cluster's display width
+			x += cell.Width()
+		}
+		if link != "" {
+			sb.WriteString(ansi.CloseHyperlink)
 		}
 		lines[y] = sb.String()
 	}
@@ -221,10 +324,7 @@ func (c *Canvas) RenderPlain() []string {
 		var sb strings.Builder
 		for x := 0; x < c.Width; x++ {
 			cell := c.Cells[y][x]
-			if cell.Rune == 0 {
-				continue // Skip continuation markers
-			}
-			sb.WriteRune(cell.Rune)
+			sb.WriteString(cell.Grapheme) // Continuation cells are empty
 		}
 		lines[y] = sb.String()
 	}
@@ -233,8 +333,9 @@ func (c *Canvas) RenderPlain() []string {
 }
 
 // Width calculates the display width of a string (handling multi-width runes).
+// ANSI escape sequences take up no width.
 func StringWidth(s string) int {
-	return runewidth.StringWidth(s)
+	return ansi.StringWidth(s)
 }
 
 // FromLines creates a canvas from a slice of strings.
@@ -262,33 +363,15 @@ func FromLines(lines []string, style lipgloss.Style) *Canvas {
 }
 
 // stripAnsi removes ANSI escape sequences from a string.
-// This is a simple implementation for trimming purposes.
 func stripAnsi(s string) string {
-	var result strings.Builder
-	inEscape := false
-
-	for _, r := range s {
-		if r == '\x1b' {
-			inEscape = true
-			continue
-		}
-		if inEscape {
-			if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
-				inEscape = false
-			}
-			continue
-		}
-		result.WriteRune(r)
-	}
-
-	return result.String()
+	return ansi.Strip(s)
 }
 
 // Height returns the visual height of the canvas content (excluding trailing empty rows).
 func (c *Canvas) ContentHeight() int {
 	for y := c.Height - 1; y >= 0; y-- {
 		for x := 0; x < c.Width; x++ {
-			if !c.Cells[y][x].Transparent && c.Cells[y][x].Rune != ' ' {
+			if !c.Cells[y][x].Transparent && c.Cells[y][x].Grapheme != " " {
 				return y + 1
 			}
 		}
@@ -301,7 +384,7 @@ func (c *Canvas) ContentWidth() int {
 	maxX := 0
 	for y := 0; y < c.Height; y++ {
 		for x := c.Width - 1; x >= 0; x-- {
-			if !c.Cells[y][x].Transparent && c.Cells[y][x].Rune != ' ' {
+			if !c.Cells[y][x].Transparent && c.Cells[y][x].Grapheme != " " {
 				if x+1 > maxX {
 					maxX = x + 1
 				}
@@ -324,7 +407,7 @@ func (c *Canvas) Clear() {
 	for y := 0; y < c.Height; y++ {
 		for x := 0; x < c.Width; x++ {
 			c.Cells[y][x] = Cell{
-				Rune:        ' ',
+				Grapheme:    " ",
 				Style:       lipgloss.NewStyle(),
 				Transparent: true,
 			}
@@ -337,7 +420,7 @@ func (c *Canvas) Fill(r rune, style lipgloss.Style) {
 	for y := 0; y < c.Height; y++ {
 		for x := 0; x < c.Width; x++ {
 			c.Cells[y][x] = Cell{
-				Rune:        r,
+				Grapheme:    string(r),
 				Style:       style,
 				Transparent: false,
 			}
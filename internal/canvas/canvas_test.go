@@ -37,7 +37,7 @@ func TestNewCanvas(t *testing.T) {
 			for y := 0; y < c.Height; y++ {
 				for x := 0; x < c.Width; x++ {
 					cell := c.Cells[y][x]
-					if cell.Rune != ' ' || !cell.Transparent {
+					if cell.Grapheme != " " || !cell.Transparent {
 						t.Errorf("Cell at (%d, %d) not properly initialized", x, y)
 					}
 				}
@@ -54,8 +54,8 @@ func TestSetGet(t *testing.T) {
 	// Test valid set
 	c.Set(5, 5, 'A', style)
 	cell := c.Get(5, 5)
-	if cell.Rune != 'A' {
-		t.Errorf("Got rune %c, want 'A'", cell.Rune)
+	if cell.Grapheme != "A" {
+		t.Errorf("Got %q, want \"A\"", cell.Grapheme)
 	}
 	if cell.Transparent {
 		t.Error("Cell should not be transparent after Set")
@@ -69,7 +69,7 @@ func TestSetGet(t *testing.T) {
 
 	// Test out of bounds Get
 	cell = c.Get(-1, 0)
-	if !cell.Transparent || cell.Rune != ' ' {
+	if !cell.Transparent || cell.Grapheme != " " {
 		t.Error("Out of bounds Get should return transparent space")
 	}
 }
@@ -81,10 +81,10 @@ func TestDrawString(t *testing.T) {
 
 	// Test ASCII string
 	c.DrawString(0, 0, "Hello", style)
-	if c.Get(0, 0).Rune != 'H' {
+	if c.Get(0, 0).Grapheme != "H" {
 		t.Error("First character not set correctly")
 	}
-	if c.Get(4, 0).Rune != 'o' {
+	if c.Get(4, 0).Grapheme != "o" {
 		t.Error("Last character not set correctly")
 	}
 
@@ -105,17 +105,17 @@ func TestDrawLines(t *testing.T) {
 	c.DrawLines(0, 0, lines, style)
 
 	// Check first line
-	if c.Get(0, 0).Rune != 'L' {
+	if c.Get(0, 0).Grapheme != "L" {
 		t.Error("First line not drawn correctly")
 	}
 
 	// Check second line
-	if c.Get(0, 1).Rune != 'L' {
+	if c.Get(0, 1).Grapheme != "L" {
 		t.Error("Second line not drawn correctly")
 	}
 
 	// Check third line
-	if c.Get(0, 2).Rune != 'L' {
+	if c.Get(0, 2).Grapheme != "L" {
 		t.Error("Third line not drawn correctly")
 	}
 
@@ -139,15 +139,15 @@ func TestOverlay(t *testing.T) {
 	base.Overlay(overlay, 2, 2)
 
 	// Check that overlay area has 'B'
-	if base.Get(2, 2).Rune != 'B' {
+	if base.Get(2, 2).Grapheme != "B" {
 		t.Error("Overlay not applied correctly")
 	}
-	if base.Get(6, 6).Rune != 'B' {
+	if base.Get(6, 6).Grapheme != "B" {
 		t.Error("Overlay boundary not correct")
 	}
 
 	// Check that area outside overlay still has 'A'
-	if base.Get(0, 0).Rune != 'A' {
+	if base.Get(0, 0).Grapheme != "A" {
 		t.Error("Base canvas modified outside overlay area")
 	}
 
@@ -169,13 +169,13 @@ func TestClone(t *testing.T) {
 	}
 
 	// Check cell content
-	if clone.Get(2, 2).Rune != 'X' {
+	if clone.Get(2, 2).Grapheme != "X" {
 		t.Error("Clone doesn't have same cell content")
 	}
 
 	// Modify clone and ensure original is unchanged
 	clone.Set(2, 2, 'Y', style)
-	if original.Get(2, 2).Rune != 'X' {
+	if original.Get(2, 2).Grapheme != "X" {
 		t.Error("Modifying clone affected original")
 	}
 }
@@ -198,12 +198,12 @@ func TestMerge(t *testing.T) {
 	}
 
 	// Check left side
-	if result.Get(0, 0).Rune != 'L' {
+	if result.Get(0, 0).Grapheme != "L" {
 		t.Error("Left canvas not merged correctly")
 	}
 
 	// Check right side
-	if result.Get(7, 0).Rune != 'R' {
+	if result.Get(7, 0).Grapheme != "R" {
 		t.Error("Right canvas not merged correctly")
 	}
 
@@ -242,12 +242,12 @@ func TestStack(t *testing.T) {
 	}
 
 	// Check top part
-	if result.Get(0, 0).Rune != 'T' {
+	if result.Get(0, 0).Grapheme != "T" {
 		t.Error("Top canvas not stacked correctly")
 	}
 
 	// Check bottom part
-	if result.Get(0, 4).Rune != 'B' {
+	if result.Get(0, 4).Grapheme != "B" {
 		t.Error("Bottom canvas not stacked correctly")
 	}
 
@@ -344,6 +344,25 @@ func TestContentHeightWidth(t *testing.T) {
 	}
 }
 
+// TestContentBounds tests the bounding box of visible cells
+func TestContentBounds(t *testing.T) {
+	c := NewCanvas(20, 10)
+	style := lipgloss.NewStyle()
+
+	if !c.ContentBounds().Empty() {
+		t.Errorf("Empty canvas bounds = %+v, want empty", c.ContentBounds())
+	}
+
+	c.DrawString(4, 2, "a b", style)
+	c.Set(7, 5, 'X', style)
+	c.Set(1, 8, ' ', style)
+
+	want := Rect{X: 4, Y: 2, Width: 4, Height: 4}
+	if got := c.ContentBounds(); got != want {
+		t.Errorf("ContentBounds() = %+v, want %+v", got, want)
+	}
+}
+
 // TestResize tests canvas resizing
 func TestResize(t *testing.T) {
 	c := NewCanvas(10, 10)
@@ -355,7 +374,7 @@ func TestResize(t *testing.T) {
 	if larger.Width != 20 || larger.Height != 20 {
 		t.Error("Resize to larger dimensions failed")
 	}
-	if larger.Get(5, 5).Rune != 'X' {
+	if larger.Get(5, 5).Grapheme != "X" {
 		t.Error("Content not preserved after resize")
 	}
 
@@ -378,7 +397,7 @@ func TestClear(t *testing.T) {
 	for y := 0; y < c.Height; y++ {
 		for x := 0; x < c.Width; x++ {
 			cell := c.Cells[y][x]
-			if cell.Rune != ' ' || !cell.Transparent {
+			if cell.Grapheme != " " || !cell.Transparent {
 				t.Errorf("Cell at (%d, %d) not cleared properly", x, y)
 			}
 		}
@@ -396,7 +415,7 @@ func TestFill(t *testing.T) {
 	for y := 0; y < c.Height; y++ {
 		for x := 0; x < c.Width; x++ {
 			cell := c.Cells[y][x]
-			if cell.Rune != '*' || cell.Transparent {
+			if cell.Grapheme != "*" || cell.Transparent {
 				t.Errorf("Cell at (%d, %d) not filled properly", x, y)
 			}
 		}
@@ -418,7 +437,7 @@ func TestApplyStyle(t *testing.T) {
 	for y := 0; y < c.Height; y++ {
 		for x := 0; x < c.Width; x++ {
 			cell := c.Cells[y][x]
-			if !cell.Transparent && cell.Rune != 'X' {
+			if !cell.Transparent && cell.Grapheme != "X" {
 				t.Errorf("Cell at (%d, %d) content changed unexpectedly", x, y)
 			}
 		}
@@ -433,7 +452,7 @@ func TestApplyStyle(t *testing.T) {
 	if c2.Get(2, 2).Transparent {
 		t.Error("Style application changed transparency incorrectly")
 	}
-	if c2.Get(2, 2).Rune != 'Y' {
+	if c2.Get(2, 2).Grapheme != "Y" {
 		t.Error("Style application changed cell content")
 	}
 }
@@ -468,6 +487,58 @@ func TestFromLines(t *testing.T) {
 	}
 }
 
+// TestFromLinesANSI tests that styled input becomes styled cells
+func TestFromLinesANSI(t *testing.T) {
+	base := lipgloss.NewStyle().Bold(true)
+	c := FromLines([]string{"\x1b[31mred\x1b[0m ok", "\x1b[38;5;45m日本\x1b[39m"}, base)
+
+	if c.Width != 6 {
+		t.Errorf("Width = %d, want 6", c.Width)
+	}
+	if got := c.RenderPlain(); got[0] != "red ok" || got[1] != "日本  " {
+		t.Errorf("RenderPlain() = %q", got)
+	}
+
+	red := c.Get(0, 0).Style
+	if red.GetForeground() != lipgloss.Color("1") || !red.GetBold() {
+		t.Errorf("Expected bold red cell, got foreground %v bold %v", red.GetForeground(), red.GetBold())
+	}
+	if plain := c.Get(4, 0).Style; plain.GetForeground() != (lipgloss.NoColor{}) || !plain.GetBold() {
+		t.Error("Cells after a reset should use the base style")
+	}
+	if c.Get(1, 1).Grapheme != "" || c.Get(2, 1).Style.GetForeground() != lipgloss.Color("45") {
+		t.Error("Wide runes should keep their continuation cell and style")
+	}
+}
+
+// TestDrawStringGraphemes tests that each grapheme cluster takes one cell
+func TestDrawStringGraphemes(t *testing.T) {
+	c := NewCanvas(10, 1)
+	c.DrawString(0, 0, "e\u0301\U0001f44d\U0001f3fd\U0001f469\u200d\U0001f4bbx", lipgloss.NewStyle())
+
+	want := []string{"e\u0301", "\U0001f44d\U0001f3fd", "", "\U0001f469\u200d\U0001f4bb", "", "x", " "}
+	for x, g := range want {
+		if got := c.Get(x, 0).Grapheme; got != g {
+			t.Errorf("Cell %d = %q, want %q", x, got, g)
+		}
+	}
+	if got := c.Get(1, 0).Width(); got != 2 {
+		t.Errorf("Width of an emoji with a skin tone = %d, want 2", got)
+	}
+
+	// A stray combining mark joins the cluster before it
+	c.DrawString(5, 0, "o", lipgloss.NewStyle())
+	c.DrawString(5, 0, "o\u0308", lipgloss.NewStyle())
+	if got := c.Get(5, 0).Grapheme; got != "o\u0308" {
+		t.Errorf("Cell 5 = %q, want o with diaeresis", got)
+	}
+
+	line := c.RenderPlain()[0]
+	if got := StringWidth(line); got != c.Width {
+		t.Errorf("Rendered line %q is %d wide, want %d", line, got, c.Width)
+	}
+}
+
 // TestStringWidth tests width calculation
 func TestStringWidth(t *testing.T) {
 	tests := []struct {
@@ -478,6 +549,9 @@ func TestStringWidth(t *testing.T) {
 		{"ASCII", "Hello", 5},
 		{"empty", "", 0},
 		{"spaces", "   ", 3},
+		{"ANSI colored", "\x1b[31mHello\x1b[0m", 5},
+		{"combining marks", "\u2022\u0301\ufe35\u2022\u0300", 4},
+		{"zwj emoji", "\U0001f469\u200d\U0001f4bb", 2},
 	}
 
 	for _, tt := range tests {
@@ -3,6 +3,7 @@ package canvas
 import (
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -41,6 +42,13 @@ type AnimationSequence struct {
 	Loop   bool             `json:"loop"`
 }
 
+// Expression overrides the eyes and tongue shown for a mood.
+// An empty field keeps the value from the theme.
+type Expression struct {
+	Eyes   string `json:"eyes,omitempty"`
+	Tongue string `json:"tongue,omitempty"`
+}
+
 // Character represents a familiar/animal character with ASCII art and expression slots.
 type Character struct {
 	Name             string                        `json:"name"`
@@ -50,16 +58,35 @@ type Character struct {
 	Eyes             *Slot                         `json:"eyes,omitempty"`            // Where to insert eyes (nil if no eyes)
 	Mouth            *Slot                         `json:"mouth,omitempty"`           // Where to insert mouth/tongue (nil if none)
 	Colors           *CharacterColors              `json:"colors,omitempty"`          // Default colors for character parts
+	Expressions      map[string]Expression         `json:"expressions,omitempty"`     // Per-mood expressions, overriding the theme
 	Animations       map[string]*AnimationSequence `json:"animations,omitempty"`      // Named animation sequences
 	DefaultAnimation string                        `json:"defaultAnimation,omitempty"` // Default animation to play (e.g., "idle")
 }
 
-// LoadCharacter loads a character from a JSON file.
+// LoadCharacter loads a character from a JSON file or a classic cowsay
+// .cow file, chosen by the file extension.
 func LoadCharacter(filename string) (*Character, error) {
 	data, err := os.ReadFile(filename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read character file %q: %w", filename, err)
 	}
+	return parseCharacter(data, filename)
+}
+
+// LoadCharacterFS loads a character from a JSON or .cow file in the given filesystem.
+func LoadCharacterFS(fsys fs.FS, filename string) (*Character, error) {
+	data, err := fs.ReadFile(fsys, filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read character file %q: %w", filename, err)
+	}
+	return parseCharacter(data, filename)
+}
+
+// parseCharacter decodes and validates character data read from filename.
+func parseCharacter(data []byte, filename string) (*Character, error) {
+	if strings.EqualFold(filepath.Ext(filename), ".cow") {
+		return ParseCow(data, strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename)))
+	}
 
 	var char Character
 	if err := json.Unmarshal(data, &char); err != nil {
@@ -95,20 +122,6 @@ func (ch *Character) ToCanvasStyled(eyes, mouth string, styles CharacterStyles)
 		return NewCanvas(1, 1)
 	}
 
-	// Calculate dimensions
-	maxWidth := 0
-	for _, line := range ch.Art {
-		w := StringWidth(line)
-		if w > maxWidth {
-			maxWidth = w
-		}
-	}
-	if maxWidth == 0 {
-		maxWidth = 1
-	}
-
-	canvas := NewCanvas(maxWidth, len(ch.Art))
-
 	// Track which cells are eyes or mouth for special styling
 	eyeCells := make(map[int]map[int]bool)  // line -> col -> isEye
 	mouthCells := make(map[int]map[int]bool) // line -> col -> isMouth
@@ -126,7 +139,7 @@ func (ch *Character) ToCanvasStyled(eyes, mouth string, styles CharacterStyles)
 		// Mark eye cells for special styling
 		eyeCells[ch.Eyes.Line] = make(map[int]bool)
 		// Find where the placeholder was and mark new content positions
-		placeholderIdx := strings.Index(origLine, ch.Eyes.Placeholder)
+		placeholderIdx := placeholderColumn(origLine, ch.Eyes.Placeholder)
 		if placeholderIdx >= 0 {
 			// Calculate centered position
 			valueWidth := StringWidth(eyes)
@@ -152,7 +165,7 @@ func (ch *Character) ToCanvasStyled(eyes, mouth string, styles CharacterStyles)
 
 		// Mark mouth cells for special styling
 		mouthCells[ch.Mouth.Line] = make(map[int]bool)
-		placeholderIdx := strings.Index(origLine, ch.Mouth.Placeholder)
+		placeholderIdx := placeholderColumn(origLine, ch.Mouth.Placeholder)
 		if placeholderIdx >= 0 {
 			valueWidth := StringWidth(mouth)
 			slotWidth := ch.Mouth.Width
@@ -166,28 +179,45 @@ func (ch *Character) ToCanvasStyled(eyes, mouth string, styles CharacterStyles)
 		}
 	}
 
+	// Calculate dimensions, including expressions wider than their slots
+	maxWidth := 0
+	for _, line := range lines {
+		w := StringWidth(line)
+		if w > maxWidth {
+			maxWidth = w
+		}
+	}
+	if maxWidth == 0 {
+		maxWidth = 1
+	}
+
+	canvas := NewCanvas(maxWidth, len(lines))
+
 	// Draw each line with appropriate styling
 	for y, line := range lines {
-		col := 0
-		for _, r := range line {
-			// Determine which style to use for this cell
-			var cellStyle lipgloss.Style
+		canvas.DrawString(0, y, line, styles.Outline)
+		for col := range canvas.Cells[y] {
 			if eyeMap, ok := eyeCells[y]; ok && eyeMap[col] {
-				cellStyle = styles.Eyes
+				canvas.Cells[y][col].Style = styles.Eyes
 			} else if mouthMap, ok := mouthCells[y]; ok && mouthMap[col] {
-				cellStyle = styles.Mouth
-			} else {
-				cellStyle = styles.Outline
+				canvas.Cells[y][col].Style = styles.Mouth
 			}
-
-			canvas.Set(col, y, r, cellStyle)
-			col++
 		}
 	}
 
 	return canvas
 }
 
+// placeholderColumn returns the display column of the first placeholder in
+// line, or -1.
+func placeholderColumn(line, placeholder string) int {
+	idx := strings.Index(line, placeholder)
+	if idx < 0 {
+		return -1
+	}
+	return StringWidth(line[:idx])
+}
+
 // replaceSlot replaces a placeholder in a line with the given value, centered within the slot width.
 func replaceSlot(line string, slot *Slot, value string) string {
 	if slot.Placeholder == "" {
@@ -262,6 +292,12 @@ func (ch *Character) Clone() *Character {
 		colors := *ch.Colors
 		clone.Colors = &colors
 	}
+	if ch.Expressions != nil {
+		clone.Expressions = make(map[string]Expression, len(ch.Expressions))
+		for mood, expr := range ch.Expressions {
+			clone.Expressions[mood] = expr
+		}
+	}
 	if ch.Animations != nil {
 		clone.Animations = make(map[string]*AnimationSequence, len(ch.Animations))
 		for name, anim := range ch.Animations {
@@ -177,6 +177,33 @@ func TestToCanvasStyled(t *testing.T) {
 	}
 }
 
+// TestToCanvasStyledGraphemes tests that expressions with combining marks
+// and wide runes line up with the art
+func TestToCanvasStyledGraphemes(t *testing.T) {
+	char := testCatCharacter()
+	char.Art[1] = ` ( @@ ) |`
+	eyes := "\u2022\u0301\ufe35\u2022\u0300" // Sad eyes of the rainbow theme
+
+	eyeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00"))
+	canvas := char.ToCanvasStyled(eyes, "w", CharacterStyles{Eyes: eyeStyle})
+
+	line := canvas.RenderPlain()[1]
+	if want := " ( " + eyes + " ) |"; line != want {
+		t.Errorf("Eye line = %q, want %q", line, want)
+	}
+	if got := StringWidth(line); got != canvas.Width {
+		t.Errorf("Eye line is %d wide, want %d", got, canvas.Width)
+	}
+	for x := 3; x < 7; x++ {
+		if canvas.Get(x, 1).Style.GetForeground() != eyeStyle.GetForeground() {
+			t.Errorf("Cell %d of the eyes is not styled as eyes", x)
+		}
+	}
+	if canvas.Get(7, 1).Style.GetForeground() == eyeStyle.GetForeground() {
+		t.Error("Cell after the eyes is styled as eyes")
+	}
+}
+
 // TestToCanvas tests the deprecated method
 func TestToCanvas(t *testing.T) {
 	char := testCatCharacter()
@@ -3,7 +3,10 @@ package canvas
 import (
 	"strings"
 
+	"github.com/MagikIO/familiar-says/internal/ansi"
 	"github.com/MagikIO/familiar-says/internal/bubble"
+	"github.com/MagikIO/familiar-says/internal/markdown"
+	"github.com/MagikIO/familiar-says/internal/textwrap"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -43,11 +46,18 @@ type CompositorConfig struct {
 	BubbleStyle    BubbleStyle
 	Layout         Layout
 	BubbleColor    lipgloss.Style
-	CharColor      lipgloss.Style    // Fallback color for character (deprecated in favor of CharColors)
-	CharColors     *CharacterColors  // Per-part colors for character (outline, eyes, mouth)
-	ConnectorLen   int               // Number of connector lines (default 2)
-	TailDirection  TailDirection     // Direction the bubble tail points (default down)
-	CustomTemplate string            // Custom template name or path (overrides BubbleStyle if set)
+	CharColor      lipgloss.Style     // Fallback color for character (deprecated in favor of CharColors)
+	CharColors     *CharacterColors   // Per-part colors for character (outline, eyes, mouth)
+	ConnectorLen   int                // Number of connector lines (default 2)
+	TailDirection  TailDirection      // Direction the bubble tail points (default down)
+	CustomTemplate string             // Custom template name or path (overrides BubbleStyle if set)
+	CodeLanguage   string             // Language for code bubbles (detected from the code if empty)
+	CodeStyle      string             // Chroma syntax highlighting style for code bubbles
+	Align          textwrap.Alignment // Text alignment in the bubble (the template's if empty)
+	Markdown       bool               // Render inline Markdown in the text (also set by templates)
+	MaxLines       int                // Lines of text in the bubble before it overflows (0 = no limit)
+	Overflow       string             // What happens to text past MaxLines (default truncate)
+	Page           int                // Page of the text shown with OverflowPage
 }
 
 // DefaultConfig returns a default compositor configuration.
@@ -62,8 +72,41 @@ func DefaultConfig() CompositorConfig {
 	}
 }
 
+// SceneLayout locates the visible parts of a composed scene on its canvas.
+// Parts that are absent or blank have empty rectangles.
+type SceneLayout struct {
+	Bubble    Rect
+	Connector Rect
+	Character Rect
+}
+
 // Compose combines a speech bubble and character into a single canvas.
 func Compose(text string, char *Character, eyes, mouth string, config CompositorConfig) *Canvas {
+	result, _ := ComposeLayout(text, char, eyes, mouth, config)
+	return result
+}
+
+// ComposeLayout is like Compose, but also returns where the bubble,
+// connector and character were placed.
+func ComposeLayout(text string, char *Character, eyes, mouth string, config CompositorConfig) (*Canvas, SceneLayout) {
+	// Resolve character styles, merging the character's default colors
+	// with config overrides, and render it with expressions filled in
+	charStyles := ResolveCharacterStyles(MergeColors(char.Colors, config.CharColors), config.CharColor)
+	charCanvas := char.ToCanvasStyled(eyes, mouth, charStyles)
+
+	return ComposeFrameLayout(text, char, charCanvas, config)
+}
+
+// ComposeFrame combines a speech bubble with an already rendered character
+// canvas, such as an animation frame. char supplies the connector anchor.
+func ComposeFrame(text string, char *Character, charCanvas *Canvas, config CompositorConfig) *Canvas {
+	result, _ := ComposeFrameLayout(text, char, charCanvas, config)
+	return result
+}
+
+// ComposeFrameLayout is like ComposeFrame, but also returns where the
+// bubble, connector and character were placed.
+func ComposeFrameLayout(text string, char *Character, charCanvas *Canvas, config CompositorConfig) (*Canvas, SceneLayout) {
 	if config.BubbleWidth <= 0 {
 		config.BubbleWidth = 40
 	}
@@ -72,21 +115,28 @@ func Compose(text string, char *Character, eyes, mouth string, config Compositor
 	}
 
 	// 1. Get the template (custom or based on style)
-	var tmpl *bubble.BubbleTemplate
-	if config.CustomTemplate != "" {
-		var err error
-		tmpl, err = bubble.GetOrLoadTemplate(config.CustomTemplate)
-		if err != nil {
-			// Fall back to style-based template on error
-			tmpl = GetTemplateForBubbleStyle(config.BubbleStyle)
-		}
-	} else {
-		tmpl = GetTemplateForBubbleStyle(config.BubbleStyle)
-	}
+	tmpl := bubbleTemplate(config)
 
-	// 2. Render the speech bubble
-	bubbleLines := renderBubbleWithTemplate(text, config.BubbleWidth, tmpl)
-	bubbleCanvas := FromLines(bubbleLines, config.BubbleColor)
+	// 2. Render the speech bubble, keeping code blocks as written
+	bubbleCanvas := renderSceneBubble(text, config, tmpl)
+
+	return composeBubbleLayout(bubbleCanvas, char, charCanvas, config, tmpl)
+}
+
+// ComposeBubbleFrame combines a bubble rendered by RenderSceneBubble with an
+// already rendered character canvas, as ComposeFrame does. Animations use it
+// to redraw frames without rendering the bubble again.
+func ComposeBubbleFrame(bubbleCanvas *Canvas, char *Character, charCanvas *Canvas, config CompositorConfig) *Canvas {
+	result, _ := composeBubbleLayout(bubbleCanvas, char, charCanvas, config, bubbleTemplate(config))
+	return result
+}
+
+// composeBubbleLayout adds the connector of tmpl and the character to a
+// rendered bubble, placed for config.TailDirection.
+func composeBubbleLayout(bubbleCanvas *Canvas, char *Character, charCanvas *Canvas, config CompositorConfig, tmpl *bubble.BubbleTemplate) (*Canvas, SceneLayout) {
+	if config.ConnectorLen <= 0 {
+		config.ConnectorLen = 2
+	}
 
 	// 3. Generate the connector using template-based character
 	connectorChar := tmpl.Connector
@@ -103,22 +153,69 @@ func Compose(text string, char *Character, eyes, mouth string, config Compositor
 		config.CharColor,
 	)
 
-	// 4. Resolve character styles
-	// Merge character's default colors with config overrides
-	mergedColors := MergeColors(char.Colors, config.CharColors)
-	charStyles := ResolveCharacterStyles(mergedColors, config.CharColor)
+	// 4. Compose based on layout and tail direction
+	return composeWithDirection(bubbleCanvas, connectorCanvas, charCanvas, config)
+}
 
-	// 5. Render the character with expressions and per-part styling
-	charCanvas := char.ToCanvasStyled(eyes, mouth, charStyles)
+// bubbleTemplate returns the template of the bubble for config: the custom
+// template if one is set and can be loaded, and the template of the bubble
+// style otherwise. config.Align overrides the template's alignment, and
+// config.Markdown turns on Markdown.
+func bubbleTemplate(config CompositorConfig) *bubble.BubbleTemplate {
+	var tmpl *bubble.BubbleTemplate
+	if config.CustomTemplate != "" {
+		var err error
+		tmpl, err = bubble.GetOrLoadTemplate(config.CustomTemplate)
+		if err != nil {
+			// Fall back to style-based template on error
+			tmpl = GetTemplateForBubbleStyle(config.BubbleStyle)
+		}
+	} else {
+		tmpl = GetTemplateForBubbleStyle(config.BubbleStyle)
+	}
 
-	// 6. Compose based on layout and tail direction
-	return composeWithDirection(bubbleCanvas, connectorCanvas, charCanvas, config)
+	if config.Align != "" || config.Markdown {
+		override := *tmpl
+		if config.Align != "" {
+			override.Align = config.Align
+		}
+		override.Markdown = override.Markdown || config.Markdown
+		tmpl = &override
+	}
+	return tmpl
+}
+
+// RenderSceneBubble renders the speech bubble that Compose draws for config.
+func RenderSceneBubble(text string, config CompositorConfig) *Canvas {
+	if config.BubbleWidth <= 0 {
+		config.BubbleWidth = 40
+	}
+	return renderSceneBubble(text, config, bubbleTemplate(config))
+}
+
+// renderSceneBubble renders a speech bubble with tmpl, keeping code blocks as
+// written. Text longer than config.MaxLines overflows as config.Overflow
+// says.
+func renderSceneBubble(text string, config CompositorConfig, tmpl *bubble.BubbleTemplate) *Canvas {
+	if tmpl.IsCodeBlock {
+		return stackBubbles(renderCodePages(text, config, tmpl))
+	}
+
+	pages := overflowPages(bubbleContent(text, config.BubbleWidth, tmpl), config)
+	bubbles := make([]*Canvas, len(pages))
+	for i, page := range pages {
+		bubbles[i] = FromLines(frameBubble(page, tmpl), config.BubbleColor)
+	}
+	return stackBubbles(bubbles)
 }
 
 // RenderBubble creates a speech bubble canvas using the template system.
 func RenderBubble(text string, width int, style BubbleStyle, color lipgloss.Style) *Canvas {
 	// Get the template for this style
 	tmpl := GetTemplateForBubbleStyle(style)
+	if tmpl.IsCodeBlock {
+		return RenderCodeBubble(text, width, bubble.DefaultHighlightConfig(), tmpl, color)
+	}
 	
 	// Use the template-based rendering
 	bubbleLines := renderBubbleWithTemplate(text, width, tmpl)
@@ -126,6 +223,41 @@ func RenderBubble(text string, width int, style BubbleStyle, color lipgloss.Styl
 	return FromLines(bubbleLines, color)
 }
 
+// RenderCodeBubble creates a code bubble canvas that keeps the code's lines
+// and indentation, with syntax highlighting and a language header. Code
+// colors are drawn over color, which styles the border.
+func RenderCodeBubble(code string, width int, highlight bubble.HighlightConfig, tmpl *bubble.BubbleTemplate, color lipgloss.Style) *Canvas {
+	if highlight.Style == "" {
+		highlight.Style = bubble.DefaultHighlightConfig().Style
+	}
+	return FromSpans(bubble.CodeBubbleSpans(code, "", width, highlight, tmpl), color)
+}
+
+// FromSpans creates a canvas from lines of styled spans. Each span's style
+// inherits any properties it leaves unset from base.
+func FromSpans(lines [][]bubble.Span, base lipgloss.Style) *Canvas {
+	width := 0
+	for _, line := range lines {
+		w := 0
+		for _, span := range line {
+			w += StringWidth(span.Text)
+		}
+		if w > width {
+			width = w
+		}
+	}
+
+	c := NewCanvas(width, len(lines))
+	for y, line := range lines {
+		x := 0
+		for _, span := range line {
+			c.DrawString(x, y, span.Text, span.Style.Inherit(base))
+			x += StringWidth(span.Text)
+		}
+	}
+	return c
+}
+
 // RenderBubbleWithTemplateName renders a bubble using a template by name.
 func RenderBubbleWithTemplateName(text string, width int, templateName string, color lipgloss.Style) *Canvas {
 	tmpl := bubble.GetTemplate(templateName)
@@ -135,16 +267,32 @@ func RenderBubbleWithTemplateName(text string, width int, templateName string, c
 
 // renderBubbleWithTemplate renders bubble lines using a template.
 func renderBubbleWithTemplate(text string, width int, tmpl *bubble.BubbleTemplate) []string {
+	return frameBubble(bubbleContent(text, width, tmpl), tmpl)
+}
+
+// bubbleContent wraps the text of a bubble into the lines inside its
+// borders.
+func bubbleContent(text string, width int, tmpl *bubble.BubbleTemplate) []string {
+	if tmpl.Markdown {
+		text = markdown.Render(text)
+	}
+	text = Linkify(text)
+
 	// Apply prefix/suffix decorators if present
 	if tmpl.Prefix != "" || tmpl.Suffix != "" {
 		text = tmpl.Prefix + text + tmpl.Suffix
 	}
 
-	lines := wrapText(text, width)
+	// Styled text keeps its colors on every wrapped line
+	lines := ansi.CarryStyles(wrapText(text, width, tmpl.Align, tmpl.Markdown))
 	if len(lines) == 0 {
 		lines = []string{""}
 	}
+	return lines
+}
 
+// frameBubble draws the borders of tmpl around lines of bubble text.
+func frameBubble(lines []string, tmpl *bubble.BubbleTemplate) []string {
 	// Calculate max line length
 	maxLen := 0
 	for _, line := range lines {
@@ -330,30 +478,62 @@ func generateConnectorRight(char string, length int, anchorX int, style lipgloss
 	return FromLines([]string{line}, style)
 }
 
-// composeWithDirection arranges bubble, connector, and character based on tail direction.
-func composeWithDirection(bubbleCanvas, connectorCanvas, charCanvas *Canvas, config CompositorConfig) *Canvas {
+// composeWithDirection arranges bubble, connector, and character based on
+// tail direction, returning where each was placed.
+func composeWithDirection(bubbleCanvas, connectorCanvas, charCanvas *Canvas, config CompositorConfig) (*Canvas, SceneLayout) {
+	// place returns the visible part of c when drawn at (x, y)
+	place := func(c *Canvas, x, y int) Rect {
+		r := c.ContentBounds()
+		if r.Empty() {
+			return r
+		}
+		r.X += x
+		r.Y += y
+		return r
+	}
+
 	switch config.TailDirection {
 	case TailUp:
 		// Character above bubble (inverted)
 		result := Stack(charCanvas, connectorCanvas, 0)
 		result = Stack(result, bubbleCanvas, 0)
-		return result
+		return result, SceneLayout{
+			Character: place(charCanvas, 0, 0),
+			Connector: place(connectorCanvas, 0, charCanvas.Height),
+			Bubble:    place(bubbleCanvas, 0, charCanvas.Height+connectorCanvas.Height),
+		}
 	case TailLeft:
 		// Bubble to the right of character
-		return Merge(charCanvas, Merge(connectorCanvas, bubbleCanvas, 1), 2)
+		x := charCanvas.Width + 2
+		return Merge(charCanvas, Merge(connectorCanvas, bubbleCanvas, 1), 2), SceneLayout{
+			Character: place(charCanvas, 0, 0),
+			Connector: place(connectorCanvas, x, 0),
+			Bubble:    place(bubbleCanvas, x+connectorCanvas.Width+1, 0),
+		}
 	case TailRight:
 		// Bubble to the left of character
-		return Merge(bubbleCanvas, Merge(connectorCanvas, charCanvas, 1), 2)
+		x := bubbleCanvas.Width + 2
+		return Merge(bubbleCanvas, Merge(connectorCanvas, charCanvas, 1), 2), SceneLayout{
+			Bubble:    place(bubbleCanvas, 0, 0),
+			Connector: place(connectorCanvas, x, 0),
+			Character: place(charCanvas, x+connectorCanvas.Width+1, 0),
+		}
 	default:
 		// Default: bubble above character (TailDown)
+		layout := SceneLayout{
+			Bubble:    place(bubbleCanvas, 0, 0),
+			Connector: place(connectorCanvas, 0, bubbleCanvas.Height),
+		}
 		switch config.Layout {
 		case LayoutHorizontal:
 			combined := Stack(bubbleCanvas, connectorCanvas, 0)
-			return Merge(combined, charCanvas, 2)
+			layout.Character = place(charCanvas, combined.Width+2, 0)
+			return Merge(combined, charCanvas, 2), layout
 		default:
 			result := Stack(bubbleCanvas, connectorCanvas, 0)
+			layout.Character = place(charCanvas, 0, result.Height)
 			result = Stack(result, charCanvas, 0)
-			return result
+			return result, layout
 		}
 	}
 }
@@ -378,59 +558,14 @@ func replaceThinkChars(s string) string {
 	return string(result)
 }
 
-// wrapText wraps text to fit within the given width.
-func wrapText(text string, width int) []string {
-	if text == "" {
-		return []string{}
-	}
-
-	words := splitWords(text)
-	if len(words) == 0 {
-		return []string{}
-	}
-
-	lines := []string{}
-	current := ""
-
-	for _, word := range words {
-		if current == "" {
-			current = word
-		} else if StringWidth(current+" "+word) <= width {
-			current += " " + word
-		} else {
-			lines = append(lines, current)
-			current = word
-		}
-	}
-
-	if current != "" {
-		lines = append(lines, current)
-	}
-
-	return lines
-}
-
-// splitWords splits text into words, preserving whitespace behavior.
-func splitWords(text string) []string {
-	words := []string{}
-	current := ""
-
-	for _, r := range text {
-		if r == ' ' || r == '\t' || r == '\n' {
-			if current != "" {
-				words = append(words, current)
-				current = ""
-			}
-		} else {
-			current += string(r)
-		}
+// wrapText wraps text to fit within the given width, keeping its line
+// breaks, and aligns the lines. Markdown list items get a hanging indent if
+// lists is set.
+func wrapText(text string, width int, align textwrap.Alignment, lists bool) []string {
+	if lists {
+		return textwrap.WrapLists(text, width, align)
 	}
-
-	if current != "" {
-		words = append(words, current)
-	}
-
-	return words
+	return textwrap.WrapAligned(text, width, align)
 }
 
 // padRight pads a string with spaces to reach the target width.
@@ -454,29 +589,84 @@ func repeat(s string, n int) string {
 	return result
 }
 
+// PanelGap is the number of columns between panels in multi-panel mode.
+const PanelGap = 3
+
 // ComposeMultiPanel renders multiple characters with their messages side by side.
+// Panels are aligned at the bottom so the characters share a baseline. Each
+// panel uses its own bubble style and color overrides on top of config.
 func ComposeMultiPanel(panels []PanelConfig, config CompositorConfig) *Canvas {
+	result, _ := ComposeMultiPanelLayout(panels, config)
+	return result
+}
+
+// ComposeMultiPanelLayout is like ComposeMultiPanel, but also returns the
+// layout of each panel on the combined canvas.
+func ComposeMultiPanelLayout(panels []PanelConfig, config CompositorConfig) (*Canvas, []SceneLayout) {
 	if len(panels) == 0 {
-		return NewCanvas(1, 1)
+		return NewCanvas(1, 1), nil
 	}
 
-	var result *Canvas
+	canvases := make([]*Canvas, len(panels))
+	layouts := make([]SceneLayout, len(panels))
+	width, height := 0, 0
 	for i, panel := range panels {
-		panelCanvas := Compose(panel.Text, panel.Character, panel.Eyes, panel.Mouth, config)
-		if i == 0 {
-			result = panelCanvas
+		panelConfig := config
+		panelConfig.BubbleStyle = panel.BubbleStyle
+		panelConfig.CharColors = MergeColors(config.CharColors, panel.CharColors)
+
+		charCanvas := panel.Frame
+		if charCanvas == nil {
+			charStyles := ResolveCharacterStyles(MergeColors(panel.Character.Colors, panelConfig.CharColors), config.CharColor)
+			charCanvas = panel.Character.ToCanvasStyled(panel.Eyes, panel.Mouth, charStyles)
+		}
+
+		if panel.NoBubble {
+			canvases[i] = charCanvas
+			layouts[i] = SceneLayout{Character: charCanvas.ContentBounds()}
 		} else {
-			result = Merge(result, panelCanvas, 3)
+			canvases[i], layouts[i] = ComposeFrameLayout(panel.Text, panel.Character, charCanvas, panelConfig)
+		}
+		if i > 0 {
+			width += PanelGap
+		}
+		width += canvases[i].Width
+		if canvases[i].Height > height {
+			height = canvases[i].Height
 		}
 	}
 
-	return result
+	result := NewCanvas(width, height)
+	x := 0
+	for i, c := range canvases {
+		y := height - c.Height
+		result.Overlay(c, x, y)
+		layouts[i] = layouts[i].offset(x, y)
+		x += c.Width + PanelGap
+	}
+
+	return result, layouts
+}
+
+// offset returns the layout moved right by dx and down by dy.
+func (l SceneLayout) offset(dx, dy int) SceneLayout {
+	for _, r := range []*Rect{&l.Bubble, &l.Connector, &l.Character} {
+		if !r.Empty() {
+			r.X += dx
+			r.Y += dy
+		}
+	}
+	return l
 }
 
 // PanelConfig holds configuration for a single panel in multi-panel mode.
 type PanelConfig struct {
-	Text      string
-	Character *Character
-	Eyes      string
-	Mouth     string
+	Text        string
+	Character   *Character
+	Eyes        string
+	Mouth       string
+	BubbleStyle BubbleStyle      // Bubble style for this panel
+	CharColors  *CharacterColors // Optional per-part color overrides for this panel
+	Frame       *Canvas          // Optional pre-rendered character, e.g. an animation frame
+	NoBubble    bool             // Show only the character, without a bubble
 }
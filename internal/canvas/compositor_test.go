@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/MagikIO/familiar-says/internal/textwrap"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -443,22 +444,21 @@ func TestWrapText(t *testing.T) {
 		{"exact fit", "12345", 5, 1},
 		{"needs wrap", "Hello World Test", 8, 2},
 		{"empty", "", 40, 0},
-		{"single word long", "Supercalifragilisticexpialidocious", 10, 1},
+		{"single word long", "Supercalifragilisticexpialidocious", 10, 4},
+		{"paragraphs", "First paragraph.\n\nSecond paragraph.", 40, 3},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			lines := wrapText(tt.text, tt.width)
+			lines := wrapText(tt.text, tt.width, textwrap.AlignLeft, false)
 
 			if len(lines) < tt.minLines {
 				t.Errorf("Got %d lines, want at least %d", len(lines), tt.minLines)
 			}
 
-			// Check that no line exceeds width (unless it's a single long word)
+			// Check that no line exceeds width
 			for _, line := range lines {
-				lineWidth := StringWidth(line)
-				words := splitWords(line)
-				if lineWidth > tt.width && len(words) > 1 {
+				if lineWidth := StringWidth(line); lineWidth > tt.width {
 					t.Errorf("Line exceeds width: %d > %d: %q", lineWidth, tt.width, line)
 				}
 			}
@@ -466,32 +466,6 @@ func TestWrapText(t *testing.T) {
 	}
 }
 
-// TestSplitWords tests word splitting
-func TestSplitWords(t *testing.T) {
-	tests := []struct {
-		name  string
-		input string
-		want  int
-	}{
-		{"simple", "hello world", 2},
-		{"extra spaces", "hello  world", 2},
-		{"tabs", "hello\tworld", 2},
-		{"newlines", "hello\nworld", 2},
-		{"single word", "hello", 1},
-		{"empty", "", 0},
-		{"only spaces", "   ", 0},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			words := splitWords(tt.input)
-			if len(words) != tt.want {
-				t.Errorf("Got %d words, want %d from %q", len(words), tt.want, tt.input)
-			}
-		})
-	}
-}
-
 // TestPadRight tests right padding
 func TestPadRight(t *testing.T) {
 	tests := []struct {
@@ -667,6 +641,152 @@ func TestComposeMultiPanel(t *testing.T) {
 	})
 }
 
+// TestComposeMultiPanelAlignment tests bottom alignment and per-panel styles
+func TestComposeMultiPanelAlignment(t *testing.T) {
+	cat, _ := GetBuiltinCharacter("cat")
+	owl, _ := GetBuiltinCharacter("owl")
+
+	panels := []PanelConfig{
+		{Text: "Tall", Character: cat, Eyes: "^^", Mouth: "w"},
+		{Text: "Short", Character: owl, Eyes: "oo", BubbleStyle: BubbleStyleThink},
+	}
+	lines := ComposeMultiPanel(panels, DefaultConfig()).RenderPlain()
+
+	// Both characters stand on the last line
+	last := lines[len(lines)-1]
+	if !strings.Contains(last, "\\_)") || !strings.Contains(last, `""`) {
+		t.Errorf("Expected both characters to end on the last line, got %q", last)
+	}
+
+	// The shorter owl panel starts lower than the cat panel
+	if strings.Contains(lines[1], "Short") {
+		t.Error("Shorter panel should be aligned to the bottom, not the top")
+	}
+
+	// The second panel uses its own think bubble
+	content := strings.Join(lines, "\n")
+	if !strings.Contains(content, "( Short )") {
+		t.Errorf("Expected think bubble for second panel, got:\n%s", content)
+	}
+	if !strings.Contains(content, "< Tall >") {
+		t.Errorf("Expected say bubble for first panel, got:\n%s", content)
+	}
+}
+
+// TestComposeMultiPanelFrames tests pre-rendered frames and bubble-less panels
+func TestComposeMultiPanelFrames(t *testing.T) {
+	cat, _ := GetBuiltinCharacter("cat")
+	owl, _ := GetBuiltinCharacter("owl")
+
+	panels := []PanelConfig{
+		{Text: "Hidden", Character: cat, Eyes: "^^", NoBubble: true},
+		{Text: "Framed", Character: owl, Frame: owl.ToCanvas("XX", "", lipgloss.NewStyle())},
+	}
+	content := strings.Join(ComposeMultiPanel(panels, DefaultConfig()).RenderPlain(), "\n")
+
+	if strings.Contains(content, "Hidden") {
+		t.Errorf("NoBubble panel should not show its text, got:\n%s", content)
+	}
+	if !strings.Contains(content, `|\___/|`) {
+		t.Errorf("NoBubble panel should still show the character, got:\n%s", content)
+	}
+	if !strings.Contains(content, "< Framed >") || !strings.Contains(content, "XX") {
+		t.Errorf("Expected bubble above the pre-rendered frame, got:\n%s", content)
+	}
+}
+
+// rectText returns the plain text of the cells inside r.
+func rectText(c *Canvas, r Rect) string {
+	var lines []string
+	for y := r.Y; y < r.Y+r.Height; y++ {
+		var line strings.Builder
+		for x := r.X; x < r.X+r.Width; x++ {
+			if cell := c.Get(x, y); cell.Grapheme != "" {
+				line.WriteString(cell.Grapheme)
+			}
+		}
+		lines = append(lines, line.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// TestComposeLayout tests that each part of a scene is located correctly
+func TestComposeLayout(t *testing.T) {
+	char := testCatCharacter()
+
+	tests := []struct {
+		name   string
+		tail   TailDirection
+		layout Layout
+	}{
+		{"tail down", TailDown, LayoutVertical},
+		{"horizontal", TailDown, LayoutHorizontal},
+		{"tail up", TailUp, LayoutVertical},
+		{"tail left", TailLeft, LayoutVertical},
+		{"tail right", TailRight, LayoutVertical},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.TailDirection = tt.tail
+			config.Layout = tt.layout
+			result, layout := ComposeLayout("Hello", char, "^^", "w", config)
+
+			parts := map[string]Rect{"bubble": layout.Bubble, "connector": layout.Connector, "character": layout.Character}
+			for name, r := range parts {
+				if r.Empty() {
+					t.Fatalf("%s rect is empty", name)
+				}
+				if r.X < 0 || r.Y < 0 || r.X+r.Width > result.Width || r.Y+r.Height > result.Height {
+					t.Errorf("%s rect %+v is outside the %dx%d canvas", name, r, result.Width, result.Height)
+				}
+			}
+			if got := rectText(result, layout.Bubble); !strings.Contains(got, "Hello") {
+				t.Errorf("Bubble rect holds %q, want the message", got)
+			}
+			if got := rectText(result, layout.Character); !strings.Contains(got, "^^") || strings.Contains(got, "Hello") {
+				t.Errorf("Character rect holds %q, want only the character", got)
+			}
+			if got := rectText(result, layout.Connector); strings.Contains(got, "Hello") || strings.Contains(got, "^^") {
+				t.Errorf("Connector rect holds %q, want only the connector", got)
+			}
+		})
+	}
+}
+
+// TestComposeMultiPanelLayout tests panel layouts on the combined canvas
+func TestComposeMultiPanelLayout(t *testing.T) {
+	cat, _ := GetBuiltinCharacter("cat")
+	owl, _ := GetBuiltinCharacter("owl")
+
+	panels := []PanelConfig{
+		{Text: "Tall\ntext", Character: cat, Eyes: "^^", Mouth: "w"},
+		{Text: "Owl", Character: owl, Eyes: "oo"},
+		{Text: "Hidden", Character: cat, Eyes: "^^", NoBubble: true},
+	}
+	result, layouts := ComposeMultiPanelLayout(panels, DefaultConfig())
+	if len(layouts) != len(panels) {
+		t.Fatalf("Got %d layouts, want %d", len(layouts), len(panels))
+	}
+
+	if got := rectText(result, layouts[1].Bubble); !strings.Contains(got, "Owl") {
+		t.Errorf("Second bubble rect holds %q, want its message", got)
+	}
+	if got := rectText(result, layouts[1].Character); !strings.Contains(got, "oo") {
+		t.Errorf("Second character rect holds %q, want the owl", got)
+	}
+	if layouts[1].Bubble.Y <= layouts[0].Bubble.Y {
+		t.Error("Shorter panel should be offset downwards")
+	}
+	if !layouts[2].Bubble.Empty() || !layouts[2].Connector.Empty() {
+		t.Errorf("NoBubble panel layout = %+v, want only a character", layouts[2])
+	}
+	if got := rectText(result, layouts[2].Character); !strings.Contains(got, `|\___/|`) {
+		t.Errorf("Third character rect holds %q, want the cat", got)
+	}
+}
+
 // TestComposeLongText tests composition with very long text
 func TestComposeLongText(t *testing.T) {
 	char := testCatCharacter()
@@ -721,3 +841,197 @@ func min(a, b int) int {
 	}
 	return b
 }
+
+// TestComposeCodeBubble tests that code bubbles keep lines and highlighting
+func TestComposeCodeBubble(t *testing.T) {
+	char := testCatCharacter()
+	config := DefaultConfig()
+	config.BubbleStyle = BubbleStyleCode
+	config.CodeLanguage = "go"
+
+	c := Compose("if ok {\n\treturn\n}", char, "^^", "w", config)
+	lines := c.RenderPlain()
+
+	if !strings.Contains(lines[0], " go ") {
+		t.Errorf("Expected language header, got %q", lines[0])
+	}
+	want := []string{"│ if ok {    │", "│     return │", "│ }          │"}
+	for i, w := range want {
+		if strings.TrimRight(lines[i+1], " ") != w {
+			t.Errorf("Line %d = %q, want %q", i+1, lines[i+1], w)
+		}
+	}
+
+	// The "if" keyword is drawn with a highlight color
+	if c.Get(2, 1).Grapheme != "i" || c.Get(2, 1).Style.GetForeground() == (lipgloss.NoColor{}) {
+		t.Errorf("Expected highlighted keyword cell, got %+v", c.Get(2, 1))
+	}
+}
+
+// TestComposeColoredText tests that colored input wraps without breaking the bubble
+func TestComposeColoredText(t *testing.T) {
+	char := testCatCharacter()
+	config := DefaultConfig()
+	config.BubbleWidth = 12
+
+	c := Compose("plain \x1b[32mgreen words that wrap\x1b[0m end", char, "^^", "w", config)
+	lines := c.RenderPlain()
+
+	want := []string{
+		" _____________ ",
+		"/ plain green \\",
+		"| words that  |",
+		"\\ wrap end    /",
+		" ------------- ",
+	}
+	for i, w := range want {
+		if strings.TrimRight(lines[i], " ") != strings.TrimRight(w, " ") {
+			t.Errorf("Line %d = %q, want %q", i, lines[i], w)
+		}
+	}
+
+	// "words" on the second line is still green; the border is not
+	if c.Get(2, 2).Style.GetForeground() != lipgloss.Color("2") {
+		t.Errorf("Expected green carried to the next line, got %v", c.Get(2, 2).Style.GetForeground())
+	}
+	if c.Get(0, 2).Style.GetForeground() != (lipgloss.NoColor{}) {
+		t.Error("Bubble border should not take the text color")
+	}
+}
+
+// TestComposeAlign tests aligning text in the bubble
+func TestComposeAlign(t *testing.T) {
+	char := testCatCharacter()
+
+	tests := []struct {
+		align textwrap.Alignment
+		want  []string
+	}{
+		{textwrap.AlignLeft, []string{"/ one two   \\", "| three     |", "\\ four five /"}},
+		{textwrap.AlignCenter, []string{"/  one two  \\", "|   three   |", "\\ four five /"}},
+		{textwrap.AlignRight, []string{"/   one two \\", "|     three |", "\\ four five /"}},
+		// The last line is not justified
+		{textwrap.AlignJustify, []string{"/ one   two \\", "| three     |", "\\ four five /"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.align), func(t *testing.T) {
+			config := DefaultConfig()
+			config.BubbleWidth = 9
+			config.Align = tt.align
+
+			lines := Compose("one two three four five", char, "^^", "w", config).RenderPlain()
+			for i, w := range tt.want {
+				if got := strings.TrimRight(lines[i+1], " "); got != w {
+					t.Errorf("Line %d = %q, want %q", i+1, got, w)
+				}
+			}
+		})
+	}
+}
+
+// TestComposeMarkdown tests drawing inline Markdown as styled cells
+func TestComposeMarkdown(t *testing.T) {
+	char := testCatCharacter()
+	config := DefaultConfig()
+	config.BubbleWidth = 14
+	config.Markdown = true
+
+	c := Compose("**Bold** `code` and\n- a list item that wraps", char, "^^", "w", config)
+	lines := c.RenderPlain()
+
+	want := []string{
+		"/ Bold code and \\",
+		"| • a list item |",
+		"\\   that wraps  /",
+	}
+	for i, w := range want {
+		if got := strings.TrimRight(lines[i+1], " "); got != w {
+			t.Errorf("Line %d = %q, want %q", i+1, got, w)
+		}
+	}
+
+	if !c.Get(2, 1).Style.GetBold() || c.Get(6, 1).Style.GetBold() {
+		t.Error("Expected only the bold word to be bold")
+	}
+	if !c.Get(7, 1).Style.GetReverse() || c.Get(12, 1).Style.GetReverse() {
+		t.Error("Expected only the code span to be inverse")
+	}
+}
+
+// TestComposeOverflow tests text longer than MaxLines
+func TestComposeOverflow(t *testing.T) {
+	char := testCatCharacter()
+
+	tests := []struct {
+		overflow string
+		want     []string
+	}{
+		{OverflowTruncate, []string{"/ one two   \\", "\\ …         /", " -----------"}},
+		{OverflowSplit, []string{"/ one two   \\", "\\ three     /", " -----------", "", " ___________", "< four five >"}},
+		{OverflowPage, []string{"< four five >", " -----------"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.overflow, func(t *testing.T) {
+			config := DefaultConfig()
+			config.BubbleWidth = 9
+			config.MaxLines = 2
+			config.Overflow = tt.overflow
+			config.Page = 1
+
+			lines := Compose("one two three four five", char, "^^", "w", config).RenderPlain()
+			for i, w := range tt.want {
+				if got := strings.TrimRight(lines[i+1], " "); got != w {
+					t.Errorf("Line %d = %q, want %q", i+1, got, w)
+				}
+			}
+		})
+	}
+
+	// A single truncated line leaves room for the ellipsis only
+	config := DefaultConfig()
+	config.MaxLines = 1
+	if got := Compose("one\ntwo", char, "^^", "w", config).RenderPlain()[1]; strings.TrimRight(got, " ") != "< … >" {
+		t.Errorf("Truncated to one line = %q, want only the ellipsis", got)
+	}
+
+	// Text that fits is not affected
+	config = DefaultConfig()
+	config.MaxLines = 2
+	if got := Compose("Hi", char, "^^", "w", config).RenderPlain()[1]; strings.TrimRight(got, " ") != "< Hi >" {
+		t.Errorf("Short text = %q, want one bubble", got)
+	}
+}
+
+// TestPageCount tests counting the pages of bubble text
+func TestPageCount(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		maxLines int
+		code     bool
+		want     int
+	}{
+		{"no limit", "one two three four five", 0, false, 1},
+		{"fits", "one two", 2, false, 1},
+		{"wrapped", "one two three four five", 2, false, 2},
+		{"exact", "one two three four five", 3, false, 1},
+		{"empty", "", 2, false, 1},
+		{"code", "a := 1\nb := 2\nc := 3\n", 1, true, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.BubbleWidth = 9
+			config.MaxLines = tt.maxLines
+			if tt.code {
+				config.BubbleStyle = BubbleStyleCode
+			}
+			if got := PageCount(tt.text, config); got != tt.want {
+				t.Errorf("PageCount() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,380 @@
+package canvas
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/MagikIO/familiar-says/internal/ansi"
+	"github.com/MagikIO/familiar-says/internal/errors"
+)
+
+// Markers substituted for cowsay variables while a .cow heredoc is parsed.
+// They come from the Unicode private use area so they never clash with art.
+const (
+	cowEyesMarker     = '\uE000'
+	cowTongueMarker   = '\uE001'
+	cowThoughtsMarker = '\uE002'
+)
+
+// cowConnectorLen is the connector length the compositor draws by default,
+// used to line the anchor up with the $thoughts trail of a .cow file.
+const cowConnectorLen = 2
+
+// cowHeredocRegex matches the start of the heredoc holding the cow art,
+// e.g. `$the_cow = <<EOC;`, `$the_cow = <<"EOC";` or `$the_cow = <<'EOC';`.
+var cowHeredocRegex = regexp.MustCompile(`\$the_cow\s*=\s*<<\s*(?:"(\w+)"|'(\w+)'|(\w+))`)
+
+// Candidate placeholders for the eye and mouth slots of imported characters.
+var (
+	cowEyePlaceholders   = []string{"@@", "##", "%%", "&&", "==", "**"}
+	cowMouthPlaceholders = []string{"UU", "~~", "++", "__", "::", ";;"}
+)
+
+// ParseCow parses the Perl heredoc of a cowsay .cow file into a Character.
+// The $eyes and $tongue variables become the eye and mouth slots, and the
+// $thoughts trail is removed from the art and used to infer the anchor.
+func ParseCow(data []byte, name string) (*Character, error) {
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+
+	description := ""
+	start, terminator, interpolate := -1, "", true
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if description == "" && strings.HasPrefix(trimmed, "#") {
+			description = strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+		}
+		if m := cowHeredocRegex.FindStringSubmatch(line); m != nil {
+			start = i + 1
+			switch {
+			case m[1] != "":
+				terminator = m[1]
+			case m[2] != "":
+				terminator, interpolate = m[2], false
+			default:
+				terminator = m[3]
+			}
+			break
+		}
+	}
+	if start < 0 {
+		return nil, fmt.Errorf("failed to parse cow %q: no $the_cow heredoc found", name)
+	}
+
+	var body []string
+	terminated := false
+	for _, line := range lines[start:] {
+		if strings.TrimRight(line, " \t") == terminator {
+			terminated = true
+			break
+		}
+		if interpolate {
+			line = expandCowLine(line)
+		}
+		body = append(body, line)
+	}
+	if !terminated {
+		return nil, fmt.Errorf("failed to parse cow %q: missing %s terminator", name, terminator)
+	}
+
+	return cowToCharacter(name, description, body)
+}
+
+// expandCowLine resolves the escapes and cowsay variables of one heredoc line.
+// Known variables are replaced with marker runes; other variables are kept.
+func expandCowLine(line string) string {
+	runes := []rune(line)
+	var sb strings.Builder
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\' && i+1 < len(runes):
+			i++
+			sb.WriteRune(runes[i])
+		case r == '$':
+			name, next := cowVariable(runes, i+1)
+			switch name {
+			case "eyes":
+				sb.WriteRune(cowEyesMarker)
+			case "tongue":
+				sb.WriteRune(cowTongueMarker)
+			case "thoughts":
+				sb.WriteRune(cowThoughtsMarker)
+			default:
+				sb.WriteString(string(runes[i:next]))
+			}
+			i = next - 1
+		default:
+			sb.WriteRune(r)
+		}
+	}
+
+	return sb.String()
+}
+
+// cowVariable reads a Perl variable name ("name" or "{name}") starting at
+// runes[i]. It returns the name and the index just past the variable.
+func cowVariable(runes []rune, i int) (string, int) {
+	braced := i < len(runes) && runes[i] == '{'
+	j := i
+	if braced {
+		j++
+	}
+	nameStart := j
+	for j < len(runes) && (runes[j] == '_' || unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j])) {
+		j++
+	}
+	name := string(runes[nameStart:j])
+	if braced {
+		if j >= len(runes) || runes[j] != '}' {
+			return "", i
+		}
+		j++
+	}
+	return name, j
+}
+
+// cowToCharacter builds a Character from expanded heredoc lines.
+func cowToCharacter(name, description string, body []string) (*Character, error) {
+	// Leading lines holding only the $thoughts trail are not part of the art
+	lead := 0
+	for lead < len(body) && strings.TrimSpace(strings.ReplaceAll(body[lead], string(cowThoughtsMarker), "")) == "" {
+		lead++
+	}
+	art := body[lead:]
+
+	// Locate the first $thoughts marker, counting rows from the first art line
+	thoughtsRow, thoughtsCol, found := 0, 0, false
+	for i, line := range body {
+		if col := markerColumn(line, cowThoughtsMarker); col >= 0 {
+			thoughtsRow, thoughtsCol, found = i-lead, col, true
+			break
+		}
+	}
+
+	for len(art) > 0 && strings.TrimSpace(art[len(art)-1]) == "" {
+		art = art[:len(art)-1]
+	}
+	if len(art) == 0 {
+		return nil, errors.ErrEmptyArt
+	}
+
+	for i, line := range art {
+		art[i] = strings.TrimRight(strings.ReplaceAll(line, string(cowThoughtsMarker), " "), " \t")
+	}
+
+	// The connector is drawn above the art, so place the anchor where the
+	// $thoughts trail would pass cowConnectorLen rows before the art starts.
+	anchorX := 0
+	if found {
+		anchorX = thoughtsCol - (thoughtsRow + cowConnectorLen)
+	}
+
+	// Remove common indentation, keeping the anchor on the canvas
+	dedent := commonIndent(art)
+	if found && anchorX < dedent {
+		dedent = anchorX
+	}
+	if dedent < 0 {
+		dedent = 0
+	}
+	for i, line := range art {
+		art[i] = trimIndent(line, dedent)
+	}
+	anchorX -= dedent
+	if anchorX < 0 {
+		anchorX = 0
+	}
+
+	char := &Character{
+		Name:        name,
+		Description: description,
+		Anchor:      Anchor{X: anchorX, Y: 0},
+	}
+	char.Eyes = fillCowSlot(art, cowEyesMarker, cowEyePlaceholders, "oo")
+	mouthCandidates := cowMouthPlaceholders
+	if char.Eyes != nil {
+		mouthCandidates = withoutPlaceholder(mouthCandidates, char.Eyes.Placeholder)
+	}
+	char.Mouth = fillCowSlot(art, cowTongueMarker, mouthCandidates, "  ")
+	char.Art = art
+
+	return char, nil
+}
+
+// markerColumn returns the display column of the first marker in line, or -1.
+func markerColumn(line string, marker rune) int {
+	idx := strings.IndexRune(line, marker)
+	if idx < 0 {
+		return -1
+	}
+	return StringWidth(line[:idx])
+}
+
+// columnOffset returns the byte offset in line of the grapheme cluster at
+// display column col, or -1 if no cluster starts there.
+func columnOffset(line string, col int) int {
+	offset, x := 0, 0
+	for g, w := range ansi.Graphemes(line) {
+		if x == col {
+			return offset
+		}
+		if x > col {
+			return -1
+		}
+		offset += len(g)
+		x += w
+	}
+	return -1
+}
+
+// commonIndent returns the smallest number of leading spaces across the
+// non-blank lines.
+func commonIndent(lines []string) int {
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " "))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent < 0 {
+		return 0
+	}
+	return indent
+}
+
+// trimIndent removes up to n leading spaces from line.
+func trimIndent(line string, n int) string {
+	for i := 0; i < n && strings.HasPrefix(line, " "); i++ {
+		line = line[1:]
+	}
+	return line
+}
+
+// fillCowSlot replaces the first marker in art with a placeholder not found
+// elsewhere in the art and returns the matching slot. Any further markers are
+// replaced with fallback. It returns nil if the marker does not appear.
+func fillCowSlot(art []string, marker rune, candidates []string, fallback string) *Slot {
+	var slot *Slot
+	for i, line := range art {
+		for strings.ContainsRune(line, marker) {
+			if slot == nil {
+				placeholder := pickPlaceholder(art, candidates)
+				slot = &Slot{
+					Line:        i,
+					Col:         markerColumn(line, marker),
+					Width:       StringWidth(placeholder),
+					Placeholder: placeholder,
+				}
+				line = strings.Replace(line, string(marker), placeholder, 1)
+			} else {
+				line = strings.Replace(line, string(marker), fallback, 1)
+			}
+		}
+		art[i] = line
+	}
+	return slot
+}
+
+// pickPlaceholder returns the first candidate that does not occur in art.
+func pickPlaceholder(art []string, candidates []string) string {
+	joined := strings.Join(art, "\n")
+	for _, candidate := range candidates {
+		if !strings.Contains(joined, candidate) {
+			return candidate
+		}
+	}
+	return candidates[0]
+}
+
+// withoutPlaceholder returns candidates with the given placeholder removed.
+func withoutPlaceholder(candidates []string, placeholder string) []string {
+	result := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate != placeholder {
+			result = append(result, candidate)
+		}
+	}
+	return result
+}
+
+// cowEscaper escapes the characters Perl would interpolate in a heredoc.
+var cowEscaper = strings.NewReplacer(`\`, `\\`, `@`, `\@`, `$`, `\$`)
+
+// FormatCow converts a character to a classic cowsay .cow file. The eye and
+// mouth placeholders become $eyes and $tongue, and the connector becomes a
+// $thoughts trail leading to the anchor. Features .cow files cannot express,
+// such as colors and animations, are dropped and reported as warnings.
+func FormatCow(char *Character) ([]byte, []string) {
+	var warnings []string
+	if char.Colors != nil {
+		warnings = append(warnings, "colors are not supported by .cow files and were dropped")
+	}
+	if names := char.ListAnimations(); len(names) > 0 {
+		warnings = append(warnings, fmt.Sprintf("animations are not supported by .cow files and were dropped: %s", strings.Join(names, ", ")))
+	}
+
+	art := make([]string, len(char.Art))
+	copy(art, char.Art)
+	slots := []struct {
+		name   string
+		slot   *Slot
+		marker rune
+	}{
+		{"eyes", char.Eyes, cowEyesMarker},
+		{"mouth", char.Mouth, cowTongueMarker},
+	}
+	// Splice the right slot of a shared line first, so the column of the
+	// other still matches the art
+	if char.Eyes != nil && char.Mouth != nil && char.Eyes.Line == char.Mouth.Line && char.Eyes.Col < char.Mouth.Col {
+		slots[0], slots[1] = slots[1], slots[0]
+	}
+	for _, s := range slots {
+		if s.slot == nil || s.slot.Placeholder == "" || s.slot.Line < 0 || s.slot.Line >= len(art) {
+			continue
+		}
+		line := art[s.slot.Line]
+		at := columnOffset(line, s.slot.Col)
+		if at < 0 || !strings.HasPrefix(line[at:], s.slot.Placeholder) {
+			warnings = append(warnings, fmt.Sprintf("%s placeholder %q not found at column %d of line %d and was dropped", s.name, s.slot.Placeholder, s.slot.Col, s.slot.Line))
+			continue
+		}
+		if w := StringWidth(s.slot.Placeholder); w != 2 {
+			warnings = append(warnings, fmt.Sprintf("%s slot is %d columns wide but cowsay always inserts 2; the art may shift", s.name, w))
+		}
+		art[s.slot.Line] = line[:at] + string(s.marker) + line[at+len(s.slot.Placeholder):]
+	}
+
+	var sb strings.Builder
+	sb.WriteString("##\n")
+	if char.Description != "" {
+		fmt.Fprintf(&sb, "## %s\n", char.Description)
+	} else {
+		fmt.Fprintf(&sb, "## %s\n", char.Name)
+	}
+	sb.WriteString("## Exported from familiar-says\n")
+	sb.WriteString("##\n")
+	sb.WriteString("$the_cow = <<\"EOC\";\n")
+
+	for i := 0; i < cowConnectorLen; i++ {
+		sb.WriteString(strings.Repeat(" ", char.Anchor.X+i))
+		sb.WriteString("$thoughts\n")
+	}
+
+	for _, line := range art {
+		line = cowEscaper.Replace(strings.TrimRight(line, " "))
+		line = strings.ReplaceAll(line, string(cowEyesMarker), "$eyes")
+		line = strings.ReplaceAll(line, string(cowTongueMarker), "$tongue")
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("EOC\n")
+
+	return []byte(sb.String()), warnings
+}
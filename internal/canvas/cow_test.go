@@ -0,0 +1,283 @@
+package canvas
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const defaultCow = `##
+## The default cow
+##
+$the_cow = <<"EOC";
+        $thoughts   ^__^
+         $thoughts  ($eyes)\\_______
+            (__)\\       )\\/\\
+             $tongue ||----w |
+                ||     ||
+EOC
+`
+
+// TestParseCow tests parsing the classic default cow
+func TestParseCow(t *testing.T) {
+	char, err := ParseCow([]byte(defaultCow), "default")
+	if err != nil {
+		t.Fatalf("ParseCow failed: %v", err)
+	}
+
+	if char.Name != "default" {
+		t.Errorf("Expected name 'default', got %q", char.Name)
+	}
+	if char.Description != "The default cow" {
+		t.Errorf("Expected description 'The default cow', got %q", char.Description)
+	}
+
+	expectedArt := []string{
+		"      ^__^",
+		"      (@@)\\_______",
+		"      (__)\\       )\\/\\",
+		"       UU ||----w |",
+		"          ||     ||",
+	}
+	if len(char.Art) != len(expectedArt) {
+		t.Fatalf("Expected %d art lines, got %d: %q", len(expectedArt), len(char.Art), char.Art)
+	}
+	for i, line := range expectedArt {
+		if char.Art[i] != line {
+			t.Errorf("Art line %d: expected %q, got %q", i, line, char.Art[i])
+		}
+	}
+
+	if char.Anchor.X != 0 || char.Anchor.Y != 0 {
+		t.Errorf("Expected anchor (0, 0), got (%d, %d)", char.Anchor.X, char.Anchor.Y)
+	}
+	if char.Eyes == nil || *char.Eyes != (Slot{Line: 1, Col: 7, Width: 2, Placeholder: "@@"}) {
+		t.Errorf("Unexpected eyes slot: %+v", char.Eyes)
+	}
+	if char.Mouth == nil || *char.Mouth != (Slot{Line: 3, Col: 7, Width: 2, Placeholder: "UU"}) {
+		t.Errorf("Unexpected mouth slot: %+v", char.Mouth)
+	}
+
+	// The slots must line up with the art when rendered
+	lines := char.ToCanvas("^^", "U ", lipgloss.NewStyle()).Render()
+	if !strings.Contains(lines[1], "(^^)") {
+		t.Errorf("Expected eyes in rendered art, got %q", lines[1])
+	}
+	if !strings.Contains(lines[3], "U  ||") {
+		t.Errorf("Expected tongue in rendered art, got %q", lines[3])
+	}
+}
+
+// TestParseCowVariants tests heredoc forms, escapes and thoughts trails
+func TestParseCowVariants(t *testing.T) {
+	tests := []struct {
+		name        string
+		cow         string
+		wantArt     []string
+		wantAnchorX int
+		wantEyes    bool
+	}{
+		{
+			name: "bare terminator and braced variables",
+			cow: "$the_cow = <<EOC;\n" +
+				"  ${thoughts}\n" +
+				"   ${thoughts}\n" +
+				"    [${eyes}]\n" +
+				"EOC\n",
+			wantArt:     []string{"  [@@]"},
+			wantAnchorX: 0,
+			wantEyes:    true,
+		},
+		{
+			name: "thoughts trail above indented art",
+			cow: "$the_cow = <<\"EOC\";\n" +
+				"      $thoughts\n" +
+				"       $thoughts\n" +
+				"     /\\\\_/\\\\\n" +
+				"    ( $eyes )\n" +
+				"EOC\n",
+			wantArt:     []string{" /\\_/\\", "( @@ )"},
+			wantAnchorX: 2,
+			wantEyes:    true,
+		},
+		{
+			name: "escaped sigils avoid placeholder clash",
+			cow: "$the_cow = <<EOC;\n" +
+				"  \\@\\@\\$ $eyes\n" +
+				"EOC\n",
+			wantArt:     []string{"@@$ ##"},
+			wantAnchorX: 0,
+			wantEyes:    true,
+		},
+		{
+			name: "single quoted heredoc is not interpolated",
+			cow: "$the_cow = <<'EOC';\n" +
+				"  (oo) $eyes\n" +
+				"EOC\n",
+			wantArt:     []string{"(oo) $eyes"},
+			wantAnchorX: 0,
+			wantEyes:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			char, err := ParseCow([]byte(tt.cow), "test")
+			if err != nil {
+				t.Fatalf("ParseCow failed: %v", err)
+			}
+			if strings.Join(char.Art, "\n") != strings.Join(tt.wantArt, "\n") {
+				t.Errorf("Expected art %q, got %q", tt.wantArt, char.Art)
+			}
+			if char.Anchor.X != tt.wantAnchorX {
+				t.Errorf("Expected anchor X %d, got %d", tt.wantAnchorX, char.Anchor.X)
+			}
+			if (char.Eyes != nil) != tt.wantEyes {
+				t.Errorf("Expected eyes slot %v, got %+v", tt.wantEyes, char.Eyes)
+			}
+		})
+	}
+}
+
+// TestParseCowErrors tests malformed .cow files
+func TestParseCowErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cow  string
+	}{
+		{"no heredoc", "print 'moo';\n"},
+		{"missing terminator", "$the_cow = <<EOC;\n  (oo)\n"},
+		{"empty art", "$the_cow = <<EOC;\n  $thoughts\nEOC\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseCow([]byte(tt.cow), "test"); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
+
+// TestLoadCharacterCow tests that LoadCharacter dispatches .cow files
+func TestLoadCharacterCow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "moo.cow")
+	if err := os.WriteFile(path, []byte(defaultCow), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	char, err := LoadCharacter(path)
+	if err != nil {
+		t.Fatalf("LoadCharacter failed: %v", err)
+	}
+	if char.Name != "moo" {
+		t.Errorf("Expected name 'moo', got %q", char.Name)
+	}
+	if char.Eyes == nil || char.Mouth == nil {
+		t.Error("Expected eye and mouth slots")
+	}
+}
+
+// TestFormatCow tests exporting a character to the .cow format
+func TestFormatCow(t *testing.T) {
+	char := &Character{
+		Name:        "cash",
+		Description: "Costs $5 @ the shop",
+		Art: []string{
+			" /\\_/\\",
+			"( @@ )",
+			" > ^^ <",
+		},
+		Anchor: Anchor{X: 2},
+		Eyes:   &Slot{Line: 1, Col: 2, Width: 2, Placeholder: "@@"},
+		Mouth:  &Slot{Line: 2, Col: 3, Width: 2, Placeholder: "^^"},
+	}
+
+	data, warnings := FormatCow(char)
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+
+	cow := string(data)
+	for _, want := range []string{
+		"$the_cow = <<\"EOC\";\n",
+		"  $thoughts\n   $thoughts\n",
+		" /\\\\_/\\\\\n",
+		"( $eyes )\n",
+		" > $tongue <\n",
+		"EOC\n",
+	} {
+		if !strings.Contains(cow, want) {
+			t.Errorf("Expected .cow output to contain %q, got:\n%s", want, cow)
+		}
+	}
+
+	// Exported files must parse back to the same character
+	parsed, err := ParseCow(data, "cash")
+	if err != nil {
+		t.Fatalf("ParseCow failed on exported cow: %v", err)
+	}
+	want := char.ToCanvas("oo", "--", lipgloss.NewStyle()).Render()
+	got := parsed.ToCanvas("oo", "--", lipgloss.NewStyle()).Render()
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Round trip render mismatch:\nwant %q\ngot  %q", want, got)
+	}
+	if parsed.Anchor != char.Anchor {
+		t.Errorf("Round trip anchor = %+v, want %+v", parsed.Anchor, char.Anchor)
+	}
+	if parsed.Eyes == nil || parsed.Eyes.Line != 1 || parsed.Eyes.Col != 2 {
+		t.Errorf("Round trip eyes slot = %+v", parsed.Eyes)
+	}
+	if parsed.Mouth == nil || parsed.Mouth.Line != 2 || parsed.Mouth.Col != 3 {
+		t.Errorf("Round trip mouth slot = %+v", parsed.Mouth)
+	}
+}
+
+// TestFormatCowSlotColumn tests that the slot column picks the placeholder
+// when the same text appears earlier on the line
+func TestFormatCowSlotColumn(t *testing.T) {
+	char := &Character{
+		Name:  "twins",
+		Art:   []string{"oo (oo) 日oo"},
+		Eyes:  &Slot{Line: 0, Col: 4, Width: 2, Placeholder: "oo"},
+		Mouth: &Slot{Line: 0, Col: 10, Width: 2, Placeholder: "oo"},
+	}
+
+	data, warnings := FormatCow(char)
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+	if want := "oo ($eyes) 日$tongue\n"; !strings.Contains(string(data), want) {
+		t.Errorf("Expected .cow output to contain %q, got:\n%s", want, data)
+	}
+
+	// A slot whose column does not hold the placeholder is dropped
+	char.Eyes.Col = 5
+	if _, warnings := FormatCow(char); len(warnings) != 1 || !strings.Contains(warnings[0], "column 5") {
+		t.Errorf("Expected a warning about column 5, got %v", warnings)
+	}
+}
+
+// TestFormatCowWarnings tests that unsupported features are reported
+func TestFormatCowWarnings(t *testing.T) {
+	char := &Character{
+		Name:   "fancy",
+		Art:    []string{"(@)"},
+		Eyes:   &Slot{Line: 0, Col: 1, Width: 1, Placeholder: "@"},
+		Colors: &CharacterColors{Eyes: "red"},
+		Animations: map[string]*AnimationSequence{
+			"blink": {Frames: []AnimationFrame{{DurationMs: 100, Eyes: "-"}}},
+		},
+	}
+
+	_, warnings := FormatCow(char)
+	if len(warnings) != 3 {
+		t.Fatalf("Expected 3 warnings (colors, animations, slot width), got %d: %v", len(warnings), warnings)
+	}
+	if !strings.Contains(warnings[1], "blink") {
+		t.Errorf("Expected animation warning to name blink, got %q", warnings[1])
+	}
+}
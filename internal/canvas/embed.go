@@ -3,24 +3,25 @@ package canvas
 import (
 	"embed"
 	"encoding/json"
-	"os"
-	"path/filepath"
+	"io/fs"
+	"path"
 	"sort"
 	"strings"
 	"sync"
-)
 
-// embeddedCharacters holds the embedded filesystem set by SetEmbeddedFS.
-var embeddedCharacters embed.FS
+	"github.com/MagikIO/familiar-says/characters"
+)
 
-// embeddedFSSet tracks whether the embedded FS has been set.
-var embeddedFSSet bool
+// embeddedCharacters holds the filesystem the builtin characters are read from.
+// It defaults to the definitions embedded by the characters package, so callers
+// do not need to call SetEmbeddedFS before rendering.
+var embeddedCharacters embed.FS = characters.FS
 
 // characterDirPrefix is the directory prefix for character files in the embedded FS.
-var characterDirPrefix = "characters"
+var characterDirPrefix = "."
 
-// SetEmbeddedFS sets the embedded filesystem containing character JSON files.
-// This must be called during initialization from the main package.
+// SetEmbeddedFS replaces the builtin characters with the JSON files found in
+// the "characters" directory of fs.
 func SetEmbeddedFS(fs embed.FS) {
 	SetEmbeddedFSWithPrefix(fs, "characters")
 }
@@ -29,11 +30,10 @@ func SetEmbeddedFS(fs embed.FS) {
 // This is useful for tests that have a different directory structure.
 func SetEmbeddedFSWithPrefix(fs embed.FS, prefix string) {
 	embeddedCharacters = fs
-	embeddedFSSet = true
 	characterDirPrefix = prefix
 	// Clear any cached data when FS changes
 	ClearCharacterCache()
-	characterListOnce = sync.Once{}
+	ResetCharacterList()
 }
 
 // characterCache caches loaded characters to avoid re-parsing JSON
@@ -44,29 +44,7 @@ var (
 	characterListOnce  sync.Once
 )
 
-// findCharactersDir searches for the characters directory by walking up from cwd.
-// This is used as a fallback when embedded FS is not available (e.g., during tests).
-func findCharactersDir() (string, bool) {
-	dir, err := os.Getwd()
-	if err != nil {
-		return "", false
-	}
-
-	for {
-		charDir := filepath.Join(dir, "characters")
-		if info, err := os.Stat(charDir); err == nil && info.IsDir() {
-			return charDir, true
-		}
-		parent := filepath.Dir(dir)
-		if parent == dir {
-			return "", false
-		}
-		dir = parent
-	}
-}
-
-// loadEmbeddedCharacter loads a character from the embedded filesystem,
-// or falls back to file-based loading if embedded FS is not set.
+// loadEmbeddedCharacter loads a character from the embedded filesystem.
 func loadEmbeddedCharacter(name string) (*Character, error) {
 	// Check cache first
 	characterCacheMu.RLock()
@@ -76,23 +54,7 @@ func loadEmbeddedCharacter(name string) (*Character, error) {
 	}
 	characterCacheMu.RUnlock()
 
-	var data []byte
-	var err error
-
-	if embeddedFSSet {
-		// Load from embedded FS
-		filename := characterDirPrefix + "/" + name + ".json"
-		data, err = embeddedCharacters.ReadFile(filename)
-	} else {
-		// Fallback: load from filesystem (useful for tests)
-		charDir, found := findCharactersDir()
-		if !found {
-			return nil, os.ErrNotExist
-		}
-		filename := filepath.Join(charDir, name+".json")
-		data, err = os.ReadFile(filename)
-	}
-
+	data, err := embeddedCharacters.ReadFile(path.Join(characterDirPrefix, name+".json"))
 	if err != nil {
 		return nil, err
 	}
@@ -118,20 +80,7 @@ func loadEmbeddedCharacter(name string) (*Character, error) {
 // listEmbeddedCharacters returns a sorted list of all embedded character names.
 func listEmbeddedCharacters() []string {
 	characterListOnce.Do(func() {
-		var entries []os.DirEntry
-		var err error
-
-		if embeddedFSSet {
-			entries, err = embeddedCharacters.ReadDir(characterDirPrefix)
-		} else {
-			// Fallback: read from filesystem
-			charDir, found := findCharactersDir()
-			if !found {
-				return
-			}
-			entries, err = os.ReadDir(charDir)
-		}
-
+		entries, err := embeddedCharacters.ReadDir(characterDirPrefix)
 		if err != nil {
 			return
 		}
@@ -152,6 +101,16 @@ func listEmbeddedCharacters() []string {
 	return characterListCache
 }
 
+// BuiltinFS returns the filesystem holding the builtin character files,
+// rooted at the directory that contains them.
+func BuiltinFS() fs.FS {
+	sub, err := fs.Sub(embeddedCharacters, characterDirPrefix)
+	if err != nil {
+		return embeddedCharacters
+	}
+	return sub
+}
+
 // GetEmbeddedCharacter returns an embedded character by name.
 func GetEmbeddedCharacter(name string) (*Character, bool) {
 	name = strings.ToLower(strings.TrimSpace(name))
@@ -0,0 +1,119 @@
+package canvas
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/MagikIO/familiar-says/internal/ansi"
+)
+
+// hyperlinks is whether rendered output contains OSC 8 hyperlinks.
+var hyperlinks atomic.Bool
+
+// SetHyperlinks turns OSC 8 hyperlinks in rendered output on or off.
+func SetHyperlinks(on bool) {
+	hyperlinks.Store(on)
+}
+
+// Hyperlinks reports whether rendered output contains OSC 8 hyperlinks.
+func Hyperlinks() bool {
+	return hyperlinks.Load()
+}
+
+// hyperlinkPrograms are TERM_PROGRAM values of terminals known to support
+// OSC 8 hyperlinks.
+var hyperlinkPrograms = []string{"iTerm.app", "WezTerm", "vscode", "ghostty", "Hyper", "Tabby", "rio"}
+
+// hyperlinkTerms are TERM values of terminals known to support OSC 8
+// hyperlinks.
+var hyperlinkTerms = []string{"xterm-kitty", "xterm-ghostty", "wezterm", "alacritty", "foot"}
+
+// HyperlinksSupported reports whether a terminal is known to support OSC 8
+// hyperlinks, judging by the environment. FORCE_HYPERLINK set to anything
+// but "0" turns them on even when not writing to a terminal, and "0" turns
+// them off.
+func HyperlinksSupported(isTerminal bool, getenv func(string) string) bool {
+	if force := getenv("FORCE_HYPERLINK"); force != "" {
+		return force != "0"
+	}
+	if !isTerminal {
+		return false
+	}
+
+	if slices.Contains(hyperlinkPrograms, getenv("TERM_PROGRAM")) {
+		return true
+	}
+	term := getenv("TERM")
+	if slices.ContainsFunc(hyperlinkTerms, func(t string) bool { return strings.HasPrefix(term, t) }) {
+		return true
+	}
+	for _, key := range []string{"WT_SESSION", "KONSOLE_VERSION", "KITTY_WINDOW_ID", "DOMTERM"} {
+		if getenv(key) != "" {
+			return true
+		}
+	}
+	// GNOME Terminal and other VTE terminals since 0.50
+	vte, err := strconv.Atoi(getenv("VTE_VERSION"))
+	return err == nil && vte >= 5000
+}
+
+// linkPattern matches Markdown links to web pages and bare URLs.
+var linkPattern = regexp.MustCompile(`\[([^\]\x1b]+)\]\((https?://[^\s()\x1b]+)\)|https?://[^\s<>"\x1b]+`)
+
+// Linkify turns bare URLs and Markdown "[text](url)" links in text into OSC
+// 8 hyperlinks if hyperlinks are enabled. Otherwise Markdown links are
+// written out as "text (url)". Text that already contains hyperlinks is left
+// alone.
+func Linkify(text string) string {
+	if !strings.Contains(text, "://") || strings.Contains(text, "\x1b]8;") {
+		return text
+	}
+	on := Hyperlinks()
+
+	var b strings.Builder
+	last := 0
+	for _, m := range linkPattern.FindAllStringSubmatchIndex(text, -1) {
+		b.WriteString(text[last:m[0]])
+		last = m[1]
+
+		if m[2] >= 0 {
+			label, url := text[m[2]:m[3]], text[m[4]:m[5]]
+			if on {
+				b.WriteString(ansi.Hyperlink(label, url))
+			} else {
+				b.WriteString(label + " (" + url + ")")
+			}
+			continue
+		}
+
+		// Punctuation ending a sentence is not part of a bare URL
+		url := trimURL(text[m[0]:m[1]])
+		last = m[0] + len(url)
+		if on {
+			b.WriteString(ansi.Hyperlink(url, url))
+		} else {
+			b.WriteString(url)
+		}
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// trimURL removes trailing punctuation from a URL found in prose, keeping
+// closing parentheses that balance opening ones, as in Wikipedia links.
+func trimURL(url string) string {
+	for url != "" {
+		switch last := url[len(url)-1]; {
+		case strings.IndexByte(".,;:!?'\"]}", last) >= 0:
+			url = url[:len(url)-1]
+		case last == ')' && strings.Count(url, ")") > strings.Count(url, "("):
+			url = url[:len(url)-1]
+		default:
+			return url
+		}
+	}
+	return url
+}
@@ -0,0 +1,146 @@
+package canvas
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MagikIO/familiar-says/internal/ansi"
+	"github.com/MagikIO/familiar-says/internal/textwrap"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// TestHyperlinksSupported tests detecting hyperlink support from the
+// environment
+func TestHyperlinksSupported(t *testing.T) {
+	tests := []struct {
+		name     string
+		terminal bool
+		env      map[string]string
+		want     bool
+	}{
+		{"unknown terminal", true, map[string]string{"TERM": "xterm-256color"}, false},
+		{"iTerm", true, map[string]string{"TERM_PROGRAM": "iTerm.app"}, true},
+		{"kitty", true, map[string]string{"TERM": "xterm-kitty"}, true},
+		{"Windows Terminal", true, map[string]string{"WT_SESSION": "1"}, true},
+		{"new VTE", true, map[string]string{"VTE_VERSION": "6003"}, true},
+		{"old VTE", true, map[string]string{"VTE_VERSION": "4601"}, false},
+		{"pipe", false, map[string]string{"TERM_PROGRAM": "iTerm.app"}, false},
+		{"forced in a pipe", false, map[string]string{"FORCE_HYPERLINK": "1"}, true},
+		{"forced off", true, map[string]string{"FORCE_HYPERLINK": "0", "TERM_PROGRAM": "WezTerm"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			if got := HyperlinksSupported(tt.terminal, getenv); got != tt.want {
+				t.Errorf("HyperlinksSupported(%v) = %v, want %v", tt.terminal, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLinkify tests finding links in message text
+func TestLinkify(t *testing.T) {
+	defer SetHyperlinks(Hyperlinks())
+
+	tests := []struct {
+		name  string
+		input string
+		plain string
+		links string
+	}{
+		{
+			"bare URL",
+			"See https://example.com/a.",
+			"See https://example.com/a.",
+			"See " + ansi.Hyperlink("https://example.com/a", "https://example.com/a") + ".",
+		},
+		{
+			"markdown link",
+			"Read [the runbook](https://example.com/rb) now",
+			"Read the runbook (https://example.com/rb) now",
+			"Read " + ansi.Hyperlink("the runbook", "https://example.com/rb") + " now",
+		},
+		{
+			"balanced parentheses",
+			"(https://en.wikipedia.org/wiki/Go_(game))",
+			"(https://en.wikipedia.org/wiki/Go_(game))",
+			"(" + ansi.Hyperlink("https://en.wikipedia.org/wiki/Go_(game)", "https://en.wikipedia.org/wiki/Go_(game)") + ")",
+		},
+		{"no links", "Just text", "Just text", "Just text"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetHyperlinks(false)
+			if got := Linkify(tt.input); got != tt.plain {
+				t.Errorf("Linkify without hyperlinks = %q, want %q", got, tt.plain)
+			}
+			SetHyperlinks(true)
+			if got := Linkify(tt.input); got != tt.links {
+				t.Errorf("Linkify with hyperlinks = %q, want %q", got, tt.links)
+			}
+		})
+	}
+}
+
+// TestRenderHyperlinks tests OSC 8 sequences in rendered output
+func TestRenderHyperlinks(t *testing.T) {
+	defer SetHyperlinks(Hyperlinks())
+
+	c := NewCanvas(12, 1)
+	c.DrawString(0, 0, "a "+ansi.Hyperlink("link", "https://example.com")+" b", lipgloss.NewStyle())
+
+	// Links survive composition
+	result := NewCanvas(14, 2)
+	result.Overlay(c, 1, 1)
+	if cell := result.Get(3, 1); cell.Link != "https://example.com" {
+		t.Fatalf("Overlaid cell link = %q, want the URL", cell.Link)
+	}
+
+	SetHyperlinks(true)
+	want := " a " + ansi.OpenHyperlink("https://example.com") + "link" + ansi.CloseHyperlink + " b"
+	if got := strings.TrimRight(result.Render()[1], " "); got != want {
+		t.Errorf("Render with hyperlinks = %q, want %q", got, want)
+	}
+
+	SetHyperlinks(false)
+	if got := strings.TrimRight(result.Render()[1], " "); got != " a link b" {
+		t.Errorf("Render without hyperlinks = %q, want plain text", got)
+	}
+}
+
+// TestWrapTextHyperlinks tests that links wrap by their visible text and
+// are never broken
+func TestWrapTextHyperlinks(t *testing.T) {
+	link := ansi.Hyperlink("the run book", "https://example.com/a/very/long/url")
+	lines := wrapText("Read "+link+" today", 14, textwrap.AlignLeft, false)
+
+	// Lines are padded to the widest, the link
+	want := []string{"Read        ", link, "today       "}
+	assertLines(t, lines, want)
+}
+
+// TestWrapTextURLs tests that bare URLs are broken to the width when
+// hyperlinks are off
+func TestWrapTextURLs(t *testing.T) {
+	defer SetHyperlinks(Hyperlinks())
+	SetHyperlinks(false)
+
+	lines := wrapText(Linkify("see https://example.com/a/very/long/path ok"), 14, textwrap.AlignLeft, false)
+	want := []string{"see           ", "https://exampl", "e.com/a/very/l", "ong/path ok   "}
+	assertLines(t, lines, want)
+}
+
+// assertLines reports the lines that differ from want.
+func assertLines(t *testing.T, lines, want []string) {
+	t.Helper()
+	if len(lines) != len(want) {
+		t.Fatalf("wrapText = %q, want %q", lines, want)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("Line %d = %q, want %q", i, lines[i], want[i])
+		}
+	}
+}
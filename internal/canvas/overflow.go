@@ -0,0 +1,94 @@
+package canvas
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/MagikIO/familiar-says/internal/ascii"
+	"github.com/MagikIO/familiar-says/internal/bubble"
+)
+
+// Overflow modes for bubble text longer than CompositorConfig.MaxLines.
+const (
+	OverflowTruncate = "truncate" // Cut the text short, ending with an ellipsis line
+	OverflowSplit    = "split"    // Stack several bubbles above the character
+	OverflowPage     = "page"     // Show the page of the text chosen by CompositorConfig.Page
+)
+
+// Overflows returns all overflow modes.
+func Overflows() []string {
+	return []string{OverflowTruncate, OverflowSplit, OverflowPage}
+}
+
+// PageCount returns how many pages of at most config.MaxLines lines the text
+// of a bubble composed with config takes up.
+func PageCount(text string, config CompositorConfig) int {
+	if config.BubbleWidth <= 0 {
+		config.BubbleWidth = 40
+	}
+	tmpl := bubbleTemplate(config)
+	n := len(codeLines(text))
+	if !tmpl.IsCodeBlock {
+		n = len(bubbleContent(text, config.BubbleWidth, tmpl))
+	}
+	if config.MaxLines <= 0 {
+		return 1
+	}
+	return max((n+config.MaxLines-1)/config.MaxLines, 1)
+}
+
+// overflowPages splits the lines of a bubble into the bubbles shown for
+// config: all of them if they fit in config.MaxLines, and otherwise the
+// first lines and an ellipsis line, every page of lines, or one page,
+// depending on config.Overflow. Truncated bubbles never exceed MaxLines, so
+// with a single line only the ellipsis is left.
+func overflowPages(lines []string, config CompositorConfig) [][]string {
+	n := config.MaxLines
+	if n <= 0 || len(lines) <= n {
+		return [][]string{lines}
+	}
+
+	switch config.Overflow {
+	case OverflowSplit:
+		return slices.Collect(slices.Chunk(lines, n))
+	case OverflowPage:
+		pages := slices.Collect(slices.Chunk(lines, n))
+		return pages[min(max(config.Page, 0), len(pages)-1):][:1]
+	default:
+		truncated := slices.Clone(lines[:n-1])
+		return [][]string{append(truncated, ascii.Apply("…"))}
+	}
+}
+
+// codeLines splits code into its lines, ignoring trailing line breaks.
+func codeLines(code string) []string {
+	return strings.Split(strings.TrimRight(code, "\n"), "\n")
+}
+
+// renderCodePages renders the code bubbles shown for config. The language
+// is detected from all of the code, so every page is highlighted alike.
+func renderCodePages(code string, config CompositorConfig, tmpl *bubble.BubbleTemplate) []*Canvas {
+	highlight := bubble.HighlightConfig{Language: config.CodeLanguage, Style: config.CodeStyle}
+	if config.MaxLines <= 0 {
+		return []*Canvas{RenderCodeBubble(code, config.BubbleWidth, highlight, tmpl, config.BubbleColor)}
+	}
+	if highlight.Language == "" && !strings.ContainsRune(code, '\x1b') {
+		highlight.Language = bubble.DetectLanguage(code)
+	}
+
+	pages := overflowPages(codeLines(code), config)
+	bubbles := make([]*Canvas, len(pages))
+	for i, page := range pages {
+		bubbles[i] = RenderCodeBubble(strings.Join(page, "\n"), config.BubbleWidth, highlight, tmpl, config.BubbleColor)
+	}
+	return bubbles
+}
+
+// stackBubbles stacks bubbles from top to bottom, a blank line apart.
+func stackBubbles(bubbles []*Canvas) *Canvas {
+	result := bubbles[0]
+	for _, b := range bubbles[1:] {
+		result = Stack(result, b, 1)
+	}
+	return result
+}
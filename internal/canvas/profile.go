@@ -0,0 +1,75 @@
+package canvas
+
+import (
+	"io"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Color modes choose whether output is colored and at what depth.
+const (
+	ColorAuto      = "auto"      // Color terminals only, following NO_COLOR and CLICOLOR_FORCE
+	ColorAlways    = "always"    // Color even when not writing to a terminal
+	ColorNever     = "never"     // No colors or other styling
+	Color16        = "16"        // Force the 16 basic ANSI colors
+	Color256       = "256"       // Force the 256 color palette
+	ColorTrueColor = "truecolor" // Force 24-bit color
+)
+
+// ColorModes returns all supported color modes.
+func ColorModes() []string {
+	return []string{ColorAuto, ColorAlways, ColorNever, Color16, Color256, ColorTrueColor}
+}
+
+// ColorProfile resolves a color mode to the profile colors are downsampled
+// to. In auto mode a set NO_COLOR turns colors off, and a set
+// CLICOLOR_FORCE other than "0" turns them on when not writing to a
+// terminal. The depth of enabled colors is detected from TERM and COLORTERM,
+// with at least 16 colors when they were asked for explicitly.
+func ColorProfile(mode string, isTerminal bool, getenv func(string) string) termenv.Profile {
+	switch mode {
+	case ColorNever:
+		return termenv.Ascii
+	case Color16:
+		return termenv.ANSI
+	case Color256:
+		return termenv.ANSI256
+	case ColorTrueColor:
+		return termenv.TrueColor
+	}
+
+	forced := mode == ColorAlways
+	if !forced {
+		if getenv("NO_COLOR") != "" {
+			return termenv.Ascii
+		}
+		if force := getenv("CLICOLOR_FORCE"); force != "" && force != "0" {
+			forced = true
+		} else if !isTerminal {
+			return termenv.Ascii
+		}
+	}
+
+	// termenv detects the depth from the environment once told the output
+	// is a terminal
+	output := termenv.NewOutput(io.Discard, termenv.WithTTY(true), termenv.WithEnvironment(environ(getenv)))
+	profile := output.ColorProfile()
+	if forced && profile == termenv.Ascii {
+		return termenv.ANSI
+	}
+	return profile
+}
+
+// SetColorMode sets the color profile that all styled output is rendered
+// with, for output that is or is not a terminal.
+func SetColorMode(mode string, isTerminal bool) {
+	lipgloss.SetColorProfile(ColorProfile(mode, isTerminal, os.Getenv))
+}
+
+// environ adapts a getenv function to termenv's environment interface.
+type environ func(string) string
+
+func (e environ) Getenv(key string) string { return e(key) }
+func (e environ) Environ() []string        { return nil }
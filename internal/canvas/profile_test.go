@@ -0,0 +1,75 @@
+package canvas
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// TestColorProfile tests resolving color modes against the environment
+func TestColorProfile(t *testing.T) {
+	truecolor := map[string]string{"TERM": "xterm-256color", "COLORTERM": "truecolor"}
+
+	tests := []struct {
+		name     string
+		mode     string
+		terminal bool
+		env      map[string]string
+		want     termenv.Profile
+	}{
+		{"auto terminal", ColorAuto, true, truecolor, termenv.TrueColor},
+		{"auto 256 color terminal", ColorAuto, true, map[string]string{"TERM": "xterm-256color"}, termenv.ANSI256},
+		{"auto pipe", ColorAuto, false, truecolor, termenv.Ascii},
+		{"NO_COLOR", ColorAuto, true, map[string]string{"TERM": "xterm-256color", "NO_COLOR": "1"}, termenv.Ascii},
+		{"CLICOLOR_FORCE", ColorAuto, false, map[string]string{"TERM": "xterm-256color", "CLICOLOR_FORCE": "1"}, termenv.ANSI256},
+		{"CLICOLOR_FORCE=0", ColorAuto, false, map[string]string{"CLICOLOR_FORCE": "0"}, termenv.Ascii},
+		{"always in a pipe", ColorAlways, false, truecolor, termenv.TrueColor},
+		{"always without TERM", ColorAlways, false, nil, termenv.ANSI},
+		{"always overrides NO_COLOR", ColorAlways, false, map[string]string{"NO_COLOR": "1"}, termenv.ANSI},
+		{"never", ColorNever, true, truecolor, termenv.Ascii},
+		{"forced 16", Color16, false, truecolor, termenv.ANSI},
+		{"forced 256", Color256, false, nil, termenv.ANSI256},
+		{"forced truecolor", ColorTrueColor, false, map[string]string{"NO_COLOR": "1"}, termenv.TrueColor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			if got := ColorProfile(tt.mode, tt.terminal, getenv); got != tt.want {
+				t.Errorf("ColorProfile(%q, %v) = %v, want %v", tt.mode, tt.terminal, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestColorDownsampling tests that hex colors are rendered at the profile's
+// depth
+func TestColorDownsampling(t *testing.T) {
+	defer lipgloss.SetColorProfile(lipgloss.ColorProfile())
+
+	c := NewCanvas(2, 1)
+	c.DrawString(0, 0, "Hi", lipgloss.NewStyle().Foreground(ParseColor("#ff6b6b")))
+
+	tests := []struct {
+		profile termenv.Profile
+		want    string
+	}{
+		{termenv.TrueColor, "38;2;255;107;107"},
+		{termenv.ANSI256, "38;5;"},
+		{termenv.ANSI, "\x1b[9"},
+		{termenv.Ascii, "Hi"},
+	}
+
+	for _, tt := range tests {
+		lipgloss.SetColorProfile(tt.profile)
+		got := c.Render()[0]
+		if !strings.Contains(got, tt.want) {
+			t.Errorf("Profile %v rendered %q, want it to contain %q", tt.profile, got, tt.want)
+		}
+		if tt.profile == termenv.Ascii && got != "Hi" {
+			t.Errorf("Profile %v rendered %q, want no styling", tt.profile, got)
+		}
+	}
+}
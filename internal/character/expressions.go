@@ -0,0 +1,88 @@
+package character
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/MagikIO/familiar-says/internal/ascii"
+	"github.com/MagikIO/familiar-says/internal/canvas"
+	"github.com/MagikIO/familiar-says/internal/personality"
+)
+
+// HasMood reports whether the character or the theme defines an expression
+// for mood. char may be nil.
+func HasMood(char *canvas.Character, theme personality.Theme, mood personality.Mood) bool {
+	if _, ok := theme.Expressions[mood]; ok {
+		return true
+	}
+	if char != nil {
+		if _, ok := char.Expressions[string(mood)]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveExpression returns the eyes and tongue to show for mood. A
+// character's own expressions win over the theme's, field by field. Moods
+// that neither defines fall back to neutral. In ASCII mode the expression
+// is transliterated. char may be nil.
+func ResolveExpression(char *canvas.Character, theme personality.Theme, mood personality.Mood) personality.Expression {
+	if !HasMood(char, theme, mood) {
+		mood = personality.MoodNeutral
+	}
+
+	expr := theme.GetExpression(mood)
+	if char != nil {
+		// A mood only the character defines builds on its neutral expression
+		if _, ok := theme.Expressions[mood]; !ok {
+			expr = overlayExpression(expr, char.Expressions[string(personality.MoodNeutral)])
+		}
+		expr = overlayExpression(expr, char.Expressions[string(mood)])
+	}
+
+	expr.Eyes = ascii.Apply(expr.Eyes)
+	expr.Tongue = ascii.Apply(expr.Tongue)
+	return expr
+}
+
+// overlayExpression returns expr with the non-empty fields of override applied.
+func overlayExpression(expr personality.Expression, override canvas.Expression) personality.Expression {
+	if override.Eyes != "" {
+		expr.Eyes = override.Eyes
+	}
+	if override.Tongue != "" {
+		expr.Tongue = override.Tongue
+	}
+	return expr
+}
+
+// AvailableMoods returns every mood the character or theme defines: the
+// standard moods first, followed by custom moods in alphabetical order.
+// char may be nil.
+func AvailableMoods(char *canvas.Character, theme personality.Theme) []personality.Mood {
+	var moods []personality.Mood
+	for _, mood := range personality.AllMoods() {
+		if HasMood(char, theme, mood) {
+			moods = append(moods, mood)
+		}
+	}
+
+	var custom []personality.Mood
+	addCustom := func(mood personality.Mood) {
+		if !slices.Contains(moods, mood) && !slices.Contains(custom, mood) {
+			custom = append(custom, mood)
+		}
+	}
+	for mood := range theme.Expressions {
+		addCustom(mood)
+	}
+	if char != nil {
+		for mood := range char.Expressions {
+			addCustom(personality.Mood(mood))
+		}
+	}
+	sort.Slice(custom, func(i, j int) bool { return custom[i] < custom[j] })
+
+	return append(moods, custom...)
+}
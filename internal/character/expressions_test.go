@@ -0,0 +1,114 @@
+package character
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/MagikIO/familiar-says/internal/ascii"
+	"github.com/MagikIO/familiar-says/internal/bubble"
+	"github.com/MagikIO/familiar-says/internal/canvas"
+	"github.com/MagikIO/familiar-says/internal/personality"
+)
+
+// testExpressionCharacter returns a character with its own expressions.
+func testExpressionCharacter() *canvas.Character {
+	return &canvas.Character{
+		Name: "critter",
+		Art:  []string{"(@@)"},
+		Eyes: &canvas.Slot{Line: 0, Col: 1, Width: 2, Placeholder: "@@"},
+		Expressions: map[string]canvas.Expression{
+			"neutral": {Tongue: "v"},
+			"happy":   {Eyes: "^.^"},
+			"wise":    {Eyes: "=="},
+		},
+	}
+}
+
+// TestResolveExpression tests character > theme > neutral precedence
+func TestResolveExpression(t *testing.T) {
+	char := testExpressionCharacter()
+	theme := personality.ThemeDefault
+	neutral := theme.Expressions[personality.MoodNeutral]
+
+	tests := []struct {
+		name string
+		char *canvas.Character
+		mood personality.Mood
+		want personality.Expression
+	}{
+		{"character overrides theme eyes", char, personality.MoodHappy, personality.Expression{Eyes: "^.^", Tongue: theme.Expressions[personality.MoodHappy].Tongue}},
+		{"theme mood without character override", char, personality.MoodSad, theme.Expressions[personality.MoodSad]},
+		{"character-only mood builds on character neutral", char, "wise", personality.Expression{Eyes: "==", Tongue: "v"}},
+		{"character neutral overrides theme neutral", char, personality.MoodNeutral, personality.Expression{Eyes: neutral.Eyes, Tongue: "v"}},
+		{"unknown mood falls back to neutral", char, "bogus", personality.Expression{Eyes: neutral.Eyes, Tongue: "v"}},
+		{"nil character uses theme", nil, personality.MoodHappy, theme.Expressions[personality.MoodHappy]},
+		{"nil character unknown mood", nil, "wise", neutral},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ResolveExpression(tt.char, theme, tt.mood)
+			if got != tt.want {
+				t.Errorf("ResolveExpression(%s) = %+v, want %+v", tt.mood, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestResolveExpressionASCII tests transliterating expressions in ASCII mode
+func TestResolveExpressionASCII(t *testing.T) {
+	defer ascii.SetEnabled(ascii.Enabled())
+	ascii.SetEnabled(true)
+
+	got := ResolveExpression(nil, personality.ThemeRainbow, personality.MoodHappy)
+	if got.Eyes != "o_o" {
+		t.Errorf("Rainbow happy eyes = %q, want %q", got.Eyes, "o_o")
+	}
+}
+
+// TestHasMood tests mood lookup across character and theme
+func TestHasMood(t *testing.T) {
+	char := testExpressionCharacter()
+	theme := personality.ThemeDefault
+
+	if !HasMood(char, theme, "wise") {
+		t.Error("Expected character mood 'wise' to be available")
+	}
+	if !HasMood(nil, theme, personality.MoodSleepy) {
+		t.Error("Expected theme mood 'sleepy' to be available")
+	}
+	if HasMood(nil, theme, "wise") {
+		t.Error("Expected 'wise' to be unavailable without the character")
+	}
+}
+
+// TestAvailableMoods tests that custom moods follow the standard ones
+func TestAvailableMoods(t *testing.T) {
+	theme := personality.ThemeDefault
+	theme.Expressions = map[personality.Mood]personality.Expression{
+		personality.MoodNeutral: {Eyes: "oo"},
+		personality.MoodHappy:   {Eyes: "^^"},
+		"zen":                   {Eyes: "--"},
+	}
+
+	got := AvailableMoods(testExpressionCharacter(), theme)
+	want := []personality.Mood{personality.MoodNeutral, personality.MoodHappy, "wise", "zen"}
+	if !slices.Equal(got, want) {
+		t.Errorf("AvailableMoods() = %v, want %v", got, want)
+	}
+}
+
+// TestRendererUsesCharacterExpressions tests that rendering applies character expressions
+func TestRendererUsesCharacterExpressions(t *testing.T) {
+	owl, err := LoadCharacter("owl")
+	if err != nil {
+		t.Fatalf("LoadCharacter(owl) failed: %v", err)
+	}
+
+	r := NewRenderer(personality.ThemeDefault, "wise", 20)
+	plain := strings.Join(r.Compose("Hoot", owl, bubble.StyleSay, canvas.TailDown).RenderPlain(), "\n")
+	if !strings.Contains(plain, "(==)") {
+		t.Errorf("Expected owl with wise eyes (==), got:\n%s", plain)
+	}
+}
@@ -2,7 +2,6 @@
 package character
 
 import (
-	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -11,6 +10,7 @@ import (
 	"github.com/MagikIO/familiar-says/internal/canvas"
 	customerrors "github.com/MagikIO/familiar-says/internal/errors"
 	"github.com/MagikIO/familiar-says/internal/personality"
+	"github.com/MagikIO/familiar-says/internal/textwrap"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -21,6 +21,12 @@ type Renderer struct {
 	BubbleWidth    int
 	CharColors     *canvas.CharacterColors // Optional per-part color overrides
 	CustomTemplate string                  // Optional custom bubble template name/path
+	CodeLanguage   string                  // Optional language for code bubbles (detected if empty)
+	CodeStyle      string                  // Optional syntax highlighting style for code bubbles
+	Align          textwrap.Alignment      // Optional text alignment (the bubble template's if empty)
+	Markdown       bool                    // Render inline Markdown in bubble text
+	MaxLines       int                     // Optional lines of bubble text before it overflows
+	Overflow       string                  // Optional overflow mode for text past MaxLines (see canvas.Overflows)
 }
 
 // NewRenderer creates a new character renderer.
@@ -42,13 +48,33 @@ func (r *Renderer) Render(text string, char *canvas.Character, style bubble.Styl
 
 // RenderWithTailDirection renders a character with a speech bubble and custom tail direction.
 func (r *Renderer) RenderWithTailDirection(text string, char *canvas.Character, style bubble.Style, tailDir canvas.TailDirection) []string {
+	return r.Compose(text, char, style, tailDir).Render()
+}
+
+// Compose builds the canvas for a character with a speech bubble without
+// rendering it to strings.
+func (r *Renderer) Compose(text string, char *canvas.Character, style bubble.Style, tailDir canvas.TailDirection) *canvas.Canvas {
+	result, _ := r.ComposeScene(text, char, style, tailDir)
+	return result
+}
+
+// SceneInfo describes a character as composed into a scene.
+type SceneInfo struct {
+	Character string             // Character name
+	Mood      personality.Mood   // Mood shown, after falling back to neutral
+	Template  string             // Bubble style or custom template name
+	Layout    canvas.SceneLayout // Where the scene's parts were placed
+}
+
+// ComposeScene is like Compose, but also describes what was composed.
+func (r *Renderer) ComposeScene(text string, char *canvas.Character, style bubble.Style, tailDir canvas.TailDirection) (*canvas.Canvas, SceneInfo) {
 	// Get expression for mood
-	expr := r.Theme.GetExpression(r.Mood)
+	expr := r.Expression(char)
 
 	// Configure the compositor
 	config := canvas.CompositorConfig{
 		BubbleWidth:    r.BubbleWidth,
-		BubbleStyle:    bubbleStyleToCanvasStyle(style),
+		BubbleStyle:    BubbleStyleToCanvasStyle(style),
 		Layout:         canvas.LayoutVertical,
 		BubbleColor:    r.Theme.BubbleStyle,
 		CharColor:      r.Theme.CharacterStyle,
@@ -56,16 +82,39 @@ func (r *Renderer) RenderWithTailDirection(text string, char *canvas.Character,
 		ConnectorLen:   2,
 		TailDirection:  tailDir,
 		CustomTemplate: r.CustomTemplate,
+		CodeLanguage:   r.CodeLanguage,
+		CodeStyle:      r.CodeStyle,
+		Align:          r.Align,
+		Markdown:       r.Markdown,
+		MaxLines:       r.MaxLines,
+		Overflow:       r.Overflow,
 	}
 
 	// Compose the output
-	result := canvas.Compose(text, char, expr.Eyes, expr.Tongue, config)
+	result, layout := canvas.ComposeLayout(text, char, expr.Eyes, expr.Tongue, config)
+	return result, r.sceneInfo(char, r.Mood, style, layout)
+}
 
-	return result.Render()
+// sceneInfo describes char composed in mood with a bubble of style.
+func (r *Renderer) sceneInfo(char *canvas.Character, mood personality.Mood, style bubble.Style, layout canvas.SceneLayout) SceneInfo {
+	if !HasMood(char, r.Theme, mood) {
+		mood = personality.MoodNeutral
+	}
+	template := style.String()
+	if r.CustomTemplate != "" {
+		template = r.CustomTemplate
+	}
+	return SceneInfo{Character: char.Name, Mood: mood, Template: template, Layout: layout}
 }
 
-// bubbleStyleToCanvasStyle converts bubble.Style to canvas.BubbleStyle
-func bubbleStyleToCanvasStyle(s bubble.Style) canvas.BubbleStyle {
+// Expression returns the expression for the renderer's mood, preferring the
+// character's own expressions over the theme's.
+func (r *Renderer) Expression(char *canvas.Character) personality.Expression {
+	return ResolveExpression(char, r.Theme, r.Mood)
+}
+
+// BubbleStyleToCanvasStyle converts bubble.Style to canvas.BubbleStyle
+func BubbleStyleToCanvasStyle(s bubble.Style) canvas.BubbleStyle {
 	switch s {
 	case bubble.StyleThink:
 		return canvas.BubbleStyleThink
@@ -97,18 +146,17 @@ func (r *Renderer) RenderByName(text string, name string, style bubble.Style) ([
 	return r.Render(text, char, style), nil
 }
 
-// LoadCharacter loads a character by name. It first tries to load from JSON files
-// (which may have animations), then falls back to builtin characters.
+// LoadCharacter loads a character by name or file path. Names are looked up
+// on the character search path (see Sources), highest priority first.
 func LoadCharacter(name string) (*canvas.Character, error) {
-	// Normalize the name
-	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.TrimSpace(name)
 
 	if name == "" {
 		return nil, customerrors.NewValidationError("character name", name, "cannot be empty")
 	}
 
 	// Check if it's a file path
-	if strings.HasSuffix(name, ".json") {
+	if isCharacterFile(name) {
 		char, err := canvas.LoadCharacter(name)
 		if err != nil {
 			return nil, customerrors.NewCharacterLoadError(name, err)
@@ -116,61 +164,93 @@ func LoadCharacter(name string) (*canvas.Character, error) {
 		return char, nil
 	}
 
-	// Try to find a JSON file first (these have animations)
-	searchPaths := []string{
-		name + ".json",
-		"characters/" + name + ".json",
-		filepath.Join("characters", name+".json"),
-	}
-
-	for _, path := range searchPaths {
-		char, err := canvas.LoadCharacter(path)
-		if err == nil {
-			return char, nil
-		}
-	}
+	// Normalize the name
+	name = strings.ToLower(name)
 
-	// Fall back to builtin character (no animations)
-	if char, ok := canvas.GetBuiltinCharacter(name); ok {
-		return char, nil
+	char, _, attempted, err := findInSources(name, Sources())
+	if err != nil {
+		return nil, customerrors.NewCharacterLoadError(name, err, attempted...)
 	}
+	return char, nil
+}
 
-	return nil, customerrors.NewCharacterLoadError(name, errors.New("character not found in any standard location"))
+// isCharacterFile reports whether name refers to a character file rather
+// than a name on the search path.
+func isCharacterFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".json" || ext == ".cow" || strings.ContainsRune(name, filepath.Separator)
 }
 
-// ListCharacters returns all available character names.
+// ListCharacters returns all available character names across the search path.
 func ListCharacters() []string {
-	return canvas.ListBuiltinCharacters()
+	entries := ListEntries()
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name
+	}
+	return names
 }
 
-// RenderMultiPanel renders multiple characters side by side.
+// RenderMultiPanel renders multiple characters side by side. Characters that
+// cannot be loaded are replaced with the default character.
 func (r *Renderer) RenderMultiPanel(panels []Panel) []string {
 	if len(panels) == 0 {
 		return []string{}
 	}
 
-	// Get expression for mood
-	expr := r.Theme.GetExpression(r.Mood)
+	resolved := make([]Panel, len(panels))
+	for i, panel := range panels {
+		resolved[i] = panel
+		if panel.CharacterName == "" {
+			resolved[i].CharacterName = "default"
+		} else if _, err := LoadCharacter(panel.CharacterName); err != nil {
+			resolved[i].CharacterName = "default"
+		}
+	}
+
+	result, _ := r.ComposeMultiPanel(resolved)
+	return result.Render()
+}
+
+// ComposeMultiPanel builds the canvas for multiple characters side by side,
+// aligned at the bottom. Each panel may override the mood, bubble style and
+// character colors of the renderer.
+func (r *Renderer) ComposeMultiPanel(panels []Panel) (*canvas.Canvas, error) {
+	result, _, err := r.ComposeMultiPanelScenes(panels)
+	return result, err
+}
 
-	// Convert to canvas panel configs
+// ComposeMultiPanelScenes is like ComposeMultiPanel, but also describes the
+// scene of each panel.
+func (r *Renderer) ComposeMultiPanelScenes(panels []Panel) (*canvas.Canvas, []SceneInfo, error) {
 	canvasPanels := make([]canvas.PanelConfig, len(panels))
+	infos := make([]SceneInfo, len(panels))
 	for i, panel := range panels {
-		var char *canvas.Character
-		if panel.CharacterName != "" {
-			var err error
-			char, err = LoadCharacter(panel.CharacterName)
-			if err != nil {
-				char, _ = canvas.GetBuiltinCharacter("default")
-			}
-		} else {
-			char, _ = canvas.GetBuiltinCharacter("default")
+		name := panel.CharacterName
+		if name == "" {
+			name = "default"
+		}
+		char, err := LoadCharacter(name)
+		if err != nil {
+			return nil, nil, err
 		}
 
+		panelRenderer := *r
+		if panel.Mood != "" {
+			panelRenderer.Mood = panel.Mood
+		}
+		expr := panelRenderer.Expression(char)
+		// Panels always use the built-in bubble styles
+		panelRenderer.CustomTemplate = ""
+		infos[i] = panelRenderer.sceneInfo(char, panelRenderer.Mood, panel.Style, canvas.SceneLayout{})
+
 		canvasPanels[i] = canvas.PanelConfig{
-			Text:      panel.Text,
-			Character: char,
-			Eyes:      expr.Eyes,
-			Mouth:     expr.Tongue,
+			Text:        panel.Text,
+			Character:   char,
+			Eyes:        expr.Eyes,
+			Mouth:       expr.Tongue,
+			BubbleStyle: BubbleStyleToCanvasStyle(panel.Style),
+			CharColors:  panel.Colors,
 		}
 	}
 
@@ -183,10 +263,19 @@ func (r *Renderer) RenderMultiPanel(panels []Panel) []string {
 		CharColor:    r.Theme.CharacterStyle,
 		CharColors:   r.CharColors,
 		ConnectorLen: 2,
+		CodeLanguage: r.CodeLanguage,
+		CodeStyle:    r.CodeStyle,
+		Align:        r.Align,
+		Markdown:     r.Markdown,
+		MaxLines:     r.MaxLines,
+		Overflow:     r.Overflow,
 	}
 
-	result := canvas.ComposeMultiPanel(canvasPanels, config)
-	return result.Render()
+	result, layouts := canvas.ComposeMultiPanelLayout(canvasPanels, config)
+	for i := range infos {
+		infos[i].Layout = layouts[i]
+	}
+	return result, infos, nil
 }
 
 // Panel represents a single panel in a multi-panel layout.
@@ -194,11 +283,13 @@ type Panel struct {
 	Text          string
 	CharacterName string
 	Style         bubble.Style
+	Mood          personality.Mood        // Overrides the renderer's mood if set
+	Colors        *canvas.CharacterColors // Overrides the renderer's character colors if set
 }
 
 // RenderInfo displays information about the current theme and mood.
 func (r *Renderer) RenderInfo() string {
-	expr := r.Theme.GetExpression(r.Mood)
+	expr := r.Expression(nil)
 
 	info := fmt.Sprintf("Theme: %s | Mood: %s | Eyes: %s | Tongue: %s",
 		r.Theme.Name,
@@ -222,7 +313,7 @@ func GetCharacterPreview(name string, theme personality.Theme, mood personality.
 		return nil, fmt.Errorf("failed to generate character preview: %w", err)
 	}
 
-	expr := theme.GetExpression(mood)
+	expr := ResolveExpression(char, theme, mood)
 	charCanvas := char.ToCanvas(expr.Eyes, expr.Tongue, theme.CharacterStyle)
 	return charCanvas.Render(), nil
 }
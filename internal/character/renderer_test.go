@@ -550,3 +550,63 @@ func min(a, b int) int {
 	}
 	return b
 }
+
+// TestComposeMultiPanelOverrides tests per-panel moods and load errors
+func TestComposeMultiPanelOverrides(t *testing.T) {
+	r := NewRenderer(personality.ThemeDefault, personality.MoodNeutral, 20)
+
+	result, err := r.ComposeMultiPanel([]Panel{
+		{Text: "Hoot", CharacterName: "owl", Mood: "wise"},
+		{Text: "Hoot", CharacterName: "owl"},
+	})
+	if err != nil {
+		t.Fatalf("ComposeMultiPanel failed: %v", err)
+	}
+	content := strings.Join(result.RenderPlain(), "\n")
+	if !strings.Contains(content, "(==)") || !strings.Contains(content, "(oo)") {
+		t.Errorf("Expected one wise and one neutral owl, got:\n%s", content)
+	}
+
+	if _, err := r.ComposeMultiPanel([]Panel{{Text: "?", CharacterName: "no-such-familiar"}}); err == nil {
+		t.Error("Expected error for unknown character")
+	}
+}
+
+// TestComposeScene tests the description of composed scenes
+func TestComposeScene(t *testing.T) {
+	r := NewRenderer(personality.ThemeDefault, "no-such-mood", 20)
+	_, info := r.ComposeScene("Hi", defaultCharacter(t), bubble.StyleThink, canvas.TailDown)
+	if info.Character != "default" || info.Mood != personality.MoodNeutral || info.Template != "think" {
+		t.Errorf("ComposeScene info = %+v, want default, neutral, think", info)
+	}
+	if info.Layout.Bubble.Empty() || info.Layout.Character.Empty() {
+		t.Errorf("ComposeScene layout = %+v, want bubble and character", info.Layout)
+	}
+
+	_, infos, err := r.ComposeMultiPanelScenes([]Panel{
+		{Text: "Hoot", CharacterName: "owl", Mood: "wise"},
+		{Text: "Hi", CharacterName: "cat", Style: bubble.StyleShout},
+	})
+	if err != nil {
+		t.Fatalf("ComposeMultiPanelScenes failed: %v", err)
+	}
+	if infos[0].Character != "owl" || infos[0].Mood != "wise" {
+		t.Errorf("First panel info = %+v, want a wise owl", infos[0])
+	}
+	if infos[1].Character != "cat" || infos[1].Template != "shout" {
+		t.Errorf("Second panel info = %+v, want a shouting cat", infos[1])
+	}
+	if infos[1].Layout.Character.X <= infos[0].Layout.Character.X {
+		t.Error("Second panel should be to the right of the first")
+	}
+}
+
+// defaultCharacter loads the built-in default character.
+func defaultCharacter(t *testing.T) *canvas.Character {
+	t.Helper()
+	char, err := LoadCharacter("default")
+	if err != nil {
+		t.Fatalf("LoadCharacter(default) failed: %v", err)
+	}
+	return char
+}
@@ -0,0 +1,235 @@
+package character
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/MagikIO/familiar-says/internal/canvas"
+	"github.com/MagikIO/familiar-says/internal/config"
+	customerrors "github.com/MagikIO/familiar-says/internal/errors"
+)
+
+// PathEnvVar names the environment variable holding extra character
+// directories, separated like $PATH. Directories listed first take precedence.
+const PathEnvVar = "FAMILIAR_SAYS_PATH"
+
+// characterExtensions lists the supported character file extensions, in the
+// order they are tried when a source defines a name more than once.
+var characterExtensions = []string{".json", ".cow"}
+
+// projectDirName is the directory that holds project-local familiar-says files.
+const projectDirName = ".familiar"
+
+// Source is a filesystem that character definitions are loaded from.
+type Source struct {
+	Name string // Kind of source: builtin, user, project or path
+	Dir  string // Directory on disk (empty for the embedded builtins)
+	FS   fs.FS
+}
+
+// String returns a human-readable description of the source.
+func (s Source) String() string {
+	if s.Dir == "" {
+		return s.Name
+	}
+	return s.Name + ": " + s.Dir
+}
+
+// Entry describes a character found on the search path.
+type Entry struct {
+	Name    string
+	Source  Source   // Source the character is loaded from
+	Shadows []Source // Lower-priority sources that define the same character
+}
+
+// Sources returns the character search path, ordered from lowest to highest
+// priority: the embedded builtins, the user directory
+// ($XDG_CONFIG_HOME/familiar-says/characters), the nearest project-local
+// .familiar/characters directory, and the directories in FAMILIAR_SAYS_PATH.
+// Directories that do not exist are left out.
+func Sources() []Source {
+	sources := []Source{{Name: "builtin", FS: canvas.BuiltinFS()}}
+
+	if dir, err := config.Dir(); err == nil {
+		sources = appendDirSource(sources, "user", filepath.Join(dir, "characters"))
+	}
+
+	if dir, ok := findProjectDir(); ok {
+		sources = appendDirSource(sources, "project", filepath.Join(dir, "characters"))
+	}
+
+	// Earlier FAMILIAR_SAYS_PATH entries win, so they go last in the stack
+	pathDirs := filepath.SplitList(os.Getenv(PathEnvVar))
+	for i := len(pathDirs) - 1; i >= 0; i-- {
+		if pathDirs[i] != "" {
+			sources = appendDirSource(sources, "path", pathDirs[i])
+		}
+	}
+
+	return sources
+}
+
+// appendDirSource appends a source for dir if it is an existing directory.
+func appendDirSource(sources []Source, name, dir string) []Source {
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		return sources
+	}
+	return append(sources, Source{Name: name, Dir: dir, FS: os.DirFS(dir)})
+}
+
+// findProjectDir searches for a .familiar directory by walking up from cwd.
+func findProjectDir() (string, bool) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+
+	for {
+		candidate := filepath.Join(dir, projectDirName)
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return candidate, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// findInSources loads the named character from the highest-priority source
+// that defines it. It returns the source used and the paths that were tried.
+// File names are matched case-insensitively, as names are listed lowercased.
+func findInSources(name string, sources []Source) (*canvas.Character, Source, []string, error) {
+	var attempted []string
+	var lastErr error = customerrors.ErrCharacterNotFound
+
+	for i := len(sources) - 1; i >= 0; i-- {
+		src := sources[i]
+		for _, ext := range characterExtensions {
+			filename := name + ext
+			attempted = append(attempted, src.describe(filename))
+
+			filename, ok := findFile(src.FS, filename)
+			if !ok {
+				continue
+			}
+
+			var char *canvas.Character
+			var err error
+			if src.Dir == "" {
+				// Builtins go through the canvas cache
+				var ok bool
+				if char, ok = canvas.GetBuiltinCharacter(name); !ok {
+					err = customerrors.ErrCharacterNotFound
+				}
+			} else {
+				char, err = canvas.LoadCharacterFS(src.FS, filename)
+			}
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			return char, src, attempted, nil
+		}
+	}
+
+	return nil, Source{}, attempted, lastErr
+}
+
+// Exists reports whether a character file or a name on the search path
+// exists, without loading the character. Names are matched like
+// LoadCharacter matches them.
+func Exists(name string) bool {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return false
+	}
+	if isCharacterFile(name) {
+		info, err := os.Stat(name)
+		return err == nil && !info.IsDir()
+	}
+	name = strings.ToLower(name)
+	for _, src := range Sources() {
+		for _, ext := range characterExtensions {
+			if _, ok := findFile(src.FS, name+ext); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// findFile returns the name of the file in the root of fsys matching
+// filename regardless of case, preferring an exact match.
+func findFile(fsys fs.FS, filename string) (string, bool) {
+	if _, err := fs.Stat(fsys, filename); err == nil {
+		return filename, true
+	}
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return "", false
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.EqualFold(entry.Name(), filename) {
+			return entry.Name(), true
+		}
+	}
+	return "", false
+}
+
+// describe returns a display path for a file within the source.
+func (s Source) describe(filename string) string {
+	if s.Dir == "" {
+		return s.Name + ":" + filename
+	}
+	return filepath.Join(s.Dir, filename)
+}
+
+// ListEntries returns every character on the search path, sorted by name,
+// with the source each one is loaded from and the sources it shadows. Names
+// are lowercased, the way LoadCharacter looks them up.
+func ListEntries() []Entry {
+	sources := Sources()
+	found := make(map[string][]Source)
+
+	for _, src := range sources {
+		entries, err := fs.ReadDir(src.FS, ".")
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			ext := filepath.Ext(entry.Name())
+			if entry.IsDir() || !slices.Contains(characterExtensions, ext) {
+				continue
+			}
+			name := strings.ToLower(strings.TrimSuffix(entry.Name(), ext))
+			// A source defining both name.json and name.cow counts once
+			if srcs := found[name]; len(srcs) > 0 && srcs[len(srcs)-1].String() == src.String() {
+				continue
+			}
+			found[name] = append(found[name], src)
+		}
+	}
+
+	result := make([]Entry, 0, len(found))
+	for name, srcs := range found {
+		// srcs is ordered lowest to highest priority
+		winner := srcs[len(srcs)-1]
+		shadows := make([]Source, 0, len(srcs)-1)
+		for i := len(srcs) - 2; i >= 0; i-- {
+			shadows = append(shadows, srcs[i])
+		}
+		result = append(result, Entry{Name: name, Source: winner, Shadows: shadows})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
@@ -0,0 +1,224 @@
+package character
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeCharacter writes a minimal character JSON file into dir.
+func writeCharacter(t *testing.T, dir, name, description string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	charJSON := `{"name": "` + name + `", "description": "` + description + `", "art": ["  (oo)  "], "anchor": {"x": 3, "y": 0}}`
+	if err := os.WriteFile(filepath.Join(dir, name+".json"), []byte(charJSON), 0644); err != nil {
+		t.Fatalf("Failed to write character: %v", err)
+	}
+}
+
+// isolateSources points every search path layer at temporary directories.
+func isolateSources(t *testing.T) (userDir, projectDir string) {
+	t.Helper()
+	root := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "config"))
+	t.Setenv(PathEnvVar, "")
+	t.Chdir(root)
+	userDir = filepath.Join(root, "config", "familiar-says", "characters")
+	projectDir = filepath.Join(root, ".familiar", "characters")
+	return userDir, projectDir
+}
+
+// TestSourcesOrder tests the search path layering
+func TestSourcesOrder(t *testing.T) {
+	userDir, projectDir := isolateSources(t)
+	pathA := filepath.Join(t.TempDir(), "a")
+	pathB := filepath.Join(t.TempDir(), "b")
+	for _, dir := range []string{userDir, projectDir, pathA, pathB} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+	}
+	t.Setenv(PathEnvVar, pathA+string(os.PathListSeparator)+pathB)
+
+	sources := Sources()
+	var got []string
+	for _, src := range sources {
+		got = append(got, src.Name)
+	}
+	want := []string{"builtin", "user", "project", "path", "path"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("Sources = %v, want %v", got, want)
+	}
+
+	// The first FAMILIAR_SAYS_PATH entry has the highest priority
+	if sources[len(sources)-1].Dir != pathA {
+		t.Errorf("Highest priority source = %s, want %s", sources[len(sources)-1].Dir, pathA)
+	}
+}
+
+// TestSourcesSkipMissingDirs tests that absent directories are left out
+func TestSourcesSkipMissingDirs(t *testing.T) {
+	isolateSources(t)
+	t.Setenv(PathEnvVar, filepath.Join(t.TempDir(), "missing"))
+
+	sources := Sources()
+	if len(sources) != 1 || sources[0].Name != "builtin" {
+		t.Errorf("Sources = %v, want only builtin", sources)
+	}
+}
+
+// TestLoadCharacterShadowing tests that higher layers override lower ones
+func TestLoadCharacterShadowing(t *testing.T) {
+	userDir, projectDir := isolateSources(t)
+
+	writeCharacter(t, userDir, "cat", "user cat")
+	char, err := LoadCharacter("cat")
+	if err != nil {
+		t.Fatalf("LoadCharacter failed: %v", err)
+	}
+	if char.Description != "user cat" {
+		t.Errorf("Description = %q, want user cat", char.Description)
+	}
+
+	writeCharacter(t, projectDir, "cat", "project cat")
+	char, err = LoadCharacter("cat")
+	if err != nil {
+		t.Fatalf("LoadCharacter failed: %v", err)
+	}
+	if char.Description != "project cat" {
+		t.Errorf("Description = %q, want project cat", char.Description)
+	}
+
+	pathDir := t.TempDir()
+	writeCharacter(t, pathDir, "cat", "path cat")
+	t.Setenv(PathEnvVar, pathDir)
+	char, err = LoadCharacter("cat")
+	if err != nil {
+		t.Fatalf("LoadCharacter failed: %v", err)
+	}
+	if char.Description != "path cat" {
+		t.Errorf("Description = %q, want path cat", char.Description)
+	}
+}
+
+// TestLoadCharacterFromUserDir tests loading a character only found in a user source
+func TestLoadCharacterFromUserDir(t *testing.T) {
+	userDir, _ := isolateSources(t)
+	writeCharacter(t, userDir, "gecko", "a gecko")
+
+	char, err := LoadCharacter("gecko")
+	if err != nil {
+		t.Fatalf("LoadCharacter failed: %v", err)
+	}
+	if char.Name != "gecko" {
+		t.Errorf("Name = %q, want gecko", char.Name)
+	}
+}
+
+// TestLoadCharacterCowFromSource tests loading a .cow file by name from a source
+func TestLoadCharacterCowFromSource(t *testing.T) {
+	_, projectDir := isolateSources(t)
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	cow := "$the_cow = <<EOC;\n  $thoughts\n   ($eyes)\nEOC\n"
+	if err := os.WriteFile(filepath.Join(projectDir, "moo.cow"), []byte(cow), 0644); err != nil {
+		t.Fatalf("Failed to write character: %v", err)
+	}
+
+	char, err := LoadCharacter("moo")
+	if err != nil {
+		t.Fatalf("LoadCharacter failed: %v", err)
+	}
+	if char.Eyes == nil {
+		t.Error("Expected eyes slot from $eyes")
+	}
+
+	entries := ListEntries()
+	if !slices.ContainsFunc(entries, func(e Entry) bool { return e.Name == "moo" && e.Source.Name == "project" }) {
+		t.Errorf("ListEntries did not include moo from the project source: %+v", entries)
+	}
+}
+
+// TestLoadCharacterMixedCase tests that characters from mixed-case files are
+// listed and loaded by their lowercased name
+func TestLoadCharacterMixedCase(t *testing.T) {
+	userDir, _ := isolateSources(t)
+	writeCharacter(t, userDir, "MyCat", "my cat")
+
+	entries := ListEntries()
+	if !slices.ContainsFunc(entries, func(e Entry) bool { return e.Name == "mycat" }) {
+		t.Errorf("ListEntries did not include mycat: %+v", entries)
+	}
+
+	for _, name := range []string{"mycat", "MyCat"} {
+		char, err := LoadCharacter(name)
+		if err != nil {
+			t.Fatalf("LoadCharacter(%q) failed: %v", name, err)
+		}
+		if char.Description != "my cat" {
+			t.Errorf("LoadCharacter(%q) description = %q, want my cat", name, char.Description)
+		}
+	}
+}
+
+// TestListEntries tests merging and shadow reporting across sources
+func TestListEntries(t *testing.T) {
+	userDir, projectDir := isolateSources(t)
+	writeCharacter(t, userDir, "cat", "user cat")
+	writeCharacter(t, projectDir, "cat", "project cat")
+	writeCharacter(t, projectDir, "gecko", "a gecko")
+
+	entries := ListEntries()
+	byName := make(map[string]Entry)
+	for _, entry := range entries {
+		byName[entry.Name] = entry
+	}
+
+	cat, ok := byName["cat"]
+	if !ok {
+		t.Fatal("cat not listed")
+	}
+	if cat.Source.Name != "project" {
+		t.Errorf("cat source = %s, want project", cat.Source.Name)
+	}
+	if len(cat.Shadows) != 2 || cat.Shadows[0].Name != "user" || cat.Shadows[1].Name != "builtin" {
+		t.Errorf("cat shadows = %v, want [user builtin]", cat.Shadows)
+	}
+
+	if gecko, ok := byName["gecko"]; !ok || len(gecko.Shadows) != 0 {
+		t.Errorf("gecko entry = %+v, want unshadowed project entry", gecko)
+	}
+
+	if owl, ok := byName["owl"]; !ok || owl.Source.Name != "builtin" {
+		t.Errorf("owl entry = %+v, want builtin entry", owl)
+	}
+}
+
+// TestExists tests checking for characters without loading them
+func TestExists(t *testing.T) {
+	userDir, _ := isolateSources(t)
+	writeCharacter(t, userDir, "gecko", "a gecko")
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"cat", true},
+		{"Gecko", true},
+		{filepath.Join(userDir, "gecko.json"), true},
+		{"nonexistent", false},
+		{filepath.Join(userDir, "missing.json"), false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := Exists(tt.name); got != tt.want {
+			t.Errorf("Exists(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
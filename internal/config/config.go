@@ -19,6 +19,10 @@ type FlagConfig struct {
 	Think         *bool   `json:"think,omitempty"`          // Deprecated: use BubbleStyle instead
 	BubbleStyle   *string `json:"bubbleStyle,omitempty"`    // Bubble style: say, think, shout, whisper, song, code
 	TailDirection *string `json:"tailDirection,omitempty"`  // Tail direction: down, up, left, right
+	Align         *string `json:"align,omitempty"`          // Text alignment: left, center, right, justify
+	Markdown      *bool   `json:"markdown,omitempty"`       // Render inline Markdown in messages
+	MaxLines      *int    `json:"maxLines,omitempty"`       // Lines of bubble text before it overflows (0 = no limit)
+	Overflow      *string `json:"overflow,omitempty"`       // Overflow past maxLines: truncate, split, page
 	Multipanel    *bool   `json:"multipanel,omitempty"`
 	OutlineColor  *string `json:"outlineColor,omitempty"`
 	EyeColor      *string `json:"eyeColor,omitempty"`
@@ -26,6 +30,12 @@ type FlagConfig struct {
 	// Code bubble options
 	CodeLanguage  *string `json:"codeLanguage,omitempty"`   // Language for syntax highlighting
 	CodeStyle     *string `json:"codeStyle,omitempty"`      // Syntax highlighting theme
+	// Color mode: auto, always, never, 16, 256, truecolor
+	Color         *string `json:"color,omitempty"`
+	// Transliterate decorations to ASCII
+	ASCII         *bool   `json:"ascii,omitempty"`
+	// Hyphenate words broken across lines
+	Hyphenate     *bool   `json:"hyphenate,omitempty"`
 }
 
 // Helper functions to create pointer values
@@ -387,6 +387,76 @@ func TestLoadFromEnv(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "color mode",
+			envVars: map[string]string{
+				"FAMILIAR_SAYS_COLOR": "always",
+			},
+			validate: func(t *testing.T, cfg *FlagConfig) {
+				if cfg.Color == nil || *cfg.Color != "always" {
+					t.Errorf("Color = %v, want always", cfg.Color)
+				}
+			},
+		},
+		{
+			name: "ascii mode",
+			envVars: map[string]string{
+				"FAMILIAR_SAYS_ASCII": "yes",
+			},
+			validate: func(t *testing.T, cfg *FlagConfig) {
+				if cfg.ASCII == nil || !*cfg.ASCII {
+					t.Errorf("ASCII = %v, want true", cfg.ASCII)
+				}
+			},
+		},
+		{
+			name: "hyphenation",
+			envVars: map[string]string{
+				"FAMILIAR_SAYS_HYPHENATE": "1",
+			},
+			validate: func(t *testing.T, cfg *FlagConfig) {
+				if cfg.Hyphenate == nil || !*cfg.Hyphenate {
+					t.Errorf("Hyphenate = %v, want true", cfg.Hyphenate)
+				}
+			},
+		},
+		{
+			name: "alignment",
+			envVars: map[string]string{
+				"FAMILIAR_SAYS_ALIGN": "justify",
+			},
+			validate: func(t *testing.T, cfg *FlagConfig) {
+				if cfg.Align == nil || *cfg.Align != "justify" {
+					t.Errorf("Align = %v, want justify", cfg.Align)
+				}
+			},
+		},
+		{
+			name: "markdown",
+			envVars: map[string]string{
+				"FAMILIAR_SAYS_MARKDOWN": "true",
+			},
+			validate: func(t *testing.T, cfg *FlagConfig) {
+				if cfg.Markdown == nil || !*cfg.Markdown {
+					t.Errorf("Markdown = %v, want true", cfg.Markdown)
+				}
+			},
+		},
+		{
+			name: "overflow",
+			envVars: map[string]string{
+				"FAMILIAR_SAYS_MAX_LINES": "5",
+				"FAMILIAR_SAYS_OVERFLOW":  "split",
+			},
+			validate: func(t *testing.T, cfg *FlagConfig) {
+				if cfg.MaxLines == nil || *cfg.MaxLines != 5 {
+					t.Errorf("MaxLines = %v, want 5", cfg.MaxLines)
+				}
+				if cfg.Overflow == nil || *cfg.Overflow != "split" {
+					t.Errorf("Overflow = %v, want split", cfg.Overflow)
+				}
+			},
+		},
 		{
 			name: "invalid integer ignored",
 			envVars: map[string]string{
@@ -483,6 +553,13 @@ func clearEnv() {
 		"FAMILIAR_SAYS_OUTLINE_COLOR",
 		"FAMILIAR_SAYS_EYE_COLOR",
 		"FAMILIAR_SAYS_MOUTH_COLOR",
+		"FAMILIAR_SAYS_COLOR",
+		"FAMILIAR_SAYS_ASCII",
+		"FAMILIAR_SAYS_HYPHENATE",
+		"FAMILIAR_SAYS_ALIGN",
+		"FAMILIAR_SAYS_MARKDOWN",
+		"FAMILIAR_SAYS_MAX_LINES",
+		"FAMILIAR_SAYS_OVERFLOW",
 	}
 	for _, v := range envVars {
 		os.Unsetenv(v)
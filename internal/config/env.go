@@ -69,6 +69,42 @@ func LoadFromEnv() *FlagConfig {
 		cfg.MouthColor = stringPtr(val)
 	}
 
+	if val := os.Getenv("FAMILIAR_SAYS_COLOR"); val != "" {
+		cfg.Color = stringPtr(val)
+	}
+
+	if val := os.Getenv("FAMILIAR_SAYS_ASCII"); val != "" {
+		if b, ok := parseBool(val); ok {
+			cfg.ASCII = boolPtr(b)
+		}
+	}
+
+	if val := os.Getenv("FAMILIAR_SAYS_HYPHENATE"); val != "" {
+		if b, ok := parseBool(val); ok {
+			cfg.Hyphenate = boolPtr(b)
+		}
+	}
+
+	if val := os.Getenv("FAMILIAR_SAYS_ALIGN"); val != "" {
+		cfg.Align = stringPtr(val)
+	}
+
+	if val := os.Getenv("FAMILIAR_SAYS_MARKDOWN"); val != "" {
+		if b, ok := parseBool(val); ok {
+			cfg.Markdown = boolPtr(b)
+		}
+	}
+
+	if val := os.Getenv("FAMILIAR_SAYS_MAX_LINES"); val != "" {
+		if i, err := strconv.Atoi(val); err == nil {
+			cfg.MaxLines = intPtr(i)
+		}
+	}
+
+	if val := os.Getenv("FAMILIAR_SAYS_OVERFLOW"); val != "" {
+		cfg.Overflow = stringPtr(val)
+	}
+
 	return cfg
 }
 
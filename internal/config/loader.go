@@ -10,13 +10,26 @@ import (
 // ErrConfigNotFound is returned when the config file doesn't exist
 var ErrConfigNotFound = errors.New("config file not found")
 
+// Dir returns the familiar-says configuration directory:
+// $XDG_CONFIG_HOME/familiar-says, or ~/.config/familiar-says if unset
+func Dir() (string, error) {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "familiar-says"), nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".config", "familiar-says"), nil
+}
+
 // DefaultConfigPath returns the default config file path
 func DefaultConfigPath() (string, error) {
-	homeDir, err := os.UserHomeDir()
+	dir, err := Dir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(homeDir, ".config", "familiar-says", "config.json"), nil
+	return filepath.Join(dir, "config.json"), nil
 }
 
 // Load loads the configuration from the default location
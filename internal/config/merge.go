@@ -56,6 +56,18 @@ func Merge(base, override *FlagConfig) {
 	if override.TailDirection != nil {
 		base.TailDirection = override.TailDirection
 	}
+	if override.Align != nil {
+		base.Align = override.Align
+	}
+	if override.Markdown != nil {
+		base.Markdown = override.Markdown
+	}
+	if override.MaxLines != nil {
+		base.MaxLines = override.MaxLines
+	}
+	if override.Overflow != nil {
+		base.Overflow = override.Overflow
+	}
 	if override.Multipanel != nil {
 		base.Multipanel = override.Multipanel
 	}
@@ -74,6 +86,15 @@ func Merge(base, override *FlagConfig) {
 	if override.CodeStyle != nil {
 		base.CodeStyle = override.CodeStyle
 	}
+	if override.Color != nil {
+		base.Color = override.Color
+	}
+	if override.ASCII != nil {
+		base.ASCII = override.ASCII
+	}
+	if override.Hyphenate != nil {
+		base.Hyphenate = override.Hyphenate
+	}
 }
 
 // ApplyToFlags applies config values to cobra command flags
@@ -109,12 +130,21 @@ func ApplyToFlags(cfg *FlagConfig, cmd *cobra.Command) {
 	if cfg.TailDirection != nil && !flags.Changed("tail-direction") {
 		flags.Set("tail-direction", *cfg.TailDirection)
 	}
+	if cfg.Align != nil && !flags.Changed("align") {
+		flags.Set("align", *cfg.Align)
+	}
+	if cfg.Overflow != nil && !flags.Changed("overflow") {
+		flags.Set("overflow", *cfg.Overflow)
+	}
 	if cfg.CodeLanguage != nil && !flags.Changed("code-language") {
 		flags.Set("code-language", *cfg.CodeLanguage)
 	}
 	if cfg.CodeStyle != nil && !flags.Changed("code-style") {
 		flags.Set("code-style", *cfg.CodeStyle)
 	}
+	if cfg.Color != nil && !flags.Changed("color") {
+		flags.Set("color", *cfg.Color)
+	}
 
 	// Apply int flags
 	if cfg.Width != nil && !flags.Changed("width") {
@@ -123,6 +153,9 @@ func ApplyToFlags(cfg *FlagConfig, cmd *cobra.Command) {
 	if cfg.Speed != nil && !flags.Changed("speed") {
 		flags.Set("speed", intToString(*cfg.Speed))
 	}
+	if cfg.MaxLines != nil && !flags.Changed("max-lines") {
+		flags.Set("max-lines", intToString(*cfg.MaxLines))
+	}
 
 	// Apply bool flags
 	if cfg.Animate != nil && !flags.Changed("animate") {
@@ -134,6 +167,15 @@ func ApplyToFlags(cfg *FlagConfig, cmd *cobra.Command) {
 	if cfg.Multipanel != nil && !flags.Changed("multipanel") {
 		flags.Set("multipanel", boolToString(*cfg.Multipanel))
 	}
+	if cfg.ASCII != nil && !flags.Changed("ascii") {
+		flags.Set("ascii", boolToString(*cfg.ASCII))
+	}
+	if cfg.Hyphenate != nil && !flags.Changed("hyphenate") {
+		flags.Set("hyphenate", boolToString(*cfg.Hyphenate))
+	}
+	if cfg.Markdown != nil && !flags.Changed("markdown") {
+		flags.Set("markdown", boolToString(*cfg.Markdown))
+	}
 }
 
 // Helper functions for type conversion to string (for flags.Set)
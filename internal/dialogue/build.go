@@ -0,0 +1,107 @@
+package dialogue
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MagikIO/familiar-says/internal/animation"
+	"github.com/MagikIO/familiar-says/internal/canvas"
+	"github.com/MagikIO/familiar-says/internal/character"
+	"github.com/MagikIO/familiar-says/internal/personality"
+)
+
+// Options controls how a script is staged for playback.
+type Options struct {
+	Theme       personality.Theme
+	BubbleWidth int
+	BubbleStyle canvas.BubbleStyle
+	TypingSpeed time.Duration
+	LinePause   time.Duration
+	KeepBubbles bool
+	Idle        bool // Play each character's idle animation between actions
+}
+
+// Build loads the cast of a script and resolves each line's mood and action,
+// returning a configuration for animation.PlayDialogue. Moods and actions a
+// character does not support fall back to neutral and no action, with a
+// warning for each.
+func Build(script *Script, opts Options) (animation.DialogueConfig, []string, error) {
+	config := animation.DialogueConfig{
+		Title:       script.Title,
+		BubbleWidth: opts.BubbleWidth,
+		BubbleStyle: opts.BubbleStyle,
+		BubbleColor: opts.Theme.BubbleStyle,
+		CharColor:   opts.Theme.CharacterStyle,
+		TypingSpeed: opts.TypingSpeed,
+		LinePause:   opts.LinePause,
+		KeepBubbles: opts.KeepBubbles,
+	}
+
+	chars := make([]*canvas.Character, len(script.Cast))
+	for i, member := range script.Cast {
+		char, err := character.LoadCharacter(member.Character)
+		if err != nil {
+			return config, nil, fmt.Errorf("failed to load character %q for %s: %w", member.Character, member.Name, err)
+		}
+		chars[i] = char
+
+		expr := character.ResolveExpression(char, opts.Theme, personality.MoodNeutral)
+		castMember := animation.DialogueCastMember{
+			Character:    char,
+			DefaultEyes:  expr.Eyes,
+			DefaultMouth: expr.Tongue,
+		}
+		if opts.Idle {
+			castMember.Idle = idleAnimation(char)
+		}
+		config.Cast = append(config.Cast, castMember)
+	}
+
+	var warnings []string
+	for _, line := range script.Lines {
+		speaker := script.CastIndex(line.Speaker)
+		char := chars[speaker]
+
+		mood := personality.MoodNeutral
+		if line.Mood != "" {
+			mood = personality.Mood(line.Mood)
+			if !character.HasMood(char, opts.Theme, mood) {
+				warnings = append(warnings, fmt.Sprintf("line %d: mood %q is not defined for %s, using neutral", line.LineNo, line.Mood, line.Speaker))
+			}
+		}
+		expr := character.ResolveExpression(char, opts.Theme, mood)
+
+		var action *canvas.AnimationSequence
+		if line.Action != "" && line.Action != "none" {
+			action = char.GetAnimation(line.Action)
+			if action == nil {
+				warnings = append(warnings, fmt.Sprintf("line %d: character %q does not have animation %q", line.LineNo, char.Name, line.Action))
+			}
+		}
+
+		config.Lines = append(config.Lines, animation.DialogueLine{
+			Speaker: speaker,
+			Text:    line.Text,
+			Eyes:    expr.Eyes,
+			Mouth:   expr.Tongue,
+			Action:  action,
+		})
+	}
+
+	return config, warnings, nil
+}
+
+// idleAnimation returns the character's idle animation, falling back to
+// blink and then its default animation.
+func idleAnimation(char *canvas.Character) *canvas.AnimationSequence {
+	if anim := char.GetAnimation("idle"); anim != nil {
+		return anim
+	}
+	if anim := char.GetAnimation("blink"); anim != nil {
+		return anim
+	}
+	if char.DefaultAnimation != "" {
+		return char.GetAnimation(char.DefaultAnimation)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+// Package dialogue parses dialogue scripts for multi-character skits and
+// prepares them for playback.
+//
+// A script is a plain text file with one spoken line per row:
+//
+//	# Lines starting with # are comments
+//	@title First day at the guild
+//	@cast Whiskers = cat
+//	@cast Sage = owl
+//
+//	Whiskers [happy] (wave): Welcome aboard!
+//	Sage [wise]: Let me show you around.
+//	    Indented rows continue the previous line.
+//	Whiskers: Any questions?
+//
+// Each line is SPEAKER [MOOD] (ACTION): TEXT, where the mood and action are
+// optional. The @cast directive maps a speaker name to a character name or
+// file; speakers without one use their lowercased name as the character.
+// Characters appear on stage in the order they are cast or first speak.
+package dialogue
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Script is a parsed dialogue script.
+type Script struct {
+	Title string
+	Cast  []CastMember
+	Lines []Line
+}
+
+// CastMember maps a speaker name to the character that plays it.
+type CastMember struct {
+	Name      string
+	Character string
+}
+
+// Line is a single spoken line of a script.
+type Line struct {
+	Speaker string
+	Mood    string // Optional mood for the line
+	Action  string // Optional character animation played while speaking
+	Text    string
+	LineNo  int // Line number in the script file
+}
+
+// lineRegex matches SPEAKER [MOOD] (ACTION): TEXT.
+var lineRegex = regexp.MustCompile(`^([^\[\]():]+?)\s*(?:\[\s*([^\]]*?)\s*\])?\s*(?:\(\s*([^)]*?)\s*\))?\s*:\s*(.*)$`)
+
+// ParseFile reads and parses a dialogue script file.
+func ParseFile(path string) (*Script, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open script %q: %w", path, err)
+	}
+	defer f.Close()
+
+	script, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return script, nil
+}
+
+// Parse parses a dialogue script.
+func Parse(r io.Reader) (*Script, error) {
+	script := &Script{}
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+
+	for scanner.Scan() {
+		lineNo++
+		raw := strings.TrimRight(scanner.Text(), " \t\r")
+		trimmed := strings.TrimSpace(raw)
+
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+			continue
+
+		case raw[0] == ' ' || raw[0] == '\t':
+			// Indented rows continue the previous line
+			if len(script.Lines) == 0 {
+				return nil, fmt.Errorf("line %d: continuation without a preceding line", lineNo)
+			}
+			last := &script.Lines[len(script.Lines)-1]
+			last.Text = strings.TrimSpace(last.Text + " " + trimmed)
+
+		case strings.HasPrefix(trimmed, "@"):
+			if err := script.parseDirective(trimmed, lineNo); err != nil {
+				return nil, err
+			}
+
+		default:
+			m := lineRegex.FindStringSubmatch(trimmed)
+			if m == nil {
+				return nil, fmt.Errorf("line %d: expected \"SPEAKER [MOOD] (ACTION): TEXT\", got %q", lineNo, trimmed)
+			}
+			line := Line{
+				Speaker: strings.TrimSpace(m[1]),
+				Mood:    strings.ToLower(m[2]),
+				Action:  strings.ToLower(m[3]),
+				Text:    m[4],
+				LineNo:  lineNo,
+			}
+			script.addCast(line.Speaker, "")
+			script.Lines = append(script.Lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read script: %w", err)
+	}
+
+	if len(script.Lines) == 0 {
+		return nil, fmt.Errorf("script has no lines")
+	}
+	return script, nil
+}
+
+// parseDirective handles an @title or @cast row. The directive name ends at
+// the first space or tab.
+func (s *Script) parseDirective(row string, lineNo int) error {
+	name, value := row[1:], ""
+	if i := strings.IndexFunc(name, unicode.IsSpace); i >= 0 {
+		name, value = name[:i], strings.TrimSpace(name[i:])
+	}
+
+	switch strings.ToLower(name) {
+	case "title":
+		s.Title = value
+	case "cast":
+		speaker, char, ok := strings.Cut(value, "=")
+		speaker, char = strings.TrimSpace(speaker), strings.TrimSpace(char)
+		if !ok || speaker == "" || char == "" {
+			return fmt.Errorf("line %d: expected \"@cast SPEAKER = CHARACTER\", got %q", lineNo, row)
+		}
+		for _, member := range s.Cast {
+			if strings.EqualFold(member.Name, speaker) {
+				return fmt.Errorf("line %d: %s is already cast", lineNo, speaker)
+			}
+		}
+		s.addCast(speaker, char)
+	default:
+		return fmt.Errorf("line %d: unknown directive @%s", lineNo, name)
+	}
+	return nil
+}
+
+// addCast adds a speaker to the cast if not already present. An empty
+// character defaults to the lowercased speaker name.
+func (s *Script) addCast(speaker, char string) {
+	if s.CastIndex(speaker) >= 0 {
+		return
+	}
+	if char == "" {
+		char = strings.ToLower(speaker)
+	}
+	s.Cast = append(s.Cast, CastMember{Name: speaker, Character: char})
+}
+
+// CastIndex returns the position of the named speaker in the cast, or -1.
+// Speaker names are matched case-insensitively.
+func (s *Script) CastIndex(speaker string) int {
+	for i, member := range s.Cast {
+		if strings.EqualFold(member.Name, speaker) {
+			return i
+		}
+	}
+	return -1
+}
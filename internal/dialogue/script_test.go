@@ -0,0 +1,173 @@
+package dialogue
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MagikIO/familiar-says/internal/personality"
+)
+
+const sampleScript = `# A short skit
+@title First day
+@cast Whiskers = cat
+
+Whiskers [happy] (wave): Welcome aboard!
+Sage [Wise]: Let me show you
+    around the guild.
+Whiskers: Any questions?
+`
+
+// TestParse tests parsing a complete script
+func TestParse(t *testing.T) {
+	script, err := Parse(strings.NewReader(sampleScript))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if script.Title != "First day" {
+		t.Errorf("Title = %q, want %q", script.Title, "First day")
+	}
+
+	wantCast := []CastMember{{Name: "Whiskers", Character: "cat"}, {Name: "Sage", Character: "sage"}}
+	if len(script.Cast) != len(wantCast) {
+		t.Fatalf("Cast = %+v, want %+v", script.Cast, wantCast)
+	}
+	for i, member := range wantCast {
+		if script.Cast[i] != member {
+			t.Errorf("Cast[%d] = %+v, want %+v", i, script.Cast[i], member)
+		}
+	}
+
+	wantLines := []Line{
+		{Speaker: "Whiskers", Mood: "happy", Action: "wave", Text: "Welcome aboard!", LineNo: 5},
+		{Speaker: "Sage", Mood: "wise", Text: "Let me show you around the guild.", LineNo: 6},
+		{Speaker: "Whiskers", Text: "Any questions?", LineNo: 8},
+	}
+	if len(script.Lines) != len(wantLines) {
+		t.Fatalf("Got %d lines, want %d", len(script.Lines), len(wantLines))
+	}
+	for i, line := range wantLines {
+		if script.Lines[i] != line {
+			t.Errorf("Lines[%d] = %+v, want %+v", i, script.Lines[i], line)
+		}
+	}
+}
+
+// TestParseDirectiveWhitespace tests directives separated from their values
+// by tabs
+func TestParseDirectiveWhitespace(t *testing.T) {
+	script, err := Parse(strings.NewReader("@title\tFirst day\n@cast\tBob  =  cat\nBob: Hi\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if script.Title != "First day" {
+		t.Errorf("Title = %q, want %q", script.Title, "First day")
+	}
+	if want := (CastMember{Name: "Bob", Character: "cat"}); len(script.Cast) != 1 || script.Cast[0] != want {
+		t.Errorf("Cast = %+v, want [%+v]", script.Cast, want)
+	}
+}
+
+// TestParseLineForms tests the optional parts of a line
+func TestParseLineForms(t *testing.T) {
+	tests := []struct {
+		row  string
+		want Line
+	}{
+		{"Cat: Hi", Line{Speaker: "Cat", Text: "Hi"}},
+		{"Cat (jump): Hi", Line{Speaker: "Cat", Action: "jump", Text: "Hi"}},
+		{"Cat [sad]: Hi: there", Line{Speaker: "Cat", Mood: "sad", Text: "Hi: there"}},
+		{"Old Owl [ sleepy ] ( blink ):   Hoo", Line{Speaker: "Old Owl", Mood: "sleepy", Action: "blink", Text: "Hoo"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.row, func(t *testing.T) {
+			script, err := Parse(strings.NewReader(tt.row))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			tt.want.LineNo = 1
+			if script.Lines[0] != tt.want {
+				t.Errorf("Got %+v, want %+v", script.Lines[0], tt.want)
+			}
+		})
+	}
+}
+
+// TestParseErrors tests that malformed scripts report the offending line
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"empty", "# nothing here\n", "no lines"},
+		{"no colon", "Cat: Hi\nJust some text\n", "line 2"},
+		{"leading continuation", "  orphan\n", "line 1: continuation"},
+		{"bad cast", "@cast Cat\nCat: Hi\n", "line 1: expected"},
+		{"duplicate cast", "@cast Cat = cat\n@cast cat = owl\n", "line 2: cat is already cast"},
+		{"unknown directive", "@scene park\n", "unknown directive @scene"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.script))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// TestBuild tests resolving cast, moods and actions
+func TestBuild(t *testing.T) {
+	script, err := Parse(strings.NewReader(`@cast Sage = owl
+Sage [wise] (wave): Hoo
+Cat [grumpy] (moonwalk): Hmph
+cat [happy]: Fine`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	theme := personality.GetTheme("default")
+	config, warnings, err := Build(script, Options{Theme: theme, TypingSpeed: time.Millisecond, Idle: true})
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	if len(config.Cast) != 2 || config.Cast[0].Character.Name != "owl" || config.Cast[1].Character.Name != "cat" {
+		t.Fatalf("Unexpected cast: %+v", config.Cast)
+	}
+	if config.Cast[0].Idle == nil {
+		t.Error("Expected owl idle animation")
+	}
+
+	if config.Lines[0].Eyes != "==" {
+		t.Errorf("Owl wise eyes = %q, want ==", config.Lines[0].Eyes)
+	}
+	if config.Lines[0].Action == nil {
+		t.Error("Expected wave action for owl")
+	}
+	if config.Lines[2].Speaker != 1 {
+		t.Errorf("Speaker names should match case-insensitively, got speaker %d", config.Lines[2].Speaker)
+	}
+	if want := theme.GetExpression(personality.MoodNeutral).Eyes; config.Lines[1].Eyes != want {
+		t.Errorf("Unknown mood eyes = %q, want neutral %q", config.Lines[1].Eyes, want)
+	}
+
+	if len(warnings) != 2 || !strings.Contains(warnings[0], "grumpy") || !strings.Contains(warnings[1], "moonwalk") {
+		t.Errorf("Unexpected warnings: %v", warnings)
+	}
+}
+
+// TestBuildUnknownCharacter tests that a missing character is an error
+func TestBuildUnknownCharacter(t *testing.T) {
+	script, err := Parse(strings.NewReader("Nobody: Hello?"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if _, _, err := Build(script, Options{Theme: personality.GetTheme("default")}); err == nil {
+		t.Error("Expected error for unknown character")
+	}
+}
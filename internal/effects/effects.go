@@ -5,6 +5,8 @@ import (
 	"math/rand"
 	"time"
 
+	"github.com/MagikIO/familiar-says/internal/ansi"
+	"github.com/MagikIO/familiar-says/internal/ascii"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -40,9 +42,17 @@ func Apply(content []string, effect Effect) []string {
 	}
 }
 
+// glyphs returns the decorations of an effect, transliterated in ASCII mode.
+func glyphs(decorations ...string) []string {
+	for i, d := range decorations {
+		decorations[i] = ascii.Apply(d)
+	}
+	return decorations
+}
+
 // applyConfetti adds confetti characters around the content
 func applyConfetti(content []string) []string {
-	confetti := []string{"*", "·", "°", "•", "◦", "∘", "○"}
+	confetti := glyphs("*", "·", "°", "•", "◦", "∘", "○")
 	colors := []lipgloss.Color{"196", "226", "46", "51", "201", "208"}
 
 	result := []string{}
@@ -83,7 +93,7 @@ func applyConfetti(content []string) []string {
 
 // applyFireworks adds firework-like bursts
 func applyFireworks(content []string) []string {
-	fireworks := []string{"✦", "✧", "★", "☆", "✪", "✫", "✬", "✭", "✮", "✯"}
+	fireworks := glyphs("✦", "✧", "★", "☆", "✪", "✫", "✬", "✭", "✮", "✯")
 	colors := []lipgloss.Color{"196", "226", "201", "51"}
 
 	result := []string{}
@@ -127,7 +137,7 @@ func applyFireworks(content []string) []string {
 
 // applySparkle adds sparkle effects - adds sparkles to both sides of each line consistently
 func applySparkle(content []string) []string {
-	sparkles := []string{"✨", "⭐", "🌟", "💫"}
+	sparkles := glyphs("✨", "⭐", "🌟", "💫")
 
 	result := []string{}
 	for i, line := range content {
@@ -140,27 +150,6 @@ func applySparkle(content []string) []string {
 	return result
 }
 
-// stripAnsi removes ANSI escape codes from a string
-func stripAnsi(s string) string {
-	result := ""
-	inEscape := false
-	for _, r := range s {
-		if r == '\033' {
-			inEscape = true
-			continue
-		}
-		if inEscape {
-			// ANSI escape sequences end with a letter
-			if (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') {
-				inEscape = false
-			}
-			continue
-		}
-		result += string(r)
-	}
-	return result
-}
-
 // applyRainbow applies rainbow colors to the content
 func applyRainbow(content []string) []string {
 	colors := []lipgloss.Color{"196", "208", "226", "46", "51", "21", "93"}
@@ -170,7 +159,7 @@ func applyRainbow(content []string) []string {
 
 	for _, line := range content {
 		// Strip any existing ANSI codes before applying rainbow
-		cleanLine := stripAnsi(line)
+		cleanLine := ansi.Strip(line)
 		styledLine := ""
 		for _, char := range cleanLine {
 			if char != ' ' && char != '\t' {
@@ -242,7 +231,7 @@ func applyRainbowTextOnly(content []string) []string {
 
 	for _, line := range content {
 		// Strip any existing ANSI codes
-		cleanLine := stripAnsi(line)
+		cleanLine := ansi.Strip(line)
 		trimmed := trimSpaces(cleanLine) // trim both leading and trailing
 
 		// Detect bubble boundaries
@@ -1,7 +1,13 @@
 package effects
 
 import (
+	"strings"
 	"testing"
+
+	"github.com/MagikIO/familiar-says/internal/ansi"
+	"github.com/MagikIO/familiar-says/internal/ascii"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 )
 
 func TestAllEffects(t *testing.T) {
@@ -94,6 +100,21 @@ func TestApplySparkle(t *testing.T) {
 	}
 }
 
+func TestApplyASCII(t *testing.T) {
+	defer ascii.SetEnabled(ascii.Enabled())
+	ascii.SetEnabled(true)
+
+	for _, effect := range []Effect{EffectConfetti, EffectFireworks, EffectSparkle} {
+		for _, line := range Apply([]string{"test"}, effect) {
+			for _, r := range line {
+				if r >= 0x80 {
+					t.Fatalf("Effect %s output %q is not ASCII", effect, line)
+				}
+			}
+		}
+	}
+}
+
 func TestApplyRainbow(t *testing.T) {
 	content := []string{"test"}
 	result := Apply(content, EffectRainbow)
@@ -108,3 +129,43 @@ func TestApplyRainbow(t *testing.T) {
 		t.Error("Expected non-empty result from rainbow effect")
 	}
 }
+
+func TestApplyRainbowTextAligned(t *testing.T) {
+	defer lipgloss.SetColorProfile(lipgloss.ColorProfile())
+	lipgloss.SetColorProfile(termenv.ANSI256)
+
+	// A justified and a right-aligned bubble keep their layout
+	for _, content := range [][]string{
+		{" ___________ ", "/ one   two \\", "| three     |", "\\ four five /", " ----------- "},
+		{" ___________ ", "/   one two \\", "|     three |", "\\ four five /", " ----------- "},
+	} {
+		result := Apply(content, EffectRainbowText)
+		for i, line := range result {
+			if got := ansi.Strip(line); got != content[i] {
+				t.Errorf("Line %d = %q, want %q", i, got, content[i])
+			}
+		}
+		if !strings.HasPrefix(result[2], "| ") || !strings.Contains(result[2], "\x1b[") {
+			t.Errorf("Expected only the text colored, got %q", result[2])
+		}
+	}
+}
+
+func TestApplyColorProfile(t *testing.T) {
+	defer lipgloss.SetColorProfile(lipgloss.ColorProfile())
+	content := []string{"test"}
+
+	lipgloss.SetColorProfile(termenv.Ascii)
+	for _, effect := range []Effect{EffectConfetti, EffectFireworks, EffectRainbow, EffectRainbowText} {
+		if result := strings.Join(Apply(content, effect), "\n"); strings.Contains(result, "\x1b") {
+			t.Errorf("Expected %s to have no colors without a color profile, got %q", effect, result)
+		}
+	}
+
+	// 256 color palette entries are downsampled to the basic 16 colors
+	lipgloss.SetColorProfile(termenv.ANSI)
+	result := strings.Join(Apply(content, EffectRainbow), "\n")
+	if !strings.Contains(result, "\x1b[") || strings.Contains(result, "38;5;") {
+		t.Errorf("Expected 16 color output, got %q", result)
+	}
+}
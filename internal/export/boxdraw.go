@@ -0,0 +1,245 @@
+package export
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// Line weights of box drawing arms.
+const (
+	armNone = iota
+	armLight
+	armHeavy
+	armDouble
+)
+
+// boxArms gives the weight of the up, right, down and left arms of box
+// drawing characters. Drawing them instead of using font glyphs lets lines
+// reach the cell edges and join their neighbors.
+var boxArms = map[rune][4]int{
+	'─': {0, 1, 0, 1}, '━': {0, 2, 0, 2}, '│': {1, 0, 1, 0}, '┃': {2, 0, 2, 0},
+	'┌': {0, 1, 1, 0}, '┏': {0, 2, 2, 0}, '┐': {0, 0, 1, 1}, '┓': {0, 0, 2, 2},
+	'└': {1, 1, 0, 0}, '┗': {2, 2, 0, 0}, '┘': {1, 0, 0, 1}, '┛': {2, 0, 0, 2},
+	'├': {1, 1, 1, 0}, '┣': {2, 2, 2, 0}, '┤': {1, 0, 1, 1}, '┫': {2, 0, 2, 2},
+	'┬': {0, 1, 1, 1}, '┳': {0, 2, 2, 2}, '┴': {1, 1, 0, 1}, '┻': {2, 2, 0, 2},
+	'┼': {1, 1, 1, 1}, '╋': {2, 2, 2, 2},
+	'╴': {0, 0, 0, 1}, '╵': {1, 0, 0, 0}, '╶': {0, 1, 0, 0}, '╷': {0, 0, 1, 0},
+	'╸': {0, 0, 0, 2}, '╹': {2, 0, 0, 0}, '╺': {0, 2, 0, 0}, '╻': {0, 0, 2, 0},
+	'═': {0, 3, 0, 3}, '║': {3, 0, 3, 0},
+	'╔': {0, 3, 3, 0}, '╗': {0, 0, 3, 3}, '╚': {3, 3, 0, 0}, '╝': {3, 0, 0, 3},
+	'╠': {3, 3, 3, 0}, '╣': {3, 0, 3, 3}, '╦': {0, 3, 3, 3}, '╩': {3, 3, 0, 3}, '╬': {3, 3, 3, 3},
+	'╭': {0, 1, 1, 0}, '╮': {0, 0, 1, 1}, '╯': {1, 0, 0, 1}, '╰': {1, 1, 0, 0},
+}
+
+// boxDashes gives the dash count of dashed lines, which are otherwise drawn
+// like their solid counterparts.
+var boxDashes = map[rune]struct {
+	solid  rune
+	dashes int
+}{
+	'┄': {'─', 3}, '┅': {'━', 3}, '┆': {'│', 3}, '┇': {'┃', 3},
+	'┈': {'─', 4}, '┉': {'━', 4}, '┊': {'│', 4}, '┋': {'┃', 4},
+	'╌': {'─', 2}, '╍': {'━', 2}, '╎': {'│', 2}, '╏': {'┃', 2},
+}
+
+// shades gives the coverage of the shade characters.
+var shades = map[rune]float64{'░': 0.25, '▒': 0.5, '▓': 0.75}
+
+// drawBoxGlyph draws box drawing and block element characters to fill rect.
+// It reports false for other runes.
+func drawBoxGlyph(img *image.RGBA, rect image.Rectangle, r rune, src *image.Uniform, bold bool) bool {
+	if block, ok := blockRect(rect, r); ok {
+		draw.Draw(img, block, src, image.Point{}, draw.Over)
+		return true
+	}
+	if coverage, ok := shades[r]; ok {
+		c := src.C.(color.RGBA)
+		draw.Draw(img, rect, image.NewUniform(fade(c, coverage)), image.Point{}, draw.Over)
+		return true
+	}
+
+	if dash, ok := boxDashes[r]; ok {
+		box := image.NewRGBA(rect)
+		drawBoxGlyph(box, rect, dash.solid, src, bold)
+		horizontal := boxArms[dash.solid][1] != armNone
+		for i := 0; i < dash.dashes; i++ {
+			draw.Draw(img, dashRect(rect, i, dash.dashes, horizontal), box, dashRect(rect, i, dash.dashes, horizontal).Min, draw.Over)
+		}
+		return true
+	}
+
+	arms, ok := boxArms[r]
+	if !ok {
+		return false
+	}
+	if bold {
+		// Bold light lines are drawn heavy, as terminals do
+		for i, arm := range arms {
+			if arm == armLight {
+				arms[i] = armHeavy
+			}
+		}
+	}
+
+	switch r {
+	case '╭', '╮', '╯', '╰':
+		drawRoundedCorner(img, rect, arms, src)
+	default:
+		drawArms(img, rect, arms, src)
+	}
+	return true
+}
+
+// isBoxGlyph reports whether drawBoxGlyph draws r.
+func isBoxGlyph(r rune) bool {
+	_, block := blockRect(image.Rectangle{}, r)
+	_, shade := shades[r]
+	_, dash := boxDashes[r]
+	_, arms := boxArms[r]
+	return block || shade || dash || arms
+}
+
+// blockRect returns the filled part of block element characters.
+func blockRect(rect image.Rectangle, r rune) (image.Rectangle, bool) {
+	w, h := rect.Dx(), rect.Dy()
+	switch {
+	case r == '█':
+		return rect, true
+	case r == '▀':
+		return image.Rect(rect.Min.X, rect.Min.Y, rect.Max.X, rect.Min.Y+h/2), true
+	case r == '▐':
+		return image.Rect(rect.Min.X+w/2, rect.Min.Y, rect.Max.X, rect.Max.Y), true
+	case r >= '▁' && r <= '▇': // Lower eighths
+		eighths := int(r-'▁') + 1
+		return image.Rect(rect.Min.X, rect.Max.Y-h*eighths/8, rect.Max.X, rect.Max.Y), true
+	case r >= '▉' && r <= '▏': // Left eighths, from seven down to one
+		eighths := 7 - int(r-'▉')
+		return image.Rect(rect.Min.X, rect.Min.Y, rect.Min.X+w*eighths/8, rect.Max.Y), true
+	}
+	return image.Rectangle{}, false
+}
+
+// dashRect returns the part of rect covered by dash i of n.
+func dashRect(rect image.Rectangle, i, n int, horizontal bool) image.Rectangle {
+	if horizontal {
+		step := float64(rect.Dx()) / float64(n)
+		x0 := rect.Min.X + int(math.Round(step*float64(i)))
+		x1 := rect.Min.X + int(math.Round(step*(float64(i)+0.6)))
+		return image.Rect(x0, rect.Min.Y, max(x1, x0+1), rect.Max.Y)
+	}
+	step := float64(rect.Dy()) / float64(n)
+	y0 := rect.Min.Y + int(math.Round(step*float64(i)))
+	y1 := rect.Min.Y + int(math.Round(step*(float64(i)+0.6)))
+	return image.Rect(rect.Min.X, y0, rect.Max.X, max(y1, y0+1))
+}
+
+// drawArms draws straight box drawing arms from the cell center to its
+// edges. Double lines leave gaps where they meet so junctions read as in
+// a terminal.
+func drawArms(img *image.RGBA, rect image.Rectangle, arms [4]int, src image.Image) {
+	cx := rect.Min.X + rect.Dx()/2
+	cy := rect.Min.Y + rect.Dy()/2
+	up, right, down, left := arms[0], arms[1], arms[2], arms[3]
+	vertical := max(thickness(up), thickness(down))
+	horizontal := max(thickness(left), thickness(right))
+
+	fill := func(x0, y0, x1, y1 int) {
+		draw.Draw(img, image.Rect(x0, y0, x1, y1), src, image.Point{}, draw.Over)
+	}
+	// hline and vline draw single or heavy lines centered on the cell
+	hline := func(x0, x1, t int) { fill(x0, cy-t/2, x1, cy-t/2+t) }
+	vline := func(y0, y1, t int) { fill(cx-t/2, y0, cx-t/2+t, y1) }
+
+	// Double arms: two lines a pixel either side of the center, each
+	// stopping short of or running past the center depending on which
+	// perpendicular arms exist.
+	stop := func(near, far bool) int {
+		switch {
+		case near:
+			return 1
+		case far:
+			return -1
+		}
+		return 0
+	}
+	hasUp, hasRight, hasDown, hasLeft := up != armNone, right != armNone, down != armNone, left != armNone
+
+	if right == armDouble {
+		fill(cx+stop(hasUp, hasDown), cy-1, rect.Max.X, cy)
+		fill(cx+stop(hasDown, hasUp), cy+1, rect.Max.X, cy+2)
+	} else if right != armNone {
+		hline(cx-vertical/2, rect.Max.X, thickness(right))
+	}
+	if left == armDouble {
+		fill(rect.Min.X, cy-1, cx-stop(hasUp, hasDown)+1, cy)
+		fill(rect.Min.X, cy+1, cx-stop(hasDown, hasUp)+1, cy+2)
+	} else if left != armNone {
+		hline(rect.Min.X, cx-vertical/2+vertical, thickness(left))
+	}
+	if up == armDouble {
+		fill(cx-1, rect.Min.Y, cx, cy-stop(hasLeft, hasRight)+1)
+		fill(cx+1, rect.Min.Y, cx+2, cy-stop(hasRight, hasLeft)+1)
+	} else if up != armNone {
+		vline(rect.Min.Y, cy-horizontal/2+horizontal, thickness(up))
+	}
+	if down == armDouble {
+		fill(cx-1, cy+stop(hasLeft, hasRight), cx, rect.Max.Y)
+		fill(cx+1, cy+stop(hasRight, hasLeft), cx+2, rect.Max.Y)
+	} else if down != armNone {
+		vline(cy-horizontal/2, rect.Max.Y, thickness(down))
+	}
+}
+
+// thickness returns the pixel width of a single line arm.
+func thickness(arm int) int {
+	switch arm {
+	case armLight:
+		return 1
+	case armHeavy:
+		return 2
+	}
+	return 0
+}
+
+// drawRoundedCorner draws a rounded corner as a quarter circle joining its
+// two arms.
+func drawRoundedCorner(img *image.RGBA, rect image.Rectangle, arms [4]int, src image.Image) {
+	cx := rect.Min.X + rect.Dx()/2
+	cy := rect.Min.Y + rect.Dy()/2
+	t := thickness(max(arms[0], arms[1], arms[2], arms[3]))
+	radius := float64(rect.Dx() / 2)
+
+	// The circle's center lies diagonally inward from the cell center
+	dx, dy := 1.0, 1.0
+	if arms[3] != armNone {
+		dx = -1
+	}
+	if arms[0] != armNone {
+		dy = -1
+	}
+	ox, oy := float64(cx)+dx*radius, float64(cy)+dy*radius
+
+	fill := func(x0, y0, x1, y1 int) {
+		draw.Draw(img, image.Rect(x0, y0, x1, y1), src, image.Point{}, draw.Over)
+	}
+	for step := 0; step <= 32; step++ {
+		angle := float64(step) / 32 * math.Pi / 2
+		x := int(math.Round(ox - dx*radius*math.Cos(angle)))
+		y := int(math.Round(oy - dy*radius*math.Sin(angle)))
+		fill(x-t/2, y-t/2, x-t/2+t, y-t/2+t)
+	}
+
+	// Straight runs from the ends of the arc to the cell edges
+	if dy > 0 {
+		fill(cx-t/2, int(oy), cx-t/2+t, rect.Max.Y)
+	} else {
+		fill(cx-t/2, rect.Min.Y, cx-t/2+t, int(oy)+1)
+	}
+	if dx > 0 {
+		fill(int(ox), cy-t/2, rect.Max.X, cy-t/2+t)
+	} else {
+		fill(rect.Min.X, cy-t/2, int(ox)+1, cy-t/2+t)
+	}
+}
@@ -0,0 +1,111 @@
+package export
+
+import (
+	"io"
+	"strings"
+
+	"github.com/MagikIO/familiar-says/internal/canvas"
+)
+
+// ChatOptions controls Markdown and Slack output.
+type ChatOptions struct {
+	Emoji    bool // Replace unicode decorations such as ♪ and ✧ with emoji
+	SlackAPI bool // Escape &, < and > in Slack output for posting through Slack's API
+}
+
+// fence opens and closes code blocks on every supported platform. Longer
+// fences are valid CommonMark but Discord and Slack do not recognize them.
+const fence = "```"
+
+// zeroWidthSpace separates backticks so they cannot close a code block.
+const zeroWidthSpace = "\u200b"
+
+// chatEmoji maps decorations used by bubbles and effects to emoji. Emoji
+// are two cells wide, so a space after the decoration is dropped to keep
+// the art aligned.
+var chatEmoji = map[rune]string{
+	'♪': "🎵", '♫': "🎶", '♬': "🎶",
+	'★': "⭐", '☆': "⭐", '✪': "⭐", '✫': "⭐", '✬': "⭐", '✭': "⭐", '✮': "⭐", '✯': "⭐",
+	'✦': "✨", '✧': "✨",
+	'♥': "💖", '♡': "💖",
+	'☀': "🌞", '✿': "🌸", '❀': "🌸",
+}
+
+// slackEscaper escapes the characters Slack requires escaping in message
+// text sent through its API, which it would otherwise parse as links and
+// mentions. Text pasted into Slack is shown as typed and needs no escaping.
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Markdown renders a canvas as plain text in a fenced code block for
+// Markdown chat and documents, such as Discord and GitHub.
+func Markdown(c *canvas.Canvas, opts ChatOptions) []byte {
+	return []byte(codeBlock(c, opts, nil))
+}
+
+// WriteMarkdown renders a canvas as a Markdown code block written to w.
+func WriteMarkdown(w io.Writer, c *canvas.Canvas, opts ChatOptions) error {
+	_, err := io.WriteString(w, codeBlock(c, opts, nil))
+	return err
+}
+
+// Slack renders a canvas as plain text in a code block in Slack's mrkdwn
+// format, ready to paste. With opts.SlackAPI, &, < and > are escaped as
+// Slack's API requires.
+func Slack(c *canvas.Canvas, opts ChatOptions) []byte {
+	return []byte(codeBlock(c, opts, slackEscape(opts)))
+}
+
+// WriteSlack renders a canvas as a Slack code block written to w.
+func WriteSlack(w io.Writer, c *canvas.Canvas, opts ChatOptions) error {
+	_, err := io.WriteString(w, codeBlock(c, opts, slackEscape(opts)))
+	return err
+}
+
+// slackEscape returns the escaper of Slack output for opts, or nil if the
+// output is pasted as it is.
+func slackEscape(opts ChatOptions) *strings.Replacer {
+	if opts.SlackAPI {
+		return slackEscaper
+	}
+	return nil
+}
+
+// codeBlock returns the plain text of the canvas in a fenced code block.
+// Trailing spaces and empty rows, which chat clients trim anyway, are
+// removed, and runs of backticks are broken up so they cannot end the block.
+func codeBlock(c *canvas.Canvas, opts ChatOptions, escaper *strings.Replacer) string {
+	var b strings.Builder
+	b.WriteString(fence + "\n")
+	for _, line := range c.RenderPlain()[:contentRows(c)] {
+		line = strings.TrimRight(line, " ")
+		if opts.Emoji {
+			line = emojify(line)
+		}
+		line = strings.ReplaceAll(line, "``", "`"+zeroWidthSpace+"`")
+		line = strings.ReplaceAll(line, "``", "`"+zeroWidthSpace+"`") // Overlapping runs
+		if escaper != nil {
+			line = escaper.Replace(line)
+		}
+		b.WriteString(line + "\n")
+	}
+	b.WriteString(fence + "\n")
+	return b.String()
+}
+
+// emojify replaces decorations in line with emoji.
+func emojify(line string) string {
+	var b strings.Builder
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		emoji, ok := chatEmoji[runes[i]]
+		if !ok {
+			b.WriteRune(runes[i])
+			continue
+		}
+		b.WriteString(emoji)
+		if i+1 < len(runes) && runes[i+1] == ' ' {
+			i++
+		}
+	}
+	return b.String()
+}
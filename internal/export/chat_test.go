@@ -0,0 +1,55 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MagikIO/familiar-says/internal/canvas"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// TestChatOutput tests Markdown and Slack code blocks
+func TestChatOutput(t *testing.T) {
+	c := canvas.NewCanvas(12, 4)
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000"))
+	c.DrawString(0, 0, "♪ <Hi> ♪", style)
+	c.DrawString(2, 1, "a ```b``", style)
+
+	tests := []struct {
+		name string
+		got  []byte
+		want string
+	}{
+		{
+			"markdown",
+			Markdown(c, ChatOptions{}),
+			"```\n♪ <Hi> ♪\n  a `\u200b`\u200b`b`\u200b`\n```\n",
+		},
+		{
+			"slack",
+			Slack(c, ChatOptions{}),
+			"```\n♪ <Hi> ♪\n  a `\u200b`\u200b`b`\u200b`\n```\n",
+		},
+		{
+			"slack api",
+			Slack(c, ChatOptions{SlackAPI: true}),
+			"```\n♪ &lt;Hi&gt; ♪\n  a `\u200b`\u200b`b`\u200b`\n```\n",
+		},
+		{
+			"emoji",
+			Markdown(canvas.FromLines([]string{"♪ Hi ♪", "✧✧"}, style), ChatOptions{Emoji: true}),
+			"```\n🎵Hi 🎵\n✨✨\n```\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(tt.got); got != tt.want {
+				t.Errorf("Got:\n%q\nwant:\n%q", got, tt.want)
+			}
+			if strings.Contains(string(tt.got), "\x1b") {
+				t.Error("Output contains escape sequences")
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Package export renders composed canvases to formats other than the
+// terminal, such as SVG and PNG images and HTML.
+package export
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ansiPalette holds the xterm defaults for the 16 basic ANSI colors.
+var ansiPalette = [16]string{
+	"#000000", "#cd0000", "#00cd00", "#cdcd00", "#0000ee", "#cd00cd", "#00cdcd", "#e5e5e5",
+	"#7f7f7f", "#ff0000", "#00ff00", "#ffff00", "#5c5cff", "#ff00ff", "#00ffff", "#ffffff",
+}
+
+// ColorHex resolves a terminal color to a "#rrggbb" string using the xterm
+// palette for ANSI colors. It returns "" for unset colors, which take the
+// default foreground or background.
+func ColorHex(c lipgloss.TerminalColor) string {
+	switch c := c.(type) {
+	case lipgloss.Color:
+		return colorStringHex(string(c))
+	case lipgloss.ANSIColor:
+		return ansiHex(int(c))
+	case lipgloss.AdaptiveColor:
+		return colorStringHex(c.Dark)
+	case lipgloss.CompleteColor:
+		return colorStringHex(c.TrueColor)
+	case lipgloss.CompleteAdaptiveColor:
+		return colorStringHex(c.Dark.TrueColor)
+	}
+	return ""
+}
+
+// colorStringHex resolves a lipgloss color string: a hex code or an ANSI
+// color number.
+func colorStringHex(s string) string {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "#") {
+		hex := strings.ToLower(s)
+		if len(hex) == 4 {
+			hex = "#" + string([]byte{hex[1], hex[1], hex[2], hex[2], hex[3], hex[3]})
+		}
+		return hex
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		return ansiHex(n)
+	}
+	return ""
+}
+
+// ansiHex returns the xterm 256 color palette entry for n.
+func ansiHex(n int) string {
+	switch {
+	case n < 0 || n > 255:
+		return ""
+	case n < 16:
+		return ansiPalette[n]
+	case n < 232:
+		// 6x6x6 color cube
+		n -= 16
+		levels := [6]int{0, 95, 135, 175, 215, 255}
+		return fmt.Sprintf("#%02x%02x%02x", levels[n/36], levels[n/6%6], levels[n%6])
+	default:
+		// Grayscale ramp
+		v := 8 + (n-232)*10
+		return fmt.Sprintf("#%02x%02x%02x", v, v, v)
+	}
+}
+
+// cellColors returns the resolved foreground and background of a style,
+// applying defaults and reverse video.
+func cellColors(style lipgloss.Style, fg, bg string) (string, string) {
+	cellFg, cellBg := ColorHex(style.GetForeground()), ColorHex(style.GetBackground())
+	if cellFg == "" {
+		cellFg = fg
+	}
+	if style.GetReverse() {
+		if cellBg == "" {
+			cellBg = bg
+		}
+		cellFg, cellBg = cellBg, cellFg
+	}
+	return cellFg, cellBg
+}
@@ -0,0 +1,44 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// TestColorHex tests resolving terminal colors to hex codes
+func TestColorHex(t *testing.T) {
+	tests := []struct {
+		name  string
+		color lipgloss.TerminalColor
+		want  string
+	}{
+		{"no color", lipgloss.NoColor{}, ""},
+		{"hex", lipgloss.Color("#FF6B6B"), "#ff6b6b"},
+		{"short hex", lipgloss.Color("#f6b"), "#ff66bb"},
+		{"basic ansi", lipgloss.Color("1"), "#cd0000"},
+		{"bright ansi", lipgloss.ANSIColor(12), "#5c5cff"},
+		{"color cube", lipgloss.Color("196"), "#ff0000"},
+		{"grayscale", lipgloss.Color("244"), "#808080"},
+		{"out of range", lipgloss.Color("300"), ""},
+		{"adaptive", lipgloss.AdaptiveColor{Light: "#000000", Dark: "#ffffff"}, "#ffffff"},
+		{"complete", lipgloss.CompleteColor{TrueColor: "#123456", ANSI256: "1", ANSI: "1"}, "#123456"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ColorHex(tt.color); got != tt.want {
+				t.Errorf("ColorHex(%v) = %q, want %q", tt.color, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCellColorsReverse tests that reverse video swaps the colors
+func TestCellColorsReverse(t *testing.T) {
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000")).Reverse(true)
+	fg, bg := cellColors(style, "#eeeeee", "#111111")
+	if fg != "#111111" || bg != "#ff0000" {
+		t.Errorf("cellColors = %q, %q, want #111111, #ff0000", fg, bg)
+	}
+}
@@ -0,0 +1,143 @@
+package export
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+	"time"
+)
+
+// WriteGIF rasterizes the frames of an animation like PNG images and writes
+// them to w as an animated GIF. Consecutive identical frames are merged and
+// later frames only store the area that changed. The GIF repeats forever if
+// loop is set and otherwise plays once, stopping on its last frame.
+func WriteGIF(w io.Writer, frames []Frame, loop bool, opts PNGOptions) error {
+	if len(frames) == 0 {
+		return errors.New("no frames to write")
+	}
+	opts = opts.withDefaults()
+	scale := opts.Scale
+	opts.Scale = 1 // Scaled after merging, which is much cheaper
+
+	images := make([]*image.RGBA, len(frames))
+	var bounds image.Rectangle
+	for i, frame := range frames {
+		images[i] = Image(frame.Canvas, opts)
+		bounds = bounds.Union(images[i].Bounds())
+	}
+
+	// Frames may differ in size, e.g. while typing: grow them all to the
+	// largest, filled with the background
+	bg := images[0].RGBAAt(0, 0)
+	for i, img := range images {
+		if img.Bounds() != bounds {
+			grown := image.NewRGBA(bounds)
+			draw.Draw(grown, bounds, image.NewUniform(bg), image.Point{}, draw.Src)
+			draw.Draw(grown, img.Bounds(), img, image.Point{}, draw.Src)
+			images[i] = grown
+		}
+	}
+
+	// Merge identical frames, keeping the exact durations until the end so
+	// rounding to GIF delays does not drift
+	type step struct {
+		img      *image.RGBA
+		duration time.Duration
+	}
+	var steps []step
+	for i, img := range images {
+		if n := len(steps); n > 0 && bytes.Equal(steps[n-1].img.Pix, img.Pix) {
+			steps[n-1].duration += frames[i].Duration
+			continue
+		}
+		steps = append(steps, step{img, frames[i].Duration})
+	}
+
+	pal, index := gifPalette(images)
+	// Transparent pixels would show the previous frame through them, so
+	// transparent GIFs clear each full frame instead of storing changes
+	transparent := bg.A == 0
+	anim := &gif.GIF{LoopCount: 0}
+	if !loop {
+		anim.LoopCount = -1
+	}
+	var elapsed, shown time.Duration
+	for i, s := range steps {
+		rect := bounds
+		if i > 0 && !transparent {
+			rect = changedRect(steps[i-1].img, s.img)
+		}
+		paletted := image.NewPaletted(image.Rect(rect.Min.X*scale, rect.Min.Y*scale, rect.Max.X*scale, rect.Max.Y*scale), pal)
+		for y := paletted.Rect.Min.Y; y < paletted.Rect.Max.Y; y++ {
+			for x := paletted.Rect.Min.X; x < paletted.Rect.Max.X; x++ {
+				c := s.img.RGBAAt(x/scale, y/scale)
+				if index != nil {
+					paletted.SetColorIndex(x, y, index[c])
+				} else {
+					paletted.Set(x, y, c)
+				}
+			}
+		}
+
+		// GIF delays are in hundredths of a second
+		elapsed += s.duration
+		delay := int((elapsed - shown + 5*time.Millisecond) / (10 * time.Millisecond))
+		shown += time.Duration(delay) * 10 * time.Millisecond
+
+		anim.Image = append(anim.Image, paletted)
+		anim.Delay = append(anim.Delay, delay)
+		if transparent {
+			anim.Disposal = append(anim.Disposal, gif.DisposalBackground)
+		} else {
+			anim.Disposal = append(anim.Disposal, gif.DisposalNone)
+		}
+	}
+	anim.Config = image.Config{ColorModel: pal, Width: bounds.Dx() * scale, Height: bounds.Dy() * scale}
+	return gif.EncodeAll(w, anim)
+}
+
+// changedRect returns the smallest rectangle holding every pixel that
+// differs between a and b, which have the same bounds. It is never empty,
+// since every GIF frame needs at least one pixel.
+func changedRect(a, b *image.RGBA) image.Rectangle {
+	var changed image.Rectangle
+	bounds := a.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if a.RGBAAt(x, y) != b.RGBAAt(x, y) {
+				changed = changed.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	if changed.Empty() {
+		return image.Rect(bounds.Min.X, bounds.Min.Y, bounds.Min.X+1, bounds.Min.Y+1)
+	}
+	return changed
+}
+
+// gifPalette returns the colors used by the images and the index of each in
+// the palette. If there are more colors than a GIF can hold, it returns the
+// web safe palette and a nil index.
+func gifPalette(images []*image.RGBA) (color.Palette, map[color.RGBA]uint8) {
+	index := map[color.RGBA]uint8{}
+	var pal color.Palette
+	for _, img := range images {
+		for i := 0; i+3 < len(img.Pix); i += 4 {
+			c := color.RGBA{img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3]}
+			if _, ok := index[c]; ok {
+				continue
+			}
+			if len(pal) == 256 {
+				return palette.WebSafe, nil
+			}
+			index[c] = uint8(len(pal))
+			pal = append(pal, c)
+		}
+	}
+	return pal, index
+}
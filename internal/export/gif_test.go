@@ -0,0 +1,74 @@
+package export
+
+import (
+	"bytes"
+	"image"
+	"image/gif"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/MagikIO/familiar-says/internal/canvas"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// TestWriteGIF tests frame merging, delays and looping
+func TestWriteGIF(t *testing.T) {
+	open := canvas.FromLines([]string{"(oo)"}, lipgloss.NewStyle())
+	closed := canvas.FromLines([]string{"(--)", "wave"}, lipgloss.NewStyle())
+	frames := []Frame{
+		{Canvas: open, Duration: 50 * time.Millisecond},
+		{Canvas: open, Duration: 50 * time.Millisecond},
+		{Canvas: closed, Duration: 33 * time.Millisecond},
+		{Canvas: open, Duration: 33 * time.Millisecond},
+	}
+
+	tests := []struct {
+		name      string
+		loop      bool
+		wantCount int
+	}{
+		{"loop", true, 0},
+		{"play once", false, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteGIF(&buf, frames, tt.loop, PNGOptions{Scale: 1}); err != nil {
+				t.Fatalf("WriteGIF failed: %v", err)
+			}
+			decoded, err := gif.DecodeAll(&buf)
+			if err != nil {
+				t.Fatalf("Output is not a valid GIF: %v", err)
+			}
+
+			// The repeated first frame is merged; delays are rounded
+			// without drifting from the total duration
+			if want := []int{10, 3, 4}; !slices.Equal(decoded.Delay, want) {
+				t.Errorf("Delays = %v, want %v", decoded.Delay, want)
+			}
+			if decoded.LoopCount != tt.wantCount {
+				t.Errorf("LoopCount = %d, want %d", decoded.LoopCount, tt.wantCount)
+			}
+			// The first frame covers the tallest canvas; later ones only
+			// the area that changed
+			full := image.Rect(0, 0, decoded.Config.Width, decoded.Config.Height)
+			if full != Image(closed, PNGOptions{Scale: 1}).Bounds() {
+				t.Errorf("GIF size = %v, want the tallest frame", full)
+			}
+			if decoded.Image[0].Bounds() != full {
+				t.Errorf("Frame 0 bounds = %v, want %v", decoded.Image[0].Bounds(), full)
+			}
+			for i, img := range decoded.Image[1:] {
+				if b := img.Bounds(); b == full || !b.In(full) {
+					t.Errorf("Frame %d bounds = %v, want only the changes within %v", i+1, b, full)
+				}
+			}
+		})
+	}
+
+	if err := WriteGIF(&bytes.Buffer{}, nil, true, PNGOptions{}); err == nil {
+		t.Error("Expected an error without frames")
+	}
+}
@@ -0,0 +1,403 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MagikIO/familiar-says/internal/canvas"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// HTMLOptions controls HTML output. The zero value writes a <pre> fragment
+// with inline styles, light text on a dark background.
+type HTMLOptions struct {
+	Standalone bool   // Wrap the output in a complete HTML page
+	Classes    bool   // Style runs with CSS classes instead of inline styles
+	Title      string // Page title for standalone output (default "familiar-says")
+	Foreground string // Color for cells without one (default "#e5e5e5")
+	Background string // Background color; "none" for transparent (default "#1e1e1e")
+}
+
+// Frame is one frame of an animation and how long it is shown.
+type Frame struct {
+	Canvas   *canvas.Canvas
+	Duration time.Duration
+}
+
+// withDefaults fills in unset options.
+func (o HTMLOptions) withDefaults() HTMLOptions {
+	if o.Title == "" {
+		o.Title = "familiar-says"
+	}
+	if o.Foreground == "" {
+		o.Foreground = defaultForeground
+	}
+	if o.Background == "" {
+		o.Background = defaultBackground
+	}
+	return o
+}
+
+// runStyle is the style shared by a run of cells. Colors are resolved hex
+// codes, empty for the defaults.
+type runStyle struct {
+	fg, bg    string
+	bold      bool
+	italic    bool
+	underline bool
+	strike    bool
+	faint     bool
+}
+
+// newRunStyle resolves the style of a cell.
+func newRunStyle(style lipgloss.Style, opts HTMLOptions) runStyle {
+	fg, bg := cellColors(style, "", "")
+	if style.GetReverse() {
+		// Reverse video swaps in the default colors where unset
+		if fg == "" {
+			fg = opts.Background
+			if fg == "none" {
+				fg = "#000000"
+			}
+		}
+		if bg == "" {
+			bg = opts.Foreground
+		}
+	}
+	return runStyle{
+		fg:        fg,
+		bg:        bg,
+		bold:      style.GetBold(),
+		italic:    style.GetItalic(),
+		underline: style.GetUnderline(),
+		strike:    style.GetStrikethrough(),
+		faint:     style.GetFaint(),
+	}
+}
+
+// declarations returns the CSS declarations for the style.
+func (s runStyle) declarations() []string {
+	var decls []string
+	if s.fg != "" {
+		decls = append(decls, "color:"+s.fg)
+	}
+	if s.bg != "" {
+		decls = append(decls, "background-color:"+s.bg)
+	}
+	if s.bold {
+		decls = append(decls, "font-weight:bold")
+	}
+	if s.italic {
+		decls = append(decls, "font-style:italic")
+	}
+	if s.faint {
+		decls = append(decls, "opacity:0.6")
+	}
+	switch {
+	case s.underline && s.strike:
+		decls = append(decls, "text-decoration:underline line-through")
+	case s.underline:
+		decls = append(decls, "text-decoration:underline")
+	case s.strike:
+		decls = append(decls, "text-decoration:line-through")
+	}
+	return decls
+}
+
+// classes returns the CSS class names for the style. Names are derived from
+// the style itself, so fragments from separate exports can share a page.
+func (s runStyle) classes() []string {
+	var classes []string
+	if s.fg != "" {
+		classes = append(classes, "fs-fg-"+s.fg[1:])
+	}
+	if s.bg != "" {
+		classes = append(classes, "fs-bg-"+s.bg[1:])
+	}
+	for _, flag := range []struct {
+		set  bool
+		name string
+	}{
+		{s.bold, "fs-bold"},
+		{s.italic, "fs-italic"},
+		{s.faint, "fs-faint"},
+		{s.underline, "fs-underline"},
+		{s.strike, "fs-strike"},
+	} {
+		if flag.set {
+			classes = append(classes, flag.name)
+		}
+	}
+	return classes
+}
+
+// classRules returns the CSS rule for each class used by styles.
+func classRules(styles []runStyle) []string {
+	seen := map[string]bool{}
+	var rules []string
+	add := func(class, decl string) {
+		if !seen[class] {
+			seen[class] = true
+			rules = append(rules, fmt.Sprintf(".%s{%s}", class, decl))
+		}
+	}
+	for _, s := range styles {
+		if s.fg != "" {
+			add("fs-fg-"+s.fg[1:], "color:"+s.fg)
+		}
+		if s.bg != "" {
+			add("fs-bg-"+s.bg[1:], "background-color:"+s.bg)
+		}
+		if s.bold {
+			add("fs-bold", "font-weight:bold")
+		}
+		if s.italic {
+			add("fs-italic", "font-style:italic")
+		}
+		if s.faint {
+			add("fs-faint", "opacity:0.6")
+		}
+		if s.underline {
+			add("fs-underline", "text-decoration:underline")
+		}
+		if s.strike {
+			add("fs-strike", "text-decoration:line-through")
+		}
+	}
+	// Both decorations on one run need a combined rule
+	if seen["fs-underline"] && seen["fs-strike"] {
+		rules = append(rules, ".fs-underline.fs-strike{text-decoration:underline line-through}")
+	}
+	slices.Sort(rules)
+	return rules
+}
+
+// htmlRun is a run of text sharing one style.
+type htmlRun struct {
+	text  string
+	style runStyle
+}
+
+// htmlRuns splits the content rows of c into styled runs. Wide rune
+// continuation cells are skipped and unstyled trailing blanks are trimmed.
+func htmlRuns(c *canvas.Canvas, opts HTMLOptions) [][]htmlRun {
+	rows := make([][]htmlRun, contentRows(c))
+	for y := range rows {
+		cells := c.Cells[y]
+		end := len(cells)
+		for end > 0 {
+			cell := cells[end-1]
+			blank := cell.Transparent || cell.Grapheme == " "
+			if !blank || newRunStyle(cell.Style, opts).bg != "" {
+				break
+			}
+			end--
+		}
+
+		var text strings.Builder
+		var style runStyle
+		for x := 0; x < end; x++ {
+			cell := cells[x]
+			if cell.Grapheme == "" {
+				continue
+			}
+			cellStyle := newRunStyle(cell.Style, opts)
+			if cell.Transparent {
+				cellStyle = runStyle{}
+			}
+			if text.Len() > 0 && cellStyle != style {
+				rows[y] = append(rows[y], htmlRun{text.String(), style})
+				text.Reset()
+			}
+			style = cellStyle
+			if cell.Transparent {
+				text.WriteRune(' ')
+			} else {
+				text.WriteString(cell.Grapheme)
+			}
+		}
+		if text.Len() > 0 {
+			rows[y] = append(rows[y], htmlRun{text.String(), style})
+		}
+	}
+	return rows
+}
+
+// preStyle returns the CSS declarations for the <pre> element.
+func preStyle(opts HTMLOptions) string {
+	decls := []string{
+		"font-family:" + defaultFontFamily,
+		"line-height:1.2",
+		"color:" + opts.Foreground,
+	}
+	if opts.Background != "none" {
+		decls = append(decls, "background-color:"+opts.Background, "padding:1em")
+	}
+	return strings.Join(decls, ";")
+}
+
+// writePre writes the runs as a <pre> element with the given extra
+// attributes.
+func writePre(b *strings.Builder, rows [][]htmlRun, opts HTMLOptions, attrs string) {
+	fmt.Fprintf(b, `<pre class="familiar"%s>`, attrs)
+	for y, row := range rows {
+		if y > 0 {
+			b.WriteByte('\n')
+		}
+		for _, run := range row {
+			text := html.EscapeString(run.text)
+			switch {
+			case run.style == runStyle{}:
+				b.WriteString(text)
+			case opts.Classes:
+				fmt.Fprintf(b, `<span class="%s">%s</span>`, strings.Join(run.style.classes(), " "), text)
+			default:
+				fmt.Fprintf(b, `<span style="%s">%s</span>`, strings.Join(run.style.declarations(), ";"), text)
+			}
+		}
+	}
+	b.WriteString("</pre>\n")
+}
+
+// usedStyles returns the styles of all runs.
+func usedStyles(rows ...[][]htmlRun) []runStyle {
+	var styles []runStyle
+	for _, frame := range rows {
+		for _, row := range frame {
+			for _, run := range row {
+				styles = append(styles, run.style)
+			}
+		}
+	}
+	return styles
+}
+
+// HTML renders a canvas to HTML: a <pre> block with a span for each run of
+// styled cells.
+func HTML(c *canvas.Canvas, opts HTMLOptions) []byte {
+	var buf bytes.Buffer
+	_ = WriteHTML(&buf, c, opts) // Writes to a bytes.Buffer cannot fail
+	return buf.Bytes()
+}
+
+// WriteHTML renders a canvas to HTML written to w.
+func WriteHTML(w io.Writer, c *canvas.Canvas, opts HTMLOptions) error {
+	return WriteHTMLAnimation(w, []Frame{{Canvas: c}}, false, opts)
+}
+
+// WriteHTMLAnimation renders the frames of an animation to HTML written to
+// w. The frames are stacked on top of each other and a CSS keyframe
+// animation shows each one for its duration, repeating if loop is set and
+// otherwise stopping on the last frame. A single frame is written as a
+// static <pre> block.
+func WriteHTMLAnimation(w io.Writer, frames []Frame, loop bool, opts HTMLOptions) error {
+	opts = opts.withDefaults()
+
+	var total time.Duration
+	rows := make([][][]htmlRun, len(frames))
+	for i, frame := range frames {
+		rows[i] = htmlRuns(frame.Canvas, opts)
+		total += frame.Duration
+	}
+	animated := len(frames) > 1 && total > 0
+
+	var css []string
+	if opts.Classes {
+		css = append(css, ".familiar{"+preStyle(opts)+"}")
+		css = append(css, classRules(usedStyles(rows...))...)
+	}
+	if animated {
+		css = append(css,
+			".familiar-animation{display:inline-grid}",
+			".familiar-animation>.familiar{grid-area:1/1;margin:0;visibility:hidden}",
+		)
+	}
+
+	var b strings.Builder
+	if opts.Standalone {
+		fmt.Fprintf(&b, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n", html.EscapeString(opts.Title))
+		if len(css) > 0 {
+			fmt.Fprintf(&b, "<style>\n%s\n</style>\n", strings.Join(css, "\n"))
+		}
+		b.WriteString("</head>\n<body>\n")
+	} else if len(css) > 0 {
+		fmt.Fprintf(&b, "<style>\n%s\n</style>\n", strings.Join(css, "\n"))
+	}
+
+	inline := ""
+	if !opts.Classes {
+		inline = preStyle(opts)
+	}
+
+	if !animated {
+		attrs := ""
+		if inline != "" {
+			attrs = fmt.Sprintf(` style="%s"`, html.EscapeString(inline))
+		}
+		if len(rows) > 0 {
+			writePre(&b, rows[0], opts, attrs)
+		}
+	} else {
+		b.WriteString("<div class=\"familiar-animation\">\n")
+		var keyframes []string
+		var start time.Duration
+		for i, frame := range frames {
+			name, rule := frameKeyframes(start, start+frame.Duration, total, loop, i == len(frames)-1)
+			keyframes = append(keyframes, rule)
+			decls := fmt.Sprintf("animation:%s %dms step-end %s forwards", name, total.Milliseconds(), iterations(loop))
+			if inline != "" {
+				decls = inline + ";" + decls
+			}
+			writePre(&b, rows[i], opts, fmt.Sprintf(` style="%s"`, html.EscapeString(decls)))
+			start += frame.Duration
+		}
+		b.WriteString("</div>\n")
+		fmt.Fprintf(&b, "<style>\n%s\n</style>\n", strings.Join(slices.Compact(keyframes), "\n"))
+	}
+
+	if opts.Standalone {
+		b.WriteString("</body>\n</html>\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// frameKeyframes returns the name and @keyframes rule that show a frame
+// between start and end of an animation lasting total. The name encodes the
+// timing, so animations exported separately can share a page.
+func frameKeyframes(start, end, total time.Duration, loop, last bool) (string, string) {
+	name := fmt.Sprintf("fs-frame-%d-%d-%d", start.Milliseconds(), end.Milliseconds(), total.Milliseconds())
+	if last && !loop {
+		name += "-hold"
+	}
+
+	percent := func(d time.Duration) string {
+		return num(float64(d) / float64(total) * 100)
+	}
+	var steps []string
+	if start > 0 {
+		steps = append(steps, "0%{visibility:hidden}")
+	}
+	steps = append(steps, percent(start)+"%{visibility:visible}")
+	switch {
+	case last && !loop:
+		// Stay on the last frame once the animation stops
+		steps = append(steps, "100%{visibility:visible}")
+	case end < total:
+		steps = append(steps, percent(end)+"%{visibility:hidden}")
+	}
+	return name, fmt.Sprintf("@keyframes %s{%s}", name, strings.Join(steps, ""))
+}
+
+// iterations returns the CSS animation iteration count.
+func iterations(loop bool) string {
+	if loop {
+		return "infinite"
+	}
+	return "1"
+}
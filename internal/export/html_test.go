@@ -0,0 +1,127 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MagikIO/familiar-says/internal/canvas"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// testCanvas returns a canvas with a red bold word, plain text and a wide
+// rune.
+func testCanvas() *canvas.Canvas {
+	c := canvas.NewCanvas(12, 2)
+	c.DrawString(0, 0, "Hi", lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000")).Bold(true))
+	c.DrawString(3, 0, "<日>", lipgloss.NewStyle())
+	return c
+}
+
+// TestHTML tests the runs and styling of HTML output
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		opts HTMLOptions
+		want []string
+		not  []string
+	}{
+		{
+			name: "inline styles",
+			opts: HTMLOptions{},
+			want: []string{
+				`<span style="color:#ff0000;font-weight:bold">Hi</span> &lt;日&gt;</pre>`,
+				`<pre class="familiar" style="font-family:ui-monospace, &#34;SFMono-Regular&#34;`,
+				`background-color:#1e1e1e`,
+			},
+			not: []string{"<style>", "<html>"},
+		},
+		{
+			name: "classes",
+			opts: HTMLOptions{Classes: true},
+			want: []string{
+				`<span class="fs-fg-ff0000 fs-bold">Hi</span>`,
+				`.fs-fg-ff0000{color:#ff0000}`,
+				`.fs-bold{font-weight:bold}`,
+				`<pre class="familiar">`,
+			},
+		},
+		{
+			name: "standalone",
+			opts: HTMLOptions{Standalone: true, Title: "A & B"},
+			want: []string{"<!DOCTYPE html>", "<title>A &amp; B</title>", "</body>\n</html>\n"},
+		},
+		{
+			name: "transparent background",
+			opts: HTMLOptions{Background: "none"},
+			not:  []string{"background-color", "padding"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := string(HTML(testCanvas(), tt.opts))
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("Output doesn't contain %q:\n%s", want, out)
+				}
+			}
+			for _, not := range tt.not {
+				if strings.Contains(out, not) {
+					t.Errorf("Output contains %q:\n%s", not, out)
+				}
+			}
+		})
+	}
+}
+
+// TestHTMLAnimation tests the CSS keyframes cycling animation frames
+func TestHTMLAnimation(t *testing.T) {
+	frames := []Frame{
+		{Canvas: canvas.FromLines([]string{"(oo)"}, lipgloss.NewStyle()), Duration: 100 * time.Millisecond},
+		{Canvas: canvas.FromLines([]string{"(--)"}, lipgloss.NewStyle()), Duration: 300 * time.Millisecond},
+	}
+
+	tests := []struct {
+		name string
+		loop bool
+		want []string
+	}{
+		{
+			name: "loop",
+			loop: true,
+			want: []string{
+				`animation:fs-frame-0-100-400 400ms step-end infinite forwards`,
+				`@keyframes fs-frame-0-100-400{0%{visibility:visible}25%{visibility:hidden}}`,
+				`@keyframes fs-frame-100-400-400{0%{visibility:hidden}25%{visibility:visible}}`,
+			},
+		},
+		{
+			name: "hold last frame",
+			loop: false,
+			want: []string{
+				`animation:fs-frame-0-100-400 400ms step-end 1 forwards`,
+				`@keyframes fs-frame-100-400-400-hold{0%{visibility:hidden}25%{visibility:visible}100%{visibility:visible}}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteHTMLAnimation(&buf, frames, tt.loop, HTMLOptions{}); err != nil {
+				t.Fatalf("WriteHTMLAnimation failed: %v", err)
+			}
+			out := buf.String()
+			if strings.Count(out, `<pre class="familiar"`) != 2 || !strings.Contains(out, `<div class="familiar-animation">`) {
+				t.Errorf("Expected both frames in an animation container:\n%s", out)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("Output doesn't contain %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/MagikIO/familiar-says/internal/canvas"
+)
+
+// JSONVersion is the version of the JSON scene format. It is increased
+// whenever a change could break existing consumers; adding fields does not
+// change it.
+const JSONVersion = 1
+
+// JSONScene is the JSON representation of a rendered scene.
+type JSONScene struct {
+	Version int         `json:"version"`
+	Width   int         `json:"width"`
+	Height  int         `json:"height"`
+	Theme   string      `json:"theme,omitempty"`
+	Lines   []string    `json:"lines"`
+	Panels  []JSONPanel `json:"panels"`
+	Cells   []JSONCell  `json:"cells"`
+}
+
+// JSONPanel describes one character of a scene: what was resolved for it
+// and where its parts were placed.
+type JSONPanel struct {
+	Character string     `json:"character"`
+	Mood      string     `json:"mood,omitempty"`
+	Template  string     `json:"template,omitempty"`
+	Bounds    JSONBounds `json:"bounds"`
+}
+
+// JSONBounds holds the bounding boxes of the parts of a panel. Parts that
+// are not shown are left out.
+type JSONBounds struct {
+	Bubble    *canvas.Rect `json:"bubble,omitempty"`
+	Connector *canvas.Rect `json:"connector,omitempty"`
+	Character *canvas.Rect `json:"character,omitempty"`
+}
+
+// JSONCell is a visible cell of a scene. Colors are "#rrggbb" strings and
+// are left out when the terminal default applies.
+type JSONCell struct {
+	X             int    `json:"x"`
+	Y             int    `json:"y"`
+	Rune          string `json:"rune"`            // A whole grapheme cluster, despite the name
+	Width         int    `json:"width,omitempty"` // Set for wide clusters only
+	Fg            string `json:"fg,omitempty"`
+	Bg            string `json:"bg,omitempty"`
+	Bold          bool   `json:"bold,omitempty"`
+	Italic        bool   `json:"italic,omitempty"`
+	Underline     bool   `json:"underline,omitempty"`
+	Faint         bool   `json:"faint,omitempty"`
+	Reverse       bool   `json:"reverse,omitempty"`
+	Strikethrough bool   `json:"strikethrough,omitempty"`
+}
+
+// NewJSONBounds converts a scene layout to panel bounds.
+func NewJSONBounds(layout canvas.SceneLayout) JSONBounds {
+	rect := func(r canvas.Rect) *canvas.Rect {
+		if r.Empty() {
+			return nil
+		}
+		return &r
+	}
+	return JSONBounds{
+		Bubble:    rect(layout.Bubble),
+		Connector: rect(layout.Connector),
+		Character: rect(layout.Character),
+	}
+}
+
+// NewJSONScene describes a canvas rendered with theme, holding the panels
+// it was composed from.
+func NewJSONScene(c *canvas.Canvas, theme string, panels []JSONPanel) JSONScene {
+	rows := contentRows(c)
+	scene := JSONScene{
+		Version: JSONVersion,
+		Width:   c.Width,
+		Height:  rows,
+		Theme:   theme,
+		Lines:   make([]string, rows),
+		Panels:  panels,
+		Cells:   []JSONCell{},
+	}
+	if scene.Panels == nil {
+		scene.Panels = []JSONPanel{}
+	}
+
+	plain := c.RenderPlain()
+	for y := 0; y < rows; y++ {
+		scene.Lines[y] = strings.TrimRight(plain[y], " ")
+		for x, cell := range c.Cells[y] {
+			if cell.Transparent || cell.Grapheme == " " || cell.Grapheme == "" {
+				continue
+			}
+			style := cell.Style
+			jc := JSONCell{
+				X:             x,
+				Y:             y,
+				Rune:          cell.Grapheme,
+				Fg:            ColorHex(style.GetForeground()),
+				Bg:            ColorHex(style.GetBackground()),
+				Bold:          style.GetBold(),
+				Italic:        style.GetItalic(),
+				Underline:     style.GetUnderline(),
+				Faint:         style.GetFaint(),
+				Reverse:       style.GetReverse(),
+				Strikethrough: style.GetStrikethrough(),
+			}
+			if w := cell.Width(); w > 1 {
+				jc.Width = w
+			}
+			scene.Cells = append(scene.Cells, jc)
+		}
+	}
+	return scene
+}
+
+// JSON renders a canvas to an indented JSON scene description.
+func JSON(c *canvas.Canvas, theme string, panels []JSONPanel) []byte {
+	var buf bytes.Buffer
+	_ = WriteJSON(&buf, c, theme, panels) // Writes to a bytes.Buffer cannot fail
+	return buf.Bytes()
+}
+
+// WriteJSON renders a canvas to an indented JSON scene description written
+// to w.
+func WriteJSON(w io.Writer, c *canvas.Canvas, theme string, panels []JSONPanel) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false) // Keep bubble edges such as < and > readable
+	return enc.Encode(NewJSONScene(c, theme, panels))
+}
@@ -0,0 +1,73 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/MagikIO/familiar-says/internal/canvas"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// TestJSON tests the JSON scene description
+func TestJSON(t *testing.T) {
+	c := canvas.NewCanvas(10, 4)
+	c.DrawString(0, 0, "Hi 世", lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Bold(true))
+	c.DrawString(1, 1, "x", lipgloss.NewStyle().Background(lipgloss.Color("4")))
+
+	panels := []JSONPanel{{
+		Character: "cat",
+		Mood:      "happy",
+		Template:  "say",
+		Bounds:    NewJSONBounds(canvas.SceneLayout{Character: canvas.Rect{X: 0, Y: 0, Width: 5, Height: 2}}),
+	}}
+	data := JSON(c, "default", panels)
+
+	var scene JSONScene
+	if err := json.Unmarshal(data, &scene); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, data)
+	}
+
+	if scene.Version != JSONVersion || scene.Width != 10 || scene.Height != 2 || scene.Theme != "default" {
+		t.Errorf("Got version %d, %dx%d, theme %q", scene.Version, scene.Width, scene.Height, scene.Theme)
+	}
+	if strings.Join(scene.Lines, "\n") != "Hi 世\n x" {
+		t.Errorf("Lines = %q", scene.Lines)
+	}
+
+	want := []JSONCell{
+		{X: 0, Y: 0, Rune: "H", Fg: "#ff0000", Bold: true},
+		{X: 1, Y: 0, Rune: "i", Fg: "#ff0000", Bold: true},
+		{X: 3, Y: 0, Rune: "世", Width: 2, Fg: "#ff0000", Bold: true},
+		{X: 1, Y: 1, Rune: "x", Bg: "#0000ee"},
+	}
+	if len(scene.Cells) != len(want) {
+		t.Fatalf("Got %d cells, want %d: %+v", len(scene.Cells), len(want), scene.Cells)
+	}
+	for i, cell := range scene.Cells {
+		if cell != want[i] {
+			t.Errorf("Cell %d = %+v, want %+v", i, cell, want[i])
+		}
+	}
+
+	if len(scene.Panels) != 1 || scene.Panels[0].Character != "cat" || scene.Panels[0].Mood != "happy" {
+		t.Fatalf("Panels = %+v", scene.Panels)
+	}
+	bounds := scene.Panels[0].Bounds
+	if bounds.Bubble != nil || bounds.Connector != nil || bounds.Character == nil || bounds.Character.Width != 5 {
+		t.Errorf("Bounds = %+v, want only a character", bounds)
+	}
+	if strings.Contains(string(data), `"bubble"`) {
+		t.Error("Missing parts should be left out of the output")
+	}
+}
+
+// TestJSONEmpty tests that empty scenes have empty arrays rather than nulls
+func TestJSONEmpty(t *testing.T) {
+	data := string(JSON(canvas.NewCanvas(3, 3), "", nil))
+	for _, field := range []string{`"lines": []`, `"panels": []`, `"cells": []`} {
+		if !strings.Contains(data, field) {
+			t.Errorf("Expected %s in:\n%s", field, data)
+		}
+	}
+}
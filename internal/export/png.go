@@ -0,0 +1,343 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/MagikIO/familiar-says/internal/canvas"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/inconsolata"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// PNGOptions controls PNG output. The zero value renders light text on a
+// dark background at twice the font's pixel size.
+type PNGOptions struct {
+	Scale      int     // Integer pixel scale (default 2)
+	Padding    int     // Padding around the grid in unscaled pixels (default 16, negative for none)
+	Foreground string  // Color for cells without one (default "#e5e5e5")
+	Background string  // Background color; "none" for transparent (default "#1e1e1e")
+	Fonts      []*Font // Fonts for glyphs the embedded fonts lack, such as CJK (see LoadFont)
+}
+
+// Cell geometry of the embedded bitmap font, in unscaled pixels. Cells are
+// as tall as the font's ascent plus descent so glyphs are never clipped.
+const (
+	cellPixelWidth  = 8
+	cellPixelHeight = 17
+	cellBaseline    = 14
+)
+
+// withDefaults fills in unset options.
+func (o PNGOptions) withDefaults() PNGOptions {
+	if o.Scale <= 0 {
+		o.Scale = 2
+	}
+	if o.Padding < 0 {
+		o.Padding = 0
+	} else if o.Padding == 0 {
+		o.Padding = 16
+	}
+	if o.Foreground == "" {
+		o.Foreground = defaultForeground
+	}
+	if o.Background == "" {
+		o.Background = defaultBackground
+	}
+	return o
+}
+
+// PNG renders a canvas to a PNG image.
+func PNG(c *canvas.Canvas, opts PNGOptions) []byte {
+	var buf bytes.Buffer
+	_ = WritePNG(&buf, c, opts) // Writes to a bytes.Buffer cannot fail
+	return buf.Bytes()
+}
+
+// WritePNG renders a canvas to a PNG image written to w.
+func WritePNG(w io.Writer, c *canvas.Canvas, opts PNGOptions) error {
+	return png.Encode(w, Image(c, opts))
+}
+
+// Image paints a canvas cell by cell with the embedded Inconsolata bitmap
+// font. Box drawing and block characters are drawn to fill their cells so
+// borders join up, and glyphs the bitmap font lacks fall back to Go Mono and
+// then opts.Fonts. Glyphs no font has are drawn as placeholder boxes.
+func Image(c *canvas.Canvas, opts PNGOptions) *image.RGBA {
+	opts = opts.withDefaults()
+	rows := contentRows(c)
+	fg, _ := parseHex(opts.Foreground)
+	bg, opaque := parseHex(opts.Background)
+	if !opaque {
+		bg = color.RGBA{}
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0,
+		2*opts.Padding+c.Width*cellPixelWidth,
+		2*opts.Padding+rows*cellPixelHeight))
+	draw.Draw(img, img.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+
+	for y := 0; y < rows; y++ {
+		for x := 0; x < c.Width; x++ {
+			cell := c.Cells[y][x]
+			if cell.Grapheme == "" || cell.Transparent {
+				continue
+			}
+			width := cell.Width()
+			rect := image.Rect(0, 0, width*cellPixelWidth, cellPixelHeight).
+				Add(image.Pt(opts.Padding+x*cellPixelWidth, opts.Padding+y*cellPixelHeight))
+
+			reverseBg := opts.Background
+			if !opaque {
+				reverseBg = "#000000"
+			}
+			cellFg, cellBg := cellColors(cell.Style, opts.Foreground, reverseBg)
+			if hex, ok := parseHex(cellBg); ok {
+				draw.Draw(img, rect, image.NewUniform(hex), image.Point{}, draw.Src)
+			}
+			if cell.Grapheme == " " {
+				continue
+			}
+
+			ink, ok := parseHex(cellFg)
+			if !ok {
+				ink = fg
+			}
+			if cell.Style.GetFaint() {
+				ink = fade(ink, 0.6)
+			}
+			src := image.NewUniform(ink)
+
+			// The bitmap fonts have no combining marks; draw the base rune
+			r, _ := utf8.DecodeRuneInString(cell.Grapheme)
+			drawGlyph(img, rect, r, src, cell.Style.GetBold(), opts.Fonts)
+			if cell.Style.GetUnderline() {
+				draw.Draw(img, image.Rect(rect.Min.X, rect.Min.Y+cellBaseline+1, rect.Max.X, rect.Min.Y+cellBaseline+2), src, image.Point{}, draw.Over)
+			}
+			if cell.Style.GetStrikethrough() {
+				mid := rect.Min.Y + cellBaseline - 5
+				draw.Draw(img, image.Rect(rect.Min.X, mid, rect.Max.X, mid+1), src, image.Point{}, draw.Over)
+			}
+		}
+	}
+
+	return scaleImage(img, opts.Scale)
+}
+
+// MissingGlyphs returns the characters of c, in order of first appearance,
+// that are drawn as placeholder boxes because neither the embedded fonts nor
+// opts.Fonts have them. Without extra fonts this includes CJK and emoji.
+func MissingGlyphs(c *canvas.Canvas, opts PNGOptions) []rune {
+	var missing []rune
+	seen := map[rune]bool{}
+	for y := 0; y < c.Height; y++ {
+		for x := 0; x < c.Width; x++ {
+			cell := c.Cells[y][x]
+			if cell.Grapheme == "" || cell.Grapheme == " " || cell.Transparent {
+				continue
+			}
+			r, _ := utf8.DecodeRuneInString(cell.Grapheme)
+			if seen[r] {
+				continue
+			}
+			seen[r] = true
+			if !canDraw(r, opts.Fonts) {
+				missing = append(missing, r)
+			}
+		}
+	}
+	return missing
+}
+
+// canDraw reports whether r is drawn by the box drawing code or one of the
+// fonts, rather than as a placeholder box.
+func canDraw(r rune, fonts []*Font) bool {
+	if isBoxGlyph(r) {
+		return true
+	}
+	if _, ok := inconsolata.Regular8x16.GlyphAdvance(r); ok {
+		return true
+	}
+	return slices.ContainsFunc(fallbackFonts(fonts), func(f *Font) bool { return f.has(r) })
+}
+
+// drawGlyph draws r into the cell rectangle rect, trying the box drawing
+// code, the bitmap font, Go Mono and then fonts.
+func drawGlyph(img *image.RGBA, rect image.Rectangle, r rune, src *image.Uniform, bold bool, fonts []*Font) {
+	if drawBoxGlyph(img, rect, r, src, bold) {
+		return
+	}
+
+	face := inconsolata.Regular8x16
+	if bold {
+		face = inconsolata.Bold8x16
+	}
+	if _, ok := face.GlyphAdvance(r); ok {
+		dot := fixed.P(rect.Min.X, rect.Min.Y+cellBaseline)
+		dr, mask, maskp, _, _ := face.Glyph(dot, r)
+		draw.DrawMask(img, dr, src, image.Point{}, mask, maskp, draw.Over)
+		return
+	}
+
+	for _, f := range fallbackFonts(fonts) {
+		if f.draw(img, rect, r, src, bold) {
+			return
+		}
+	}
+
+	// No font has the glyph: draw a placeholder box filling its cells
+	box := image.Rect(rect.Min.X+1, rect.Min.Y+3, rect.Max.X-1, rect.Min.Y+cellBaseline+1)
+	for _, edge := range []image.Rectangle{
+		{box.Min, image.Pt(box.Max.X, box.Min.Y+1)},
+		{image.Pt(box.Min.X, box.Max.Y-1), box.Max},
+		{box.Min, image.Pt(box.Min.X+1, box.Max.Y)},
+		{image.Pt(box.Max.X-1, box.Min.Y), box.Max},
+	} {
+		draw.Draw(img, edge, src, image.Point{}, draw.Over)
+	}
+}
+
+// Font is a TrueType or OpenType font for glyphs the embedded fonts lack,
+// such as CJK, sized so its em fits two cells.
+type Font struct {
+	mu   sync.Mutex // Faces are not safe for concurrent use
+	font *sfnt.Font
+	face font.Face
+}
+
+// LoadFont loads a TrueType or OpenType font file, or the first font of a
+// collection such as a .ttc file.
+func LoadFont(path string) (*Font, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	collection, err := opentype.ParseCollection(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	f, err := collection.Font(0)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return newFont(f)
+}
+
+// newFont sizes f to the bitmap font's cells. Monospaced fonts advance
+// 0.6em, so this size gives 8 pixel cells, and full-width glyphs of 1em fit
+// two cells.
+func newFont(f *sfnt.Font) (*Font, error) {
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{
+		Size:    float64(cellPixelWidth) / 0.6,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Font{font: f, face: face}, nil
+}
+
+// has reports whether the font has a glyph for r, rather than its
+// placeholder glyph.
+func (f *Font) has(r rune) bool {
+	var buf sfnt.Buffer
+	idx, err := f.font.GlyphIndex(&buf, r)
+	return err == nil && idx != 0
+}
+
+// draw draws r centered in the cell rectangle rect, reporting false if the
+// font has no glyph for it.
+func (f *Font) draw(img *image.RGBA, rect image.Rectangle, r rune, src *image.Uniform, bold bool) bool {
+	if !f.has(r) {
+		return false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Center the glyph in its cells; wide runes span two
+	advance, _ := f.face.GlyphAdvance(r)
+	left := fixed.I(rect.Min.X) + (fixed.I(rect.Dx())-advance)/2
+	dot := fixed.Point26_6{X: left, Y: fixed.I(rect.Min.Y + cellBaseline)}
+	dr, mask, maskp, _, ok := f.face.Glyph(dot, r)
+	if !ok {
+		return false
+	}
+	draw.DrawMask(img, dr, src, image.Point{}, mask, maskp, draw.Over)
+	if bold {
+		// Fake bold by overprinting one pixel to the right
+		draw.DrawMask(img, dr.Add(image.Pt(1, 0)), src, image.Point{}, mask, maskp, draw.Over)
+	}
+	return true
+}
+
+var (
+	goMonoOnce sync.Once
+	goMono     *Font
+)
+
+// fallbackFonts returns the fonts tried for glyphs the bitmap font lacks:
+// the embedded Go Mono, then fonts.
+func fallbackFonts(fonts []*Font) []*Font {
+	goMonoOnce.Do(func() {
+		if f, err := opentype.Parse(gomono.TTF); err == nil {
+			goMono, _ = newFont(f)
+		}
+	})
+	if goMono == nil {
+		return fonts
+	}
+	return append([]*Font{goMono}, fonts...)
+}
+
+// scaleImage enlarges img by an integer factor without smoothing, keeping
+// the bitmap glyphs crisp.
+func scaleImage(img *image.RGBA, scale int) *image.RGBA {
+	if scale == 1 {
+		return img
+	}
+	b := img.Bounds()
+	scaled := image.NewRGBA(image.Rect(0, 0, b.Dx()*scale, b.Dy()*scale))
+	for y := 0; y < scaled.Rect.Dy(); y++ {
+		for x := 0; x < scaled.Rect.Dx(); x++ {
+			scaled.SetRGBA(x, y, img.RGBAAt(b.Min.X+x/scale, b.Min.Y+y/scale))
+		}
+	}
+	return scaled
+}
+
+// parseHex parses a "#rrggbb" color. It reports false for anything else,
+// such as "none".
+func parseHex(s string) (color.RGBA, bool) {
+	if len(s) != 7 || s[0] != '#' {
+		return color.RGBA{}, false
+	}
+	v, err := strconv.ParseUint(s[1:], 16, 32)
+	if err != nil {
+		return color.RGBA{}, false
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}, true
+}
+
+// fade returns c with its opacity scaled by alpha, premultiplied.
+func fade(c color.RGBA, alpha float64) color.RGBA {
+	return color.RGBA{
+		R: uint8(float64(c.R) * alpha),
+		G: uint8(float64(c.G) * alpha),
+		B: uint8(float64(c.B) * alpha),
+		A: uint8(float64(c.A) * alpha),
+	}
+}
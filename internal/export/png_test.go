@@ -0,0 +1,169 @@
+package export
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/MagikIO/familiar-says/internal/canvas"
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/image/font/gofont/gomono"
+)
+
+// inked reports whether any pixel of rect in img has the color c.
+func inked(img *image.RGBA, rect image.Rectangle, c color.RGBA) bool {
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			if img.RGBAAt(x, y) == c {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// TestPNG tests that PNG output decodes to an image of the grid's size
+func TestPNG(t *testing.T) {
+	c := canvas.FromLines([]string{"abc", ""}, lipgloss.NewStyle())
+
+	img, err := png.Decode(bytes.NewReader(PNG(c, PNGOptions{Scale: 2, Padding: 4})))
+	if err != nil {
+		t.Fatalf("Output is not a valid PNG: %v", err)
+	}
+	// Trailing empty rows are trimmed
+	want := image.Rect(0, 0, (4*2+3*cellPixelWidth)*2, (4*2+cellPixelHeight)*2)
+	if img.Bounds() != want {
+		t.Errorf("Bounds = %v, want %v", img.Bounds(), want)
+	}
+}
+
+// TestImageColors tests painting cells with their resolved colors
+func TestImageColors(t *testing.T) {
+	red := color.RGBA{0xff, 0, 0, 0xff}
+	blue := color.RGBA{0, 0, 0xee, 0xff}
+
+	c := canvas.NewCanvas(4, 1)
+	c.DrawString(0, 0, "A", lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000")))
+	c.DrawString(1, 0, "日", lipgloss.NewStyle().Background(lipgloss.Color("4")))
+
+	img := Image(c, PNGOptions{Scale: 1, Padding: -1})
+	if got := img.RGBAAt(3*cellPixelWidth, 0); got != (color.RGBA{0x1e, 0x1e, 0x1e, 0xff}) {
+		t.Errorf("Background = %v, want #1e1e1e", got)
+	}
+	if !inked(img, image.Rect(0, 0, cellPixelWidth, cellPixelHeight), red) {
+		t.Error("Expected a red glyph in the first cell")
+	}
+	// The wide rune's background covers both of its cells
+	for _, x := range []int{cellPixelWidth, 3*cellPixelWidth - 1} {
+		if got := img.RGBAAt(x, 0); got != blue {
+			t.Errorf("Pixel at x=%d = %v, want the wide rune's background", x, got)
+		}
+	}
+
+	transparent := Image(c, PNGOptions{Scale: 1, Padding: -1, Background: "none"})
+	if got := transparent.RGBAAt(3*cellPixelWidth, 0); got.A != 0 {
+		t.Errorf("Background = %v, want transparent", got)
+	}
+}
+
+// TestImageBoxDrawing tests that box drawing lines reach the cell edges so
+// neighboring cells join up
+func TestImageBoxDrawing(t *testing.T) {
+	white := color.RGBA{0xff, 0xff, 0xff, 0xff}
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("#ffffff"))
+
+	tests := []struct {
+		name  string
+		r     string
+		edges []image.Point
+	}{
+		{"horizontal", "─", []image.Point{{0, cellPixelHeight / 2}, {cellPixelWidth - 1, cellPixelHeight / 2}}},
+		{"vertical", "│", []image.Point{{cellPixelWidth / 2, 0}, {cellPixelWidth / 2, cellPixelHeight - 1}}},
+		{"corner", "┌", []image.Point{{cellPixelWidth - 1, cellPixelHeight / 2}, {cellPixelWidth / 2, cellPixelHeight - 1}}},
+		{"rounded corner", "╯", []image.Point{{0, cellPixelHeight / 2}, {cellPixelWidth / 2, 0}}},
+		{"double", "═", []image.Point{{0, cellPixelHeight/2 - 1}, {0, cellPixelHeight/2 + 1}}},
+		{"full block", "█", []image.Point{{0, 0}, {cellPixelWidth - 1, cellPixelHeight - 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := canvas.NewCanvas(1, 1)
+			c.DrawString(0, 0, tt.r, style)
+			img := Image(c, PNGOptions{Scale: 1, Padding: -1})
+			for _, p := range tt.edges {
+				if got := img.RGBAAt(p.X, p.Y); got != white {
+					t.Errorf("Pixel at %v = %v, want the line color", p, got)
+				}
+			}
+		})
+	}
+}
+
+// TestImageScale tests that scaling repeats pixels without smoothing
+func TestImageScale(t *testing.T) {
+	c := canvas.FromLines([]string{"█"}, lipgloss.NewStyle())
+	small := Image(c, PNGOptions{Scale: 1, Padding: -1})
+	large := Image(c, PNGOptions{Scale: 3, Padding: -1})
+
+	if large.Bounds().Dx() != 3*small.Bounds().Dx() || large.Bounds().Dy() != 3*small.Bounds().Dy() {
+		t.Fatalf("Scaled bounds = %v, want 3x %v", large.Bounds(), small.Bounds())
+	}
+	if large.RGBAAt(2, 2) != small.RGBAAt(0, 0) {
+		t.Error("Scaled pixels don't match the original")
+	}
+}
+
+// TestMissingGlyphs tests finding characters drawn as placeholder boxes
+func TestMissingGlyphs(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []rune
+	}{
+		{"ascii", "abc", nil},
+		{"box drawing", "┌─┐", nil},
+		{"fallback font", "→", nil},
+		{"cjk", "a日本日", []rune{'日', '本'}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := canvas.FromLines([]string{tt.line}, lipgloss.NewStyle())
+			if got := MissingGlyphs(c, PNGOptions{}); !slices.Equal(got, tt.want) {
+				t.Errorf("MissingGlyphs(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLoadFont tests loading fallback fonts from files
+func TestLoadFont(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "gomono.ttf")
+	invalid := filepath.Join(dir, "invalid.ttf")
+	if err := os.WriteFile(valid, gomono.TTF, 0644); err != nil {
+		t.Fatalf("Failed to write font: %v", err)
+	}
+	if err := os.WriteFile(invalid, []byte("not a font"), 0644); err != nil {
+		t.Fatalf("Failed to write font: %v", err)
+	}
+
+	f, err := LoadFont(valid)
+	if err != nil {
+		t.Fatalf("LoadFont failed: %v", err)
+	}
+	if !f.has('→') || f.has('日') {
+		t.Error("Expected the loaded font's own glyphs")
+	}
+
+	for _, path := range []string{invalid, filepath.Join(dir, "missing.ttf")} {
+		if _, err := LoadFont(path); err == nil {
+			t.Errorf("LoadFont(%q) succeeded, want an error", path)
+		}
+	}
+}
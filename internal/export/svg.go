@@ -0,0 +1,206 @@
+package export
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/MagikIO/familiar-says/internal/canvas"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// SVGOptions controls SVG output. The zero value renders light text on a
+// dark background with 14px glyphs.
+type SVGOptions struct {
+	FontFamily string  // CSS font family list (default a monospace stack)
+	FontSize   float64 // Font size in pixels (default 14)
+	Foreground string  // Color for cells without one (default "#e5e5e5")
+	Background string  // Background color; "none" for transparent (default "#1e1e1e")
+	Padding    float64 // Padding around the grid in pixels (default 16, negative for none)
+}
+
+const (
+	defaultFontFamily = `ui-monospace, "SFMono-Regular", Menlo, Consolas, "DejaVu Sans Mono", monospace`
+	defaultForeground = "#e5e5e5"
+	defaultBackground = "#1e1e1e"
+
+	// Monospace cell geometry relative to the font size
+	cellWidthRatio  = 0.6
+	lineHeightRatio = 1.2
+	baselineRatio   = 0.8 // Baseline position within a line
+)
+
+// withDefaults fills in unset options.
+func (o SVGOptions) withDefaults() SVGOptions {
+	if o.FontFamily == "" {
+		o.FontFamily = defaultFontFamily
+	}
+	if o.FontSize <= 0 {
+		o.FontSize = 14
+	}
+	if o.Foreground == "" {
+		o.Foreground = defaultForeground
+	}
+	if o.Background == "" {
+		o.Background = defaultBackground
+	}
+	if o.Padding < 0 {
+		o.Padding = 0
+	} else if o.Padding == 0 {
+		o.Padding = 16
+	}
+	return o
+}
+
+// glyphStyle is the part of a cell's style that SVG text can show.
+type glyphStyle struct {
+	fg        string
+	bold      bool
+	italic    bool
+	underline bool
+	strike    bool
+	faint     bool
+}
+
+// SVG renders a canvas to an SVG image. Cells are placed on a monospace
+// grid, so alignment does not depend on the viewer's font metrics.
+func SVG(c *canvas.Canvas, opts SVGOptions) []byte {
+	var buf bytes.Buffer
+	_ = WriteSVG(&buf, c, opts) // Writes to a bytes.Buffer cannot fail
+	return buf.Bytes()
+}
+
+// WriteSVG renders a canvas to an SVG image written to w.
+func WriteSVG(w io.Writer, c *canvas.Canvas, opts SVGOptions) error {
+	opts = opts.withDefaults()
+	cellW := opts.FontSize * cellWidthRatio
+	lineH := opts.FontSize * lineHeightRatio
+	rows := contentRows(c)
+
+	width := 2*opts.Padding + float64(c.Width)*cellW
+	height := 2*opts.Padding + float64(rows)*lineH
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s">`+"\n",
+		num(width), num(height), num(width), num(height))
+	if opts.Background != "none" {
+		fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", opts.Background)
+	}
+	fmt.Fprintf(&b, `<g font-family="%s" font-size="%s" fill="%s" xml:space="preserve">`+"\n",
+		attr(opts.FontFamily), num(opts.FontSize), opts.Foreground)
+
+	for y := 0; y < rows; y++ {
+		top := opts.Padding + float64(y)*lineH
+		baseline := top + lineH*baselineRatio
+
+		// Background runs
+		for x := 0; x < c.Width; {
+			_, bg := cellColors(c.Cells[y][x].Style, opts.Foreground, "")
+			start := x
+			for x < c.Width && sameBackground(c.Cells[y][x], bg, opts.Foreground) {
+				x++
+			}
+			if bg != "" {
+				fmt.Fprintf(&b, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s"/>`+"\n",
+					num(opts.Padding+float64(start)*cellW), num(top), num(float64(x-start)*cellW), num(lineH), bg)
+			}
+		}
+
+		// Text runs, one glyph per cell
+		for x := 0; x < c.Width; {
+			style := cellGlyphStyle(c.Cells[y][x].Style, opts)
+			var xs []string
+			var text strings.Builder
+			for x < c.Width && cellGlyphStyle(c.Cells[y][x].Style, opts) == style {
+				cell := c.Cells[y][x]
+				if cell.Grapheme != "" && cell.Grapheme != " " && !cell.Transparent {
+					xs = append(xs, num(opts.Padding+float64(x)*cellW))
+					text.WriteString(cell.Grapheme)
+				}
+				x += max(cell.Width(), 1)
+			}
+			if len(xs) > 0 {
+				fmt.Fprintf(&b, `<text x="%s" y="%s"%s>`, strings.Join(xs, " "), num(baseline), style.attrs(opts.Foreground))
+				xml.EscapeText(&b, []byte(text.String()))
+				b.WriteString("</text>\n")
+			}
+		}
+	}
+
+	b.WriteString("</g>\n</svg>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// cellGlyphStyle resolves the text style of a cell.
+func cellGlyphStyle(style lipgloss.Style, opts SVGOptions) glyphStyle {
+	fg, _ := cellColors(style, opts.Foreground, opts.Background)
+	return glyphStyle{
+		fg:        fg,
+		bold:      style.GetBold(),
+		italic:    style.GetItalic(),
+		underline: style.GetUnderline(),
+		strike:    style.GetStrikethrough(),
+		faint:     style.GetFaint(),
+	}
+}
+
+// sameBackground reports whether cell has the background bg.
+func sameBackground(cell canvas.Cell, bg, fg string) bool {
+	_, cellBg := cellColors(cell.Style, fg, "")
+	return cellBg == bg
+}
+
+// attrs returns the SVG attributes for the style.
+func (s glyphStyle) attrs(defaultFg string) string {
+	var b strings.Builder
+	if s.fg != "" && s.fg != defaultFg {
+		fmt.Fprintf(&b, ` fill="%s"`, s.fg)
+	}
+	if s.bold {
+		b.WriteString(` font-weight="bold"`)
+	}
+	if s.italic {
+		b.WriteString(` font-style="italic"`)
+	}
+	if s.faint {
+		b.WriteString(` opacity="0.6"`)
+	}
+	switch {
+	case s.underline && s.strike:
+		b.WriteString(` text-decoration="underline line-through"`)
+	case s.underline:
+		b.WriteString(` text-decoration="underline"`)
+	case s.strike:
+		b.WriteString(` text-decoration="line-through"`)
+	}
+	return b.String()
+}
+
+// contentRows returns the number of rows up to the last non-empty one,
+// matching the trailing line trimming of Canvas.Render.
+func contentRows(c *canvas.Canvas) int {
+	for y := c.Height - 1; y >= 0; y-- {
+		for _, cell := range c.Cells[y] {
+			if !cell.Transparent && cell.Grapheme != " " && cell.Grapheme != "" {
+				return y + 1
+			}
+		}
+	}
+	return 0
+}
+
+// num formats a pixel value with at most two decimals.
+func num(v float64) string {
+	return strconv.FormatFloat(float64(int64(v*100+0.5))/100, 'f', -1, 64)
+}
+
+// attr escapes a value for use in a double quoted attribute.
+func attr(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,67 @@
+package export
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/MagikIO/familiar-says/internal/canvas"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// TestSVG tests the structure and styling of SVG output
+func TestSVG(t *testing.T) {
+	c := canvas.NewCanvas(10, 3)
+	c.DrawString(0, 0, "Hi", lipgloss.NewStyle().Foreground(lipgloss.Color("#ff0000")).Bold(true))
+	c.DrawString(3, 0, "<&>", lipgloss.NewStyle())
+
+	svg := string(SVG(c, SVGOptions{FontSize: 10, Padding: 5}))
+
+	if err := xml.Unmarshal([]byte(svg), new(struct{})); err != nil {
+		t.Fatalf("Output is not valid XML: %v\n%s", err, svg)
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		// Trailing empty rows are trimmed: 10 cells of 6px, 1 row of 12px
+		{"size", `width="70" height="22"`},
+		{"background", `fill="#1e1e1e"`},
+		{"styled run", `<text x="5 11" y="14.6" fill="#ff0000" font-weight="bold">Hi</text>`},
+		{"escaped run", `<text x="23 29 35" y="14.6">&lt;&amp;&gt;</text>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(svg, tt.want) {
+				t.Errorf("SVG doesn't contain %q:\n%s", tt.want, svg)
+			}
+		})
+	}
+}
+
+// TestSVGWideRunes tests that wide runes take two grid cells
+func TestSVGWideRunes(t *testing.T) {
+	c := canvas.NewCanvas(5, 1)
+	c.DrawString(0, 0, "日本!", lipgloss.NewStyle())
+
+	svg := string(SVG(c, SVGOptions{FontSize: 10, Padding: -1}))
+	if !strings.Contains(svg, `<text x="0 12 24" y="9.6">日本!</text>`) {
+		t.Errorf("Wide runes are not on the grid:\n%s", svg)
+	}
+}
+
+// TestSVGCellBackground tests background rectangles and transparency
+func TestSVGCellBackground(t *testing.T) {
+	c := canvas.NewCanvas(4, 1)
+	c.DrawString(1, 0, "ab", lipgloss.NewStyle().Background(lipgloss.Color("4")))
+
+	svg := string(SVG(c, SVGOptions{FontSize: 10, Padding: -1, Background: "none"}))
+	if strings.Contains(svg, `width="100%"`) {
+		t.Error("Transparent background should not draw a page rectangle")
+	}
+	if !strings.Contains(svg, `<rect x="6" y="0" width="12" height="12" fill="#0000ee"/>`) {
+		t.Errorf("Missing cell background:\n%s", svg)
+	}
+}
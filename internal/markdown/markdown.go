@@ -0,0 +1,130 @@
+// Package markdown renders the inline Markdown of message text as ANSI
+// styles, which bubbles draw as styled cells: **bold**, _italic_,
+// ~~strikethrough~~ and `code`, shown inverse. The markers are removed so they
+// take no space, and list bullets are drawn as "•".
+package markdown
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/MagikIO/familiar-says/internal/ascii"
+)
+
+// emphasis is a kind of emphasis and the SGR sequences that turn its style
+// on and off.
+type emphasis struct {
+	pattern   *regexp.Regexp
+	on, off   string
+	intraword bool // Whether the markers may be inside a word, as "*" may but "_" may not
+}
+
+// emphases are tried in order, so that "**" is not taken for two "*".
+var emphases = []emphasis{
+	{regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*`), "\x1b[1m", "\x1b[22m", true},
+	{regexp.MustCompile(`__(\S(?:.*?\S)?)__`), "\x1b[1m", "\x1b[22m", false},
+	{regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`), "\x1b[9m", "\x1b[29m", true},
+	{regexp.MustCompile(`\*(\S(?:.*?\S)?)\*`), "\x1b[3m", "\x1b[23m", true},
+	{regexp.MustCompile(`_(\S(?:.*?\S)?)_`), "\x1b[3m", "\x1b[23m", false},
+}
+
+// Code spans are shown inverse.
+const codeOn, codeOff = "\x1b[7m", "\x1b[27m"
+
+// literal matches text that is not parsed: code spans and URLs, which often
+// contain underscores.
+var literal = regexp.MustCompile("``(.+?)``|`([^`]+)`|https?://[^\\s<>\"()]+")
+
+// bulletItem matches the bullet of an unordered list item.
+var bulletItem = regexp.MustCompile(`^( *)[-*+]( +\S)`)
+
+// placeholder is the first private use rune standing in for literal text
+// while a line is parsed.
+const placeholder = '\uE100'
+
+// escaped replaces backslash escaped markers with private use runes,
+// unescaped turns those into the markers, and literally back into the
+// escaped markers.
+var (
+	escaped   = strings.NewReplacer(`\*`, "\uE000", `\_`, "\uE001", `\~`, "\uE002", "\\`", "\uE003")
+	unescaped = strings.NewReplacer("\uE000", "*", "\uE001", "_", "\uE002", "~", "\uE003", "`")
+	literally = strings.NewReplacer("\uE000", `\*`, "\uE001", `\_`, "\uE002", `\~`, "\uE003", "\\`")
+)
+
+// Render replaces the inline Markdown of each line of text with ANSI styles
+// and the bullets of list items with "•", or "-" in ASCII mode. Markers can
+// be escaped with a backslash.
+func Render(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = renderLine(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderLine renders the inline Markdown of a line.
+func renderLine(line string) string {
+	bullet := "•"
+	if ascii.Enabled() {
+		bullet = "-"
+	}
+	line = bulletItem.ReplaceAllString(line, "${1}"+bullet+"${2}")
+
+	// Set code spans and URLs aside so their markers are kept
+	line = escaped.Replace(line)
+	var literals []string
+	line = literal.ReplaceAllStringFunc(line, func(s string) string {
+		s = literally.Replace(s)
+		if m := literal.FindStringSubmatch(s); m[1] != "" || m[2] != "" {
+			s = codeOn + m[1] + m[2] + codeOff
+		}
+		literals = append(literals, s)
+		return string(placeholder + rune(len(literals)-1))
+	})
+
+	for _, e := range emphases {
+		line = e.apply(line)
+	}
+	line = unescaped.Replace(line)
+
+	for i, s := range literals {
+		line = strings.Replace(line, string(placeholder+rune(i)), s, 1)
+	}
+	return line
+}
+
+// apply styles the text between the markers of e in line.
+func (e emphasis) apply(line string) string {
+	var b strings.Builder
+	last := 0
+	for pos := 0; pos < len(line); {
+		m := e.pattern.FindStringSubmatchIndex(line[pos:])
+		if m == nil {
+			break
+		}
+		start, end := pos+m[0], pos+m[1]
+		if !e.intraword && (wordBefore(line, start) || wordAfter(line, end)) {
+			pos = start + 1 // Markers inside a word, as in snake_case
+			continue
+		}
+		b.WriteString(line[last:start])
+		b.WriteString(e.on + line[pos+m[2]:pos+m[3]] + e.off)
+		last, pos = end, end
+	}
+	b.WriteString(line[last:])
+	return b.String()
+}
+
+// wordBefore reports whether a letter or digit comes before index i of s.
+func wordBefore(s string, i int) bool {
+	r, _ := utf8.DecodeLastRuneInString(s[:i])
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// wordAfter reports whether a letter or digit is at index i of s.
+func wordAfter(s string, i int) bool {
+	r, _ := utf8.DecodeRuneInString(s[i:])
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,61 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/MagikIO/familiar-says/internal/ansi"
+	"github.com/MagikIO/familiar-says/internal/ascii"
+)
+
+// TestRender tests replacing inline Markdown with styles
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"plain", "Just text", "Just text"},
+		{"bold", "a **b c** d", "a \x1b[1mb c\x1b[22m d"},
+		{"bold underscores", "__b__", "\x1b[1mb\x1b[22m"},
+		{"italic", "*a* and _b_", "\x1b[3ma\x1b[23m and \x1b[3mb\x1b[23m"},
+		{"strikethrough", "~~gone~~", "\x1b[9mgone\x1b[29m"},
+		{"code", "run `go test ./...`", "run \x1b[7mgo test ./...\x1b[27m"},
+		{"code keeps markers", "`**not bold**`", "\x1b[7m**not bold**\x1b[27m"},
+		{"double backticks", "``a `b` c``", "\x1b[7ma `b` c\x1b[27m"},
+		{"nested", "**bold _and italic_**", "\x1b[1mbold \x1b[3mand italic\x1b[23m\x1b[22m"},
+		{"snake case", "snake_case_name and _it_", "snake_case_name and \x1b[3mit\x1b[23m"},
+		{"url", "https://example.com/_a_/b", "https://example.com/_a_/b"},
+		{"spaced stars", "2 * 3 * 4", "2 * 3 * 4"},
+		{"escaped", `\*not italic\*`, "*not italic*"},
+		{"unclosed", "**open", "**open"},
+		{"bullets", "- one\n  * two\n+ three", "• one\n  • two\n• three"},
+		{"numbered", "1. **first**", "1. \x1b[1mfirst\x1b[22m"},
+		{"bold line start", "**Note:** hi", "\x1b[1mNote:\x1b[22m hi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(tt.input); got != tt.want {
+				t.Errorf("Render(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRenderASCII tests ASCII list bullets
+func TestRenderASCII(t *testing.T) {
+	defer ascii.SetEnabled(ascii.Enabled())
+	ascii.SetEnabled(true)
+
+	if got := Render("* item"); got != "- item" {
+		t.Errorf("Render in ASCII mode = %q, want %q", got, "- item")
+	}
+}
+
+// TestRenderWidth tests that markers take no space
+func TestRenderWidth(t *testing.T) {
+	got := Render("**bold** _it_ ~~x~~ `c`")
+	if w := ansi.StringWidth(got); w != len("bold it x c") {
+		t.Errorf("Rendered width = %d, want %d", w, len("bold it x c"))
+	}
+}
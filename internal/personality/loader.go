@@ -0,0 +1,344 @@
+package personality
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/MagikIO/familiar-says/internal/canvas"
+	"github.com/MagikIO/familiar-says/internal/config"
+	customerrors "github.com/MagikIO/familiar-says/internal/errors"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ThemeFile is the JSON representation of a user-defined theme.
+// Unset fields are inherited from the theme named by Inherits.
+type ThemeFile struct {
+	Name           string                    `json:"name"`
+	Inherits       string                    `json:"inherits,omitempty"` // Theme to inherit from (default "default")
+	PrimaryColor   string                    `json:"primaryColor,omitempty"`
+	SecondaryColor string                    `json:"secondaryColor,omitempty"`
+	AccentColor    string                    `json:"accentColor,omitempty"`
+	Bubble         *StyleFile                `json:"bubble,omitempty"`
+	Character      *StyleFile                `json:"character,omitempty"`
+	Expressions    map[string]ExpressionFile `json:"expressions,omitempty"`
+}
+
+// StyleFile describes a text style in a theme file.
+type StyleFile struct {
+	Foreground string `json:"foreground,omitempty"`
+	Background string `json:"background,omitempty"`
+	Bold       *bool  `json:"bold,omitempty"`
+	Italic     *bool  `json:"italic,omitempty"`
+	Underline  *bool  `json:"underline,omitempty"`
+}
+
+// ExpressionFile describes a mood expression in a theme file.
+// An empty field keeps the inherited value.
+type ExpressionFile struct {
+	Eyes   string `json:"eyes,omitempty"`
+	Tongue string `json:"tongue,omitempty"`
+}
+
+var (
+	// customThemes stores user-defined themes by name
+	customThemes = make(map[string]Theme)
+
+	// userThemesOnce guards loading themes from the user's config directory
+	userThemesOnce sync.Once
+
+	// userThemesErr is the error from loading the user's themes, if any
+	userThemesErr error
+)
+
+// builtinTheme returns the builtin theme with the given name.
+func builtinTheme(name string) (Theme, bool) {
+	switch name {
+	case "default":
+		return ThemeDefault, true
+	case "rainbow":
+		return ThemeRainbow, true
+	case "cyber":
+		return ThemeCyber, true
+	case "retro":
+		return ThemeRetro, true
+	default:
+		return Theme{}, false
+	}
+}
+
+// builtinThemeNames lists the builtin themes in display order.
+var builtinThemeNames = []string{"default", "rainbow", "cyber", "retro"}
+
+// loadUserThemes loads themes from $XDG_CONFIG_HOME/familiar-says/themes once.
+func loadUserThemes() {
+	userThemesOnce.Do(func() {
+		dir, err := config.Dir()
+		if err != nil {
+			return
+		}
+		userThemesErr = LoadCustomThemesFromDir(filepath.Join(dir, "themes"))
+	})
+}
+
+// UserThemesError returns the error from loading the themes in the user's
+// config directory, or nil if they loaded or have not been needed yet. The
+// themes that loaded are available either way.
+func UserThemesError() error {
+	return userThemesErr
+}
+
+// lookupTheme returns a builtin or custom theme by name.
+func lookupTheme(name string) (Theme, bool) {
+	name = strings.ToLower(name)
+	if theme, ok := builtinTheme(name); ok {
+		return theme, true
+	}
+	loadUserThemes()
+	theme, ok := customThemes[name]
+	return theme, ok
+}
+
+// RegisterTheme adds a theme to the registry, replacing any custom theme
+// with the same name. Builtin themes cannot be replaced.
+func RegisterTheme(theme Theme) {
+	customThemes[strings.ToLower(theme.Name)] = theme
+}
+
+// ResetThemes removes all custom themes so they are reloaded on next use.
+// This is primarily useful for testing.
+func ResetThemes() {
+	customThemes = make(map[string]Theme)
+	userThemesOnce = sync.Once{}
+	userThemesErr = nil
+}
+
+// LoadCustomThemesFromDir loads and registers every *.json theme in dir.
+// Themes may inherit from builtins or from other themes in the same directory.
+// A missing directory is not an error. Files that cannot be loaded are
+// reported together, and the other themes are still registered.
+func LoadCustomThemesFromDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil // Directory doesn't exist, that's fine
+		}
+		return err
+	}
+
+	var errs []string
+	files := make(map[string]*ThemeFile)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		file, err := readThemeFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if IsBuiltinTheme(file.Name) {
+			errs = append(errs, fmt.Sprintf("theme file %q cannot redefine builtin theme %q", entry.Name(), file.Name))
+			continue
+		}
+		files[strings.ToLower(file.Name)] = file
+	}
+
+	for name := range files {
+		theme, err := resolveThemeFile(name, files, nil)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		RegisterTheme(theme)
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return fmt.Errorf("failed to load themes from %s: %s", dir, strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// LoadThemeFromFile loads a theme from a JSON file. The theme may inherit
+// from a builtin or an already registered custom theme.
+func LoadThemeFromFile(path string) (Theme, error) {
+	file, err := readThemeFile(path)
+	if err != nil {
+		return Theme{}, err
+	}
+	if IsBuiltinTheme(file.Name) {
+		return Theme{}, fmt.Errorf("theme file %q cannot redefine builtin theme %q", path, file.Name)
+	}
+	loadUserThemes()
+	name := strings.ToLower(file.Name)
+	return resolveThemeFile(name, map[string]*ThemeFile{name: file}, nil)
+}
+
+// readThemeFile reads and decodes a theme file, naming it after the file
+// if the name is not set.
+func readThemeFile(path string) (*ThemeFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read theme file %q: %w", path, err)
+	}
+
+	var file ThemeFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse theme JSON from %q: %w", path, err)
+	}
+	if file.Name == "" {
+		file.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return &file, nil
+}
+
+// resolveThemeFile builds the named theme from files, resolving its parent
+// first. seen tracks the inheritance chain to detect cycles.
+func resolveThemeFile(name string, files map[string]*ThemeFile, seen []string) (Theme, error) {
+	file, ok := files[name]
+	if !ok {
+		// Look up registered themes directly; this may run while the user
+		// themes are being loaded
+		if theme, ok := builtinTheme(name); ok {
+			return theme, nil
+		}
+		if theme, ok := customThemes[name]; ok {
+			return theme, nil
+		}
+		return Theme{}, customerrors.NewValidationError("inherits", name, "unknown theme")
+	}
+
+	if slices.Contains(seen, name) {
+		return Theme{}, fmt.Errorf("theme %q has an inheritance cycle: %s -> %s", name, strings.Join(seen, " -> "), name)
+	}
+
+	parentName := strings.ToLower(file.Inherits)
+	if parentName == "" {
+		parentName = "default"
+	}
+	parent, err := resolveThemeFile(parentName, files, append(seen, name))
+	if err != nil {
+		return Theme{}, err
+	}
+
+	return file.apply(parent)
+}
+
+// apply returns a copy of parent with the file's overrides applied.
+func (f *ThemeFile) apply(parent Theme) (Theme, error) {
+	theme := parent
+	theme.Name = strings.ToLower(f.Name)
+
+	for _, c := range []struct {
+		value  string
+		target *lipgloss.Color
+	}{
+		{f.PrimaryColor, &theme.PrimaryColor},
+		{f.SecondaryColor, &theme.SecondaryColor},
+		{f.AccentColor, &theme.AccentColor},
+	} {
+		if c.value == "" {
+			continue
+		}
+		if !canvas.ValidateColor(c.value) {
+			return Theme{}, customerrors.NewColorParseError(c.value, customerrors.ErrInvalidColorFormat)
+		}
+		*c.target = canvas.ParseColor(c.value)
+	}
+
+	var err error
+	if theme.BubbleStyle, err = f.Bubble.apply(parent.BubbleStyle); err != nil {
+		return Theme{}, err
+	}
+	if theme.CharacterStyle, err = f.Character.apply(parent.CharacterStyle); err != nil {
+		return Theme{}, err
+	}
+
+	// Copy the expressions so the parent's map is never modified
+	theme.Expressions = make(map[Mood]Expression, len(parent.Expressions)+len(f.Expressions))
+	for mood, expr := range parent.Expressions {
+		theme.Expressions[mood] = expr
+	}
+	for mood, override := range f.Expressions {
+		key := Mood(strings.ToLower(mood))
+		expr := theme.Expressions[key]
+		if override.Eyes != "" {
+			expr.Eyes = override.Eyes
+		}
+		if override.Tongue != "" {
+			expr.Tongue = override.Tongue
+		}
+		theme.Expressions[key] = expr
+	}
+
+	return theme, nil
+}
+
+// apply returns base with the style's overrides applied.
+func (s *StyleFile) apply(base lipgloss.Style) (lipgloss.Style, error) {
+	if s == nil {
+		return base, nil
+	}
+
+	style := base
+	for _, c := range []string{s.Foreground, s.Background} {
+		if !canvas.ValidateColor(c) {
+			return style, customerrors.NewColorParseError(c, customerrors.ErrInvalidColorFormat)
+		}
+	}
+	if s.Foreground != "" {
+		style = style.Foreground(canvas.ParseColor(s.Foreground))
+	}
+	if s.Background != "" {
+		style = style.Background(canvas.ParseColor(s.Background))
+	}
+	if s.Bold != nil {
+		style = style.Bold(*s.Bold)
+	}
+	if s.Italic != nil {
+		style = style.Italic(*s.Italic)
+	}
+	if s.Underline != nil {
+		style = style.Underline(*s.Underline)
+	}
+	return style, nil
+}
+
+// GetOrLoadTheme returns a theme by name, or loads it from a file if
+// nameOrPath ends with .json or contains a path separator.
+func GetOrLoadTheme(nameOrPath string) (Theme, error) {
+	if strings.HasSuffix(strings.ToLower(nameOrPath), ".json") || strings.ContainsRune(nameOrPath, filepath.Separator) {
+		return LoadThemeFromFile(nameOrPath)
+	}
+
+	if theme, ok := lookupTheme(nameOrPath); ok {
+		return theme, nil
+	}
+	return Theme{}, customerrors.NewValidationError("theme", nameOrPath, "unknown theme. Use --list-themes to see available themes")
+}
+
+// IsBuiltinTheme reports whether name is one of the builtin themes.
+func IsBuiltinTheme(name string) bool {
+	_, ok := builtinTheme(strings.ToLower(name))
+	return ok
+}
+
+// customThemeNames returns the sorted names of custom themes that do not
+// collide with a builtin.
+func customThemeNames() []string {
+	loadUserThemes()
+	names := make([]string, 0, len(customThemes))
+	for name := range customThemes {
+		if _, ok := builtinTheme(name); !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
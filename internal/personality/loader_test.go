@@ -0,0 +1,200 @@
+package personality
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// isolateThemes points the user config directory at a temporary directory
+// and returns its themes directory.
+func isolateThemes(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", root)
+	ResetThemes()
+	t.Cleanup(ResetThemes)
+	return filepath.Join(root, "familiar-says", "themes")
+}
+
+// writeTheme writes a theme JSON file into dir.
+func writeTheme(t *testing.T, dir, filename, content string) string {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	path := filepath.Join(dir, filename)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write theme: %v", err)
+	}
+	return path
+}
+
+// TestUserThemeInheritance tests that user themes override only what they set
+func TestUserThemeInheritance(t *testing.T) {
+	dir := isolateThemes(t)
+	writeTheme(t, dir, "acme.json", `{
+		"name": "acme",
+		"inherits": "cyber",
+		"primaryColor": "#FF6600",
+		"bubble": {"foreground": "orange", "italic": true},
+		"expressions": {"happy": {"eyes": "$$"}}
+	}`)
+
+	theme := GetTheme("acme")
+	if theme.Name != "acme" {
+		t.Fatalf("Expected theme 'acme', got %q", theme.Name)
+	}
+	if theme.PrimaryColor != lipgloss.Color("#FF6600") {
+		t.Errorf("PrimaryColor = %q, want #FF6600", theme.PrimaryColor)
+	}
+	if theme.SecondaryColor != ThemeCyber.SecondaryColor {
+		t.Errorf("SecondaryColor = %q, want inherited %q", theme.SecondaryColor, ThemeCyber.SecondaryColor)
+	}
+	if !theme.BubbleStyle.GetItalic() {
+		t.Error("Expected italic bubble style")
+	}
+	if !theme.BubbleStyle.GetBold() {
+		t.Error("Expected bold inherited from cyber bubble style")
+	}
+
+	if got := theme.GetExpression(MoodHappy); got.Eyes != "$$" || got.Tongue != ThemeCyber.Expressions[MoodHappy].Tongue {
+		t.Errorf("Happy expression = %+v, want eyes $$ with inherited tongue", got)
+	}
+	if got := theme.GetExpression(MoodSad); got != ThemeCyber.Expressions[MoodSad] {
+		t.Errorf("Sad expression = %+v, want inherited %+v", got, ThemeCyber.Expressions[MoodSad])
+	}
+
+	// The builtin theme must be left untouched
+	if ThemeCyber.Expressions[MoodHappy].Eyes == "$$" {
+		t.Error("Overriding an expression modified the builtin theme")
+	}
+}
+
+// TestUserThemeChain tests inheritance between user themes
+func TestUserThemeChain(t *testing.T) {
+	dir := isolateThemes(t)
+	writeTheme(t, dir, "base.json", `{"inherits": "retro", "accentColor": "1"}`)
+	writeTheme(t, dir, "child.json", `{"name": "child", "inherits": "base", "expressions": {"sad": {"eyes": ";;"}}}`)
+
+	theme := GetTheme("child")
+	if theme.AccentColor != lipgloss.Color("1") {
+		t.Errorf("AccentColor = %q, want inherited 1", theme.AccentColor)
+	}
+	if theme.GetExpression(MoodSad).Eyes != ";;" {
+		t.Errorf("Sad eyes = %q, want ;;", theme.GetExpression(MoodSad).Eyes)
+	}
+	if theme.GetExpression(MoodHappy) != ThemeRetro.Expressions[MoodHappy] {
+		t.Errorf("Happy expression should be inherited from retro")
+	}
+
+	all := AllThemes()
+	want := []string{"default", "rainbow", "cyber", "retro", "base", "child"}
+	if !slices.Equal(all, want) {
+		t.Errorf("AllThemes() = %v, want %v", all, want)
+	}
+}
+
+// TestLoadCustomThemesFromDirErrors tests invalid theme directories
+func TestLoadCustomThemesFromDirErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name:  "cycle",
+			files: map[string]string{"a.json": `{"inherits": "b"}`, "b.json": `{"inherits": "a"}`},
+			want:  "cycle",
+		},
+		{
+			name:  "unknown parent",
+			files: map[string]string{"a.json": `{"inherits": "nope"}`},
+			want:  "unknown theme",
+		},
+		{
+			name:  "invalid color",
+			files: map[string]string{"a.json": `{"bubble": {"foreground": "not-a-color"}}`},
+			want:  "not-a-color",
+		},
+		{
+			name:  "builtin name",
+			files: map[string]string{"cyber.json": `{"primaryColor": "1"}`},
+			want:  "builtin",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateThemes(t)
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeTheme(t, dir, name, content)
+			}
+			err := LoadCustomThemesFromDir(dir)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// TestLoadCustomThemesFromDirPartial tests that broken theme files do not
+// keep the others from loading
+func TestLoadCustomThemesFromDirPartial(t *testing.T) {
+	isolateThemes(t)
+	dir := t.TempDir()
+	writeTheme(t, dir, "good.json", `{"primaryColor": "1"}`)
+	writeTheme(t, dir, "broken.json", `{"primaryColor": `)
+	writeTheme(t, dir, "cyber.json", `{"primaryColor": "1"}`)
+
+	err := LoadCustomThemesFromDir(dir)
+	if err == nil || !strings.Contains(err.Error(), "broken.json") || !strings.Contains(err.Error(), "builtin") {
+		t.Errorf("Expected errors for broken.json and cyber.json, got %v", err)
+	}
+	if _, err := GetOrLoadTheme("good"); err != nil {
+		t.Errorf("Expected the valid theme to load, got %v", err)
+	}
+}
+
+// TestUserThemesError tests keeping the error from loading user themes
+func TestUserThemesError(t *testing.T) {
+	dir := isolateThemes(t)
+	writeTheme(t, dir, "good.json", `{"primaryColor": "1"}`)
+	writeTheme(t, dir, "broken.json", `not json`)
+
+	if _, err := GetOrLoadTheme("good"); err != nil {
+		t.Fatalf("Expected the valid user theme to load, got %v", err)
+	}
+	if err := UserThemesError(); err == nil || !strings.Contains(err.Error(), "broken.json") {
+		t.Errorf("UserThemesError() = %v, want an error for broken.json", err)
+	}
+}
+
+// TestGetOrLoadTheme tests loading themes by name and by path
+func TestGetOrLoadTheme(t *testing.T) {
+	isolateThemes(t)
+	path := writeTheme(t, t.TempDir(), "brand.json", `{"inherits": "rainbow", "character": {"bold": true}}`)
+
+	theme, err := GetOrLoadTheme(path)
+	if err != nil {
+		t.Fatalf("GetOrLoadTheme(%q) failed: %v", path, err)
+	}
+	if theme.Name != "brand" {
+		t.Errorf("Expected name 'brand' from filename, got %q", theme.Name)
+	}
+	if !theme.CharacterStyle.GetBold() {
+		t.Error("Expected bold character style")
+	}
+
+	if _, err := GetOrLoadTheme("Cyber"); err != nil {
+		t.Errorf("GetOrLoadTheme(Cyber) failed: %v", err)
+	}
+	if _, err := GetOrLoadTheme("missing"); err == nil {
+		t.Error("Expected error for unknown theme")
+	}
+}
@@ -1,6 +1,8 @@
 package personality
 
 import (
+	"slices"
+
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -122,23 +124,19 @@ var (
 	}
 )
 
-// GetTheme returns a theme by name
+// GetTheme returns a builtin or custom theme by name, falling back to the
+// default theme for unknown names.
 func GetTheme(name string) Theme {
-	switch name {
-	case "rainbow":
-		return ThemeRainbow
-	case "cyber":
-		return ThemeCyber
-	case "retro":
-		return ThemeRetro
-	default:
-		return ThemeDefault
+	if theme, ok := lookupTheme(name); ok {
+		return theme
 	}
+	return ThemeDefault
 }
 
-// AllThemes returns a list of all available themes
+// AllThemes returns the names of all available themes: the builtins first,
+// followed by custom themes in alphabetical order.
 func AllThemes() []string {
-	return []string{"default", "rainbow", "cyber", "retro"}
+	return append(slices.Clone(builtinThemeNames), customThemeNames()...)
 }
 
 // AllMoods returns a list of all available moods
@@ -0,0 +1,363 @@
+// Package textwrap wraps message text to the width of a speech bubble. Line
+// breaks and blank lines in the text are kept, tabs are expanded, and words
+// too long for a line are broken. Escape sequences take no space, and OSC 8
+// hyperlinks are never broken.
+package textwrap
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/MagikIO/familiar-says/internal/ansi"
+)
+
+// TabWidth is the distance between tab stops.
+const TabWidth = 8
+
+// hyphenate is whether words broken across lines are marked with a hyphen.
+var hyphenate atomic.Bool
+
+// SetHyphenate turns hyphens at the end of broken words on or off.
+func SetHyphenate(on bool) {
+	hyphenate.Store(on)
+}
+
+// Hyphenate reports whether broken words are marked with a hyphen.
+func Hyphenate() bool {
+	return hyphenate.Load()
+}
+
+// piece is a word of a line with the spaces before it.
+type piece struct {
+	gap   int    // Spaces before the word
+	text  string // The word, with its escape sequences
+	width int    // Display width of the word
+	link  bool   // Whether the word is in a hyperlink and must not be broken
+}
+
+// line is a wrapped line as the words it is made of.
+type line struct {
+	indent int // Hanging indent before the first word
+	pieces []piece
+	width  int
+	last   bool // Whether the line ends a line of the text
+}
+
+// String joins the words of the line with the spaces between them.
+func (l line) String() string {
+	var b strings.Builder
+	b.WriteString(pad(l.indent))
+	for _, p := range l.pieces {
+		b.WriteString(strings.Repeat(" ", p.gap))
+		b.WriteString(p.text)
+	}
+	return b.String()
+}
+
+// Alignment is how wrapped lines are placed within the width of a bubble.
+type Alignment string
+
+const (
+	AlignLeft    Alignment = "left"
+	AlignCenter  Alignment = "center"
+	AlignRight   Alignment = "right"
+	AlignJustify Alignment = "justify" // Spread words to fill lines, except the last of each paragraph
+)
+
+// Alignments returns all alignments.
+func Alignments() []Alignment {
+	return []Alignment{AlignLeft, AlignCenter, AlignRight, AlignJustify}
+}
+
+// ParseAlignment returns the alignment named s, ignoring case, and whether
+// there is one. An empty name is left alignment.
+func ParseAlignment(s string) (Alignment, bool) {
+	align := Alignment(strings.ToLower(strings.TrimSpace(s)))
+	if align == "" {
+		return AlignLeft, true
+	}
+	return align, slices.Contains(Alignments(), align)
+}
+
+// Wrap wraps text into lines no wider than width. Each line of the text is
+// wrapped on its own, keeping its indentation and the spacing between its
+// words; trailing line breaks are dropped. Words wider than a line are
+// broken, unless they are hyperlinks, which are left whole. Bare URLs are
+// broken like any other word.
+func Wrap(text string, width int) []string {
+	wrapped := wrap(text, width, false)
+	lines := make([]string, len(wrapped))
+	for i, l := range wrapped {
+		lines[i] = l.String()
+	}
+	return lines
+}
+
+// WrapAligned wraps text like Wrap and aligns the lines within the width of
+// the widest, padding them with spaces so all are equally wide. Unknown
+// alignments are treated as left alignment.
+func WrapAligned(text string, width int, align Alignment) []string {
+	return alignLines(wrap(text, width, false), align)
+}
+
+// WrapLists wraps and aligns text like WrapAligned, and indents the wrapped
+// lines of list items to the text after their bullet or number.
+func WrapLists(text string, width int, align Alignment) []string {
+	return alignLines(wrap(text, width, true), align)
+}
+
+// alignLines aligns wrapped lines and pads them to the width of the widest.
+// Styles are closed at the end of each line and reopened on the next, so the
+// padding is never styled.
+func alignLines(wrapped []line, align Alignment) []string {
+	widest := 0
+	lines := make([]string, len(wrapped))
+	for i, l := range wrapped {
+		widest = max(widest, l.width)
+		lines[i] = l.String()
+	}
+	for i, l := range wrapped {
+		if align == AlignJustify && !l.last {
+			lines[i] = justify(l, widest-l.width)
+		}
+	}
+
+	lines = ansi.CarryStyles(lines)
+	for i, l := range lines {
+		extra := widest - ansi.StringWidth(l)
+		switch align {
+		case AlignCenter:
+			lines[i] = pad(extra/2) + l + pad(extra-extra/2)
+		case AlignRight:
+			lines[i] = pad(extra) + l
+		default:
+			lines[i] = l + pad(extra)
+		}
+	}
+	return lines
+}
+
+// pad returns n spaces.
+func pad(n int) string {
+	return strings.Repeat(" ", n)
+}
+
+// justify widens the spaces between the words of a line by extra columns in
+// total, spread as evenly as possible with the wider gaps first. A line with
+// a single word is left as it is.
+func justify(l line, extra int) string {
+	gaps := 0
+	for i, p := range l.pieces {
+		if i > 0 && p.gap > 0 {
+			gaps++
+		}
+	}
+	if gaps == 0 {
+		return l.String()
+	}
+
+	pieces := slices.Clone(l.pieces)
+	n := 0
+	for i := 1; i < len(pieces); i++ {
+		if pieces[i].gap == 0 {
+			continue
+		}
+		pieces[i].gap += extra / gaps
+		if n < extra%gaps {
+			pieces[i].gap++
+		}
+		n++
+	}
+	return line{indent: l.indent, pieces: pieces}.String()
+}
+
+// listItem matches the bullet or number of a list item and the spaces after
+// it.
+var listItem = regexp.MustCompile(`^ *([-*+\x{2022}]|\d{1,9}[.)]) +`)
+
+// hangingIndent returns the indent of the wrapped lines of line if it is a
+// list item, and 0 otherwise.
+func hangingIndent(line string) int {
+	marker := listItem.FindString(ansi.Strip(line))
+	if marker == "" || strings.TrimSpace(ansi.Strip(line)) == strings.TrimSpace(marker) {
+		return 0
+	}
+	return ansi.StringWidth(marker)
+}
+
+// wrap wraps text into lines of words. If lists is set, the wrapped lines of
+// list items have a hanging indent.
+func wrap(text string, width int, lists bool) []line {
+	text = strings.TrimRight(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if strings.TrimSpace(ansi.Strip(text)) == "" {
+		return nil
+	}
+	width = max(width, 1)
+
+	var lines []line
+	inLink := false
+	for _, text := range strings.Split(text, "\n") {
+		text = expandTabs(text)
+		hang := 0
+		if lists {
+			hang = hangingIndent(text)
+		}
+		var pieces []piece
+		pieces, inLink = split(text, inLink)
+		lines = append(lines, wrapLine(pieces, width, hang, Hyphenate())...)
+	}
+	return lines
+}
+
+// expandTabs replaces tabs with spaces up to the next tab stop.
+func expandTabs(line string) string {
+	if !strings.ContainsRune(line, '\t') {
+		return line
+	}
+
+	var b strings.Builder
+	col := 0
+	for _, t := range ansi.Tokenize(line) {
+		if t.Escape {
+			b.WriteString(t.Text)
+			continue
+		}
+		for g, w := range ansi.Graphemes(t.Text) {
+			if g == "\t" {
+				n := TabWidth - col%TabWidth
+				b.WriteString(strings.Repeat(" ", n))
+				col += n
+				continue
+			}
+			b.WriteString(g)
+			col += w
+		}
+	}
+	return b.String()
+}
+
+// split splits a line into words at spaces outside hyperlinks. inLink tells
+// whether the line starts inside a hyperlink; split returns whether it ends
+// in one.
+func split(line string, inLink bool) ([]piece, bool) {
+	var pieces []piece
+	var word strings.Builder
+	current := piece{link: inLink}
+	for _, t := range ansi.Tokenize(line) {
+		if t.Escape {
+			if url, ok := ansi.HyperlinkTarget(t.Text); ok {
+				inLink = url != ""
+				current.link = current.link || inLink
+			}
+			word.WriteString(t.Text)
+			continue
+		}
+		for g, w := range ansi.Graphemes(t.Text) {
+			if g != " " || inLink {
+				word.WriteString(g)
+				current.width += w
+				continue
+			}
+			if word.Len() > 0 {
+				current.text = word.String()
+				pieces = append(pieces, current)
+				word.Reset()
+				current = piece{link: inLink}
+			}
+			current.gap++
+		}
+	}
+	if word.Len() > 0 {
+		current.text = word.String()
+		pieces = append(pieces, current)
+	}
+	return pieces, inLink
+}
+
+// wrapLine lays out the words of a line greedily. Spaces where the line is
+// wrapped are dropped; the indentation of the first line is kept if the first
+// word fits after it, and the wrapped lines are indented by hang columns if
+// there is room.
+func wrapLine(pieces []piece, width, hang int, hyphen bool) []line {
+	if hang > width/2 {
+		hang = 0
+	}
+	next := line{indent: hang, width: hang}
+
+	var lines []line
+	var current line
+	for _, p := range pieces {
+		empty := len(current.pieces) == 0
+		if p.width == 0 || (empty && len(lines) > 0) {
+			p.gap = 0 // Escape sequences need no space, and wrapped lines no indent
+		}
+		if current.width+p.gap+p.width > width && !empty {
+			lines = append(lines, current)
+			current = next
+			p.gap = 0
+		}
+		if current.width+p.gap+p.width > width {
+			p.gap = 0
+		}
+
+		if p.width <= width-current.width-p.gap || p.link {
+			current.pieces = append(current.pieces, p)
+			current.width += p.gap + p.width
+			continue
+		}
+
+		for i, chunk := range breakWord(p.text, width-hang, hyphen) {
+			if i > 0 {
+				lines = append(lines, current)
+				current = next
+			}
+			current.pieces = append(current.pieces, piece{text: chunk})
+			current.width += ansi.StringWidth(chunk)
+		}
+	}
+	current.last = true
+	return append(lines, current)
+}
+
+// breakWord breaks a word between grapheme clusters into chunks no wider than
+// width, all but the last ending in a hyphen if hyphen is set and there is
+// room for one.
+func breakWord(word string, width int, hyphen bool) []string {
+	reserve := 0
+	if hyphen && width > 1 {
+		reserve = 1
+	}
+	remaining := ansi.StringWidth(word)
+
+	var chunks []string
+	var chunk strings.Builder
+	col := 0
+	for _, t := range ansi.Tokenize(word) {
+		if t.Escape {
+			chunk.WriteString(t.Text)
+			continue
+		}
+		for g, w := range ansi.Graphemes(t.Text) {
+			limit := width - reserve
+			if g == "-" {
+				limit = width // A hyphen in the word can end the chunk
+			}
+			// Break when the rest of the word does not fit and this rune
+			// would leave no room for the hyphen
+			if col > 0 && col+remaining > width && col+w > limit {
+				if reserve > 0 && !strings.HasSuffix(chunk.String(), "-") {
+					chunk.WriteByte('-')
+				}
+				chunks = append(chunks, chunk.String())
+				chunk.Reset()
+				col = 0
+			}
+			chunk.WriteString(g)
+			col += w
+			remaining -= w
+		}
+	}
+	return append(chunks, chunk.String())
+}
@@ -0,0 +1,168 @@
+package textwrap
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/MagikIO/familiar-says/internal/ansi"
+)
+
+// TestWrap tests wrapping text to a width
+func TestWrap(t *testing.T) {
+	link := ansi.Hyperlink("the run book", "https://example.com/a/very/long/url")
+
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  []string
+	}{
+		{"short", "Hello", 40, []string{"Hello"}},
+		{"empty", "", 40, []string{}},
+		{"only spaces", "  \n ", 40, []string{}},
+		{"exact fit", "12345", 5, []string{"12345"}},
+		{"greedy", "Hello World Test", 8, []string{"Hello", "World", "Test"}},
+		{"line breaks", "one\ntwo three", 40, []string{"one", "two three"}},
+		{"blank lines", "para one\n\npara two", 40, []string{"para one", "", "para two"}},
+		{"trailing newline", "done\n\n", 40, []string{"done"}},
+		{"crlf", "a\r\nb", 40, []string{"a", "b"}},
+		{"indentation", "list:\n  - item one", 40, []string{"list:", "  - item one"}},
+		{"wrapped indentation", "  one two", 5, []string{"  one", "two"}},
+		{"spacing kept", "a  b", 40, []string{"a  b"}},
+		{"tabs", "a\tb\n\t-- Author", 40, []string{"a       b", "        -- Author"}},
+		{"long word", "see 0123456789abcdef", 8, []string{"see", "01234567", "89abcdef"}},
+		{"long word continues", "0123456789 ok", 8, []string{"01234567", "89 ok"}},
+		{"wide runes", "日本語の文章", 5, []string{"日本", "語の", "文章"}},
+		{"grapheme clusters", "e\u0301e\u0301e\u0301", 2, []string{"e\u0301e\u0301", "e\u0301"}},
+		{"emoji sequences", "\U0001f469\u200d\U0001f4bb\U0001f469\u200d\U0001f4bb", 3, []string{"\U0001f469\u200d\U0001f4bb", "\U0001f469\u200d\U0001f4bb"}},
+		{"styled", "\x1b[31mred words\x1b[0m", 5, []string{"\x1b[31mred", "words\x1b[0m"}},
+		{"styled long word", "\x1b[1mabcdef\x1b[0m", 3, []string{"\x1b[1mabc", "def\x1b[0m"}},
+		{"hyperlink", "Read " + link + " today", 14, []string{"Read", link, "today"}},
+		{"bare URL", "see https://example.com/a/long/path ok", 14, []string{"see", "https://exampl", "e.com/a/long/p", "ath ok"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Wrap(tt.text, tt.width)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Wrap(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestWrapHyphenate tests marking broken words with hyphens
+func TestWrapHyphenate(t *testing.T) {
+	defer SetHyphenate(Hyphenate())
+	SetHyphenate(true)
+
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  []string
+	}{
+		{"broken word", "supercalifragilistic", 8, []string{"superca-", "lifragi-", "listic"}},
+		{"existing hyphen", "abcdef-ghijkl", 7, []string{"abcdef-", "ghijkl"}},
+		{"fits without hyphen", "abcdefgh", 8, []string{"abcdefgh"}},
+		{"no room", "abc", 1, []string{"a", "b", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Wrap(tt.text, tt.width)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Wrap(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestWrapWidth tests that no wrapped line is wider than the width
+func TestWrapWidth(t *testing.T) {
+	text := "A commit message:\n\n\tFix the frobnicator when\n\tits widget is a1b2c3d4e5f6a7b8c9d0 long."
+	for width := 1; width <= 30; width++ {
+		for _, line := range Wrap(text, width) {
+			if w := ansi.StringWidth(line); w > width {
+				t.Errorf("Width %d: line %q is %d wide", width, line, w)
+			}
+		}
+	}
+}
+
+// TestWrapAligned tests aligning wrapped lines
+func TestWrapAligned(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		align Alignment
+		want  []string
+	}{
+		{"left", "aa b ccc dd e", AlignLeft, []string{"aa b  ", "ccc dd", "e     "}},
+		{"center", "aa b ccc dd e", AlignCenter, []string{" aa b ", "ccc dd", "  e   "}},
+		{"right", "aa b ccc dd e", AlignRight, []string{"  aa b", "ccc dd", "     e"}},
+		{"justify", "aaaaaa a b cccc", AlignJustify, []string{"aaaaaa", "a    b", "cccc  "}},
+		{"justify wider gaps first", "aaaaaa a b c ddddd", AlignJustify, []string{"aaaaaa", "a  b c", "ddddd "}},
+		{"justify paragraph ends", "a b\nc d e f", AlignJustify, []string{"a b  ", "c d e", "f    "}},
+		{"unknown", "aa b ccc", Alignment("diagonal"), []string{"aa b", "ccc "}},
+		{"empty", "", AlignCenter, []string{}},
+		{"styles end before padding", "\x1b[7mab cd ef\x1b[0m", AlignLeft, []string{"\x1b[7mab cd\x1b[0m", "\x1b[7mef\x1b[0m   "}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WrapAligned(tt.text, 6, tt.align)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("WrapAligned(%q, %q) = %q, want %q", tt.text, tt.align, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseAlignment tests parsing alignment names
+func TestParseAlignment(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Alignment
+		ok    bool
+	}{
+		{"", AlignLeft, true},
+		{"center", AlignCenter, true},
+		{"Justify", AlignJustify, true},
+		{"middle", Alignment("middle"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := ParseAlignment(tt.input)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("ParseAlignment(%q) = %q, %v, want %q, %v", tt.input, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+// TestWrapLists tests the hanging indent of list items
+func TestWrapLists(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"bullet", "- one two three", []string{"- one two", "  three  "}},
+		{"numbered", "10. one two three", []string{"10. one  ", "    two  ", "    three"}},
+		{"indented", "  \u2022 one two", []string{"  \u2022 one", "    two"}},
+		{"broken word", "* abcdefghijkl", []string{"*         ", "  abcdefgh", "  ijkl    "}},
+		{"not a list", "one two three", []string{"one two", "three  "}},
+		{"marker only", "-", []string{"-"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WrapLists(tt.text, 10, AlignLeft)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("WrapLists(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
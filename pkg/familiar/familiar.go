@@ -0,0 +1,517 @@
+// Package familiar is the public Go API for rendering familiar-says output.
+//
+// It wraps the internal canvas, character, bubble and effects packages behind
+// a single Options struct so other programs can embed familiars without
+// shelling out to the familiar-says binary:
+//
+//	lines, err := familiar.Render("Hello!", familiar.Options{Character: "cat", Mood: "happy"})
+//
+// Builtin characters are embedded in the package, so no setup is required.
+package familiar
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MagikIO/familiar-says/internal/animation"
+	"github.com/MagikIO/familiar-says/internal/bubble"
+	"github.com/MagikIO/familiar-says/internal/canvas"
+	"github.com/MagikIO/familiar-says/internal/character"
+	"github.com/MagikIO/familiar-says/internal/effects"
+	customerrors "github.com/MagikIO/familiar-says/internal/errors"
+	"github.com/MagikIO/familiar-says/internal/export"
+	"github.com/MagikIO/familiar-says/internal/personality"
+	"github.com/MagikIO/familiar-says/internal/textwrap"
+	"golang.org/x/term"
+)
+
+// Canvas is a 2D grid of styled cells holding a composed scene.
+type Canvas = canvas.Canvas
+
+// Cell is a single character cell of a Canvas.
+type Cell = canvas.Cell
+
+// Character is a familiar definition with ASCII art and expression slots.
+type Character = canvas.Character
+
+// SVGOptions controls SVG output: font, colors and padding.
+type SVGOptions = export.SVGOptions
+
+// HTMLOptions controls HTML output: page wrapper, CSS classes and colors.
+type HTMLOptions = export.HTMLOptions
+
+// PNGOptions controls PNG output: scale, padding, colors and fonts for
+// characters the embedded fonts lack.
+type PNGOptions = export.PNGOptions
+
+// Font is a font file loaded for characters the embedded PNG fonts lack.
+type Font = export.Font
+
+// ChatOptions controls Markdown and Slack output: emoji decorations and
+// escaping for Slack's API.
+type ChatOptions = export.ChatOptions
+
+// Colors overrides the colors of individual character parts.
+// Each field accepts a hex code, an ANSI 256 number or a color name.
+type Colors struct {
+	Outline string
+	Eyes    string
+	Mouth   string
+}
+
+// Options configures how a message is rendered. The zero value renders the
+// default character with the default theme in a 40 column speech bubble.
+type Options struct {
+	Character     string // Character name or path to a character JSON file (default "default")
+	Theme         string // Theme name or path to a theme JSON file (default "default")
+	Mood          string // Mood expression defined by the theme or character (default "neutral")
+	BubbleStyle   string // Bubble style: say, think, shout, whisper, song, code (default "say")
+	TailDirection string // Tail direction: down, up, left, right (default "down")
+	Template      string // Custom bubble template name or path; overrides BubbleStyle
+	Width         int    // Bubble width in columns (default 40)
+	Colors        Colors // Per-part character color overrides
+	Effect        string // Visual effect (default "none"); only applied to rendered lines
+	CodeLanguage  string // Language for code bubbles (detected from the message if empty)
+	CodeStyle     string // Syntax highlighting style for code bubbles (default "monokai")
+	Align         string // Text alignment: left, center, right, justify (default from the bubble template)
+	Markdown      bool   // Render inline Markdown (bold, italic, code, strikethrough, lists) in the message
+	MaxLines      int    // Lines of text in the bubble before it overflows (0 = no limit, at least 2 to truncate)
+	Overflow      string // Overflow past MaxLines: truncate, split, page (default "truncate"); page is split except in Animate
+
+	// Animation settings, used by Animate.
+	TypingSpeed time.Duration // Delay per typed character; 0 disables the typing animation
+	Action      string        // Character action animation (wave, jump, blink, ...)
+	Idle        bool          // Play the character's idle animation
+	Duration    time.Duration // Animation duration; 0 runs until a key is pressed
+}
+
+// scene holds the resolved values for a set of Options.
+type scene struct {
+	renderer *character.Renderer
+	char     *canvas.Character
+	theme    personality.Theme
+	style    bubble.Style
+	tail     canvas.TailDirection
+	effect   effects.Effect
+	opts     Options
+}
+
+// resolve validates opts and looks up the theme, character and styles it names.
+func resolve(opts Options) (*scene, error) {
+	if opts.Width == 0 {
+		opts.Width = 40
+	}
+	if opts.Width < 0 {
+		return nil, customerrors.NewValidationError("width", opts.Width, "must be greater than 0")
+	}
+
+	themeName := opts.Theme
+	if themeName == "" {
+		themeName = "default"
+	}
+	theme, err := personality.GetOrLoadTheme(themeName)
+	if err != nil {
+		return nil, err
+	}
+
+	styleName := strings.ToLower(opts.BubbleStyle)
+	if styleName == "" {
+		styleName = "say"
+	}
+	if !slices.Contains(bubble.AllStyles(), styleName) {
+		return nil, customerrors.NewValidationError("bubble style", opts.BubbleStyle, "unknown bubble style")
+	}
+
+	var tail canvas.TailDirection
+	switch strings.ToLower(opts.TailDirection) {
+	case "", "down":
+		tail = canvas.TailDown
+	case "up":
+		tail = canvas.TailUp
+	case "left":
+		tail = canvas.TailLeft
+	case "right":
+		tail = canvas.TailRight
+	default:
+		return nil, customerrors.NewValidationError("tail direction", opts.TailDirection, "must be down, up, left or right")
+	}
+
+	effect := effects.Effect(strings.ToLower(opts.Effect))
+	if effect == "" {
+		effect = effects.EffectNone
+	}
+	if !slices.Contains(effects.AllEffects(), effect) {
+		return nil, customerrors.NewValidationError("effect", opts.Effect, "unknown effect")
+	}
+
+	if opts.CodeLanguage != "" && !bubble.IsValidLanguage(opts.CodeLanguage) {
+		return nil, customerrors.NewValidationError("code language", opts.CodeLanguage, "unknown language")
+	}
+	if opts.CodeStyle != "" && !bubble.IsValidStyle(opts.CodeStyle) {
+		return nil, customerrors.NewValidationError("code style", opts.CodeStyle, "unknown highlighting style")
+	}
+
+	var align textwrap.Alignment
+	if opts.Align != "" {
+		var ok bool
+		if align, ok = textwrap.ParseAlignment(opts.Align); !ok {
+			return nil, customerrors.NewValidationError("align", opts.Align, "must be left, center, right or justify")
+		}
+	}
+
+	if opts.MaxLines < 0 {
+		return nil, customerrors.NewValidationError("max lines", opts.MaxLines, "must be non-negative")
+	}
+	overflow := strings.ToLower(opts.Overflow)
+	if overflow == "" {
+		overflow = canvas.OverflowTruncate
+	}
+	if !slices.Contains(canvas.Overflows(), overflow) {
+		return nil, customerrors.NewValidationError("overflow", opts.Overflow, "must be truncate, split or page")
+	}
+	if opts.MaxLines == 1 && overflow == canvas.OverflowTruncate {
+		return nil, customerrors.NewValidationError("max lines", opts.MaxLines, "must be at least 2 to truncate, leaving a line for the ellipsis")
+	}
+
+	for _, c := range []string{opts.Colors.Outline, opts.Colors.Eyes, opts.Colors.Mouth} {
+		if !canvas.ValidateColor(c) {
+			return nil, customerrors.NewColorParseError(c, customerrors.ErrInvalidColorFormat)
+		}
+	}
+
+	charName := opts.Character
+	if charName == "" {
+		charName = "default"
+	}
+	char, err := character.LoadCharacter(charName)
+	if err != nil {
+		return nil, err
+	}
+
+	mood := personality.Mood(strings.ToLower(opts.Mood))
+	if mood == "" {
+		mood = personality.MoodNeutral
+	}
+	if !character.HasMood(char, theme, mood) {
+		return nil, customerrors.NewValidationError("mood", opts.Mood, "not defined by the theme or character")
+	}
+
+	renderer := character.NewRenderer(theme, mood, opts.Width)
+	renderer.CustomTemplate = opts.Template
+	renderer.CodeLanguage = opts.CodeLanguage
+	renderer.CodeStyle = opts.CodeStyle
+	renderer.Align = align
+	renderer.Markdown = opts.Markdown
+	renderer.MaxLines = opts.MaxLines
+	renderer.Overflow = overflow
+	if overflow == canvas.OverflowPage {
+		renderer.Overflow = canvas.OverflowSplit // Pages can only be turned by Animate
+	}
+	if opts.Colors != (Colors{}) {
+		renderer.CharColors = &canvas.CharacterColors{
+			Outline: opts.Colors.Outline,
+			Eyes:    opts.Colors.Eyes,
+			Mouth:   opts.Colors.Mouth,
+		}
+	}
+
+	return &scene{
+		renderer: renderer,
+		char:     char,
+		theme:    theme,
+		style:    bubble.ParseStyle(styleName),
+		tail:     tail,
+		effect:   effect,
+		opts:     opts,
+	}, nil
+}
+
+// RenderCanvas composes the message and character into a Canvas.
+// Effects are not applied, since they operate on rendered lines.
+func RenderCanvas(message string, opts Options) (*Canvas, error) {
+	s, err := resolve(opts)
+	if err != nil {
+		return nil, err
+	}
+	return s.renderer.Compose(message, s.char, s.style, s.tail), nil
+}
+
+// Render renders the message and character to styled terminal lines,
+// with the configured effect applied.
+func Render(message string, opts Options) ([]string, error) {
+	s, err := resolve(opts)
+	if err != nil {
+		return nil, err
+	}
+	lines := s.renderer.Compose(message, s.char, s.style, s.tail).Render()
+	return effects.Apply(lines, s.effect), nil
+}
+
+// RenderSVG renders the message and character to an SVG image. Effects are
+// not applied.
+func RenderSVG(message string, opts Options, svg SVGOptions) ([]byte, error) {
+	c, err := RenderCanvas(message, opts)
+	if err != nil {
+		return nil, err
+	}
+	return export.SVG(c, svg), nil
+}
+
+// RenderMarkdown renders the message and character as plain text in a
+// Markdown code block, safe to paste into chat. Effects are not applied.
+func RenderMarkdown(message string, opts Options, chat ChatOptions) ([]byte, error) {
+	c, err := RenderCanvas(message, opts)
+	if err != nil {
+		return nil, err
+	}
+	return export.Markdown(c, chat), nil
+}
+
+// RenderSlack renders the message and character as plain text in a code
+// block in Slack's mrkdwn format, ready to paste. Set chat.SlackAPI to escape
+// it for posting through Slack's API. Effects are not applied.
+func RenderSlack(message string, opts Options, chat ChatOptions) ([]byte, error) {
+	c, err := RenderCanvas(message, opts)
+	if err != nil {
+		return nil, err
+	}
+	return export.Slack(c, chat), nil
+}
+
+// RenderJSON renders the message and character to a versioned JSON
+// description of the scene: its plain lines, the bounds of the bubble,
+// connector and character, every visible cell with its colors, and the
+// resolved character, theme, mood and template. Effects are not applied.
+func RenderJSON(message string, opts Options) ([]byte, error) {
+	s, err := resolve(opts)
+	if err != nil {
+		return nil, err
+	}
+	c, info := s.renderer.ComposeScene(message, s.char, s.style, s.tail)
+	return export.JSON(c, s.theme.Name, []export.JSONPanel{{
+		Character: info.Character,
+		Mood:      string(info.Mood),
+		Template:  info.Template,
+		Bounds:    export.NewJSONBounds(info.Layout),
+	}}), nil
+}
+
+// RenderPNG renders the message and character to a PNG image using an
+// embedded bitmap font. Effects are not applied.
+func RenderPNG(message string, opts Options, png PNGOptions) ([]byte, error) {
+	c, err := RenderCanvas(message, opts)
+	if err != nil {
+		return nil, err
+	}
+	return export.PNG(c, png), nil
+}
+
+// RenderHTML renders the message and character to HTML. If opts request a
+// character animation, its frames are cycled with CSS keyframes. Typing and
+// effects are not applied.
+func RenderHTML(message string, opts Options, html HTMLOptions) ([]byte, error) {
+	s, err := resolve(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	anim := s.characterAnimation()
+	if anim == nil {
+		err = export.WriteHTML(&buf, s.renderer.Compose(message, s.char, s.style, s.tail), html)
+		return buf.Bytes(), err
+	}
+
+	scenes := animation.NewCharacterModel(s.characterAnimationConfig(message, anim)).Frames()
+	frames := make([]export.Frame, len(scenes))
+	for i, scene := range scenes {
+		frames[i] = export.Frame{Canvas: scene.Canvas, Duration: scene.Duration}
+	}
+	err = export.WriteHTMLAnimation(&buf, frames, anim.Loop, html)
+	return buf.Bytes(), err
+}
+
+// RenderGIF renders the message and character to an animated GIF. The
+// character animation and typing requested by opts are recorded for one
+// loop, or opts.Duration if set; effects are not applied.
+func RenderGIF(message string, opts Options, gif PNGOptions) ([]byte, error) {
+	s, err := resolve(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	anim := s.characterAnimation()
+	scenes := animation.Record(s.characterAnimationConfig(message, anim))
+	frames := make([]export.Frame, len(scenes))
+	for i, scene := range scenes {
+		frames[i] = export.Frame{Canvas: scene.Canvas, Duration: scene.Duration}
+	}
+
+	var buf bytes.Buffer
+	err = export.WriteGIF(&buf, frames, anim != nil && anim.Loop, gif)
+	return buf.Bytes(), err
+}
+
+// Fprint renders the message and writes the lines to w.
+func Fprint(w io.Writer, message string, opts Options) error {
+	lines, err := Render(message, opts)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Animate plays the typing and character animations configured in opts,
+// writing frames to w. It returns when the animation finishes, a key is
+// pressed, or ctx is cancelled. Without any animation options set it behaves
+// like Fprint. With Overflow "page" and w a terminal, text longer than
+// MaxLines is paged through with keys until the last page is turned or q is
+// pressed; otherwise the pages are shown as split bubbles.
+func Animate(ctx context.Context, w io.Writer, message string, opts Options) error {
+	s, err := resolve(opts)
+	if err != nil {
+		return err
+	}
+
+	if strings.EqualFold(opts.Overflow, canvas.OverflowPage) && opts.MaxLines > 0 && isTerminal(w) {
+		if config := s.characterAnimationConfig(message, s.characterAnimation()); config.Pages() > 1 {
+			return animation.PlayPagerContext(ctx, w, config)
+		}
+	}
+	if anim := s.characterAnimation(); anim != nil {
+		return animation.AnimateCharacterContext(ctx, w, s.characterAnimationConfig(message, anim))
+	}
+
+	lines := effects.Apply(s.renderer.Compose(message, s.char, s.style, s.tail).Render(), s.effect)
+	if opts.TypingSpeed > 0 {
+		return animation.AnimateContext(ctx, w, lines, animation.AnimationTyping, opts.TypingSpeed)
+	}
+	return animation.AnimateContext(ctx, w, lines, animation.AnimationNone, 0)
+}
+
+// isTerminal reports whether w is a terminal keys can be read from.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// characterAnimationConfig configures the character animation anim for the
+// message.
+func (s *scene) characterAnimationConfig(message string, anim *canvas.AnimationSequence) animation.CharacterAnimationConfig {
+	expr := s.renderer.Expression(s.char)
+	return animation.CharacterAnimationConfig{
+		Character:      s.char,
+		Animation:      anim,
+		BubbleText:     message,
+		BubbleWidth:    s.renderer.BubbleWidth,
+		BubbleStyle:    character.BubbleStyleToCanvasStyle(s.style),
+		BubbleColor:    s.theme.BubbleStyle,
+		CharColors:     s.renderer.CharColors,
+		CharColor:      s.theme.CharacterStyle,
+		DefaultEyes:    expr.Eyes,
+		DefaultMouth:   expr.Tongue,
+		TypingSpeed:    s.opts.TypingSpeed,
+		Duration:       s.opts.Duration,
+		Effect:         s.effect,
+		CodeLanguage:   s.opts.CodeLanguage,
+		CodeStyle:      s.opts.CodeStyle,
+		Align:          s.renderer.Align,
+		Markdown:       s.renderer.Markdown,
+		MaxLines:       s.renderer.MaxLines,
+		Overflow:       s.renderer.Overflow,
+		TailDirection:  s.tail,
+		CustomTemplate: s.renderer.CustomTemplate,
+	}
+}
+
+// characterAnimation returns the animation requested by the options, or nil
+// if none was requested or the character does not define it.
+func (s *scene) characterAnimation() *canvas.AnimationSequence {
+	if s.opts.Action != "" && s.opts.Action != "none" {
+		return s.char.GetAnimation(s.opts.Action)
+	}
+	if !s.opts.Idle {
+		return nil
+	}
+	for _, name := range []string{"idle", "blink", s.char.DefaultAnimation} {
+		if anim := s.char.GetAnimation(name); anim != nil {
+			return anim
+		}
+	}
+	return nil
+}
+
+// Characters returns the names of all available characters.
+func Characters() []string {
+	return character.ListCharacters()
+}
+
+// LoadFont loads a TrueType or OpenType font file, or the first font of a
+// collection, for PNGOptions.Fonts.
+func LoadFont(path string) (*Font, error) {
+	return export.LoadFont(path)
+}
+
+// LoadCharacter loads a character by name or from a JSON file path.
+func LoadCharacter(name string) (*Character, error) {
+	return character.LoadCharacter(name)
+}
+
+// Themes returns the names of all available themes, including custom themes
+// from the user's config directory.
+func Themes() []string {
+	return personality.AllThemes()
+}
+
+// Moods returns the names of the standard moods every builtin theme defines.
+// Use MoodsFor to include custom moods from a theme or character.
+func Moods() []string {
+	moods := personality.AllMoods()
+	names := make([]string, len(moods))
+	for i, m := range moods {
+		names[i] = string(m)
+	}
+	return names
+}
+
+// MoodsFor returns the moods available to the character and theme named in
+// opts, including custom moods either of them defines.
+func MoodsFor(opts Options) ([]string, error) {
+	opts.Mood = ""
+	s, err := resolve(opts)
+	if err != nil {
+		return nil, err
+	}
+	moods := character.AvailableMoods(s.char, s.theme)
+	names := make([]string, len(moods))
+	for i, m := range moods {
+		names[i] = string(m)
+	}
+	return names, nil
+}
+
+// BubbleStyles returns the names of all builtin bubble styles.
+func BubbleStyles() []string {
+	return bubble.AllStyles()
+}
+
+// Effects returns the names of all available effects.
+func Effects() []string {
+	all := effects.AllEffects()
+	names := make([]string, len(all))
+	for i, e := range all {
+		names[i] = string(e)
+	}
+	return names
+}
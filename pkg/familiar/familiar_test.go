@@ -0,0 +1,305 @@
+package familiar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image/gif"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestRender tests rendering with the zero Options
+func TestRender(t *testing.T) {
+	lines, err := Render("Hello, world!", Options{})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(strings.Join(lines, "\n"), "Hello, world!") {
+		t.Error("Output doesn't contain the message")
+	}
+}
+
+// TestRenderCanvas tests that the composed canvas is returned
+func TestRenderCanvas(t *testing.T) {
+	c, err := RenderCanvas("Meow", Options{Character: "cat", Mood: "happy", BubbleStyle: "think"})
+	if err != nil {
+		t.Fatalf("RenderCanvas failed: %v", err)
+	}
+	if c.Width == 0 || c.Height == 0 {
+		t.Fatalf("Canvas has no size: %dx%d", c.Width, c.Height)
+	}
+	if !strings.Contains(strings.Join(c.RenderPlain(), "\n"), "( Meow )") {
+		t.Error("Canvas doesn't contain a thought bubble with the message")
+	}
+}
+
+// TestRenderAlign tests aligning the message in the bubble
+func TestRenderAlign(t *testing.T) {
+	c, err := RenderCanvas("one two three four five", Options{Width: 9, Align: "right"})
+	if err != nil {
+		t.Fatalf("RenderCanvas failed: %v", err)
+	}
+	if !strings.Contains(strings.Join(c.RenderPlain(), "\n"), "|     three |") {
+		t.Errorf("Canvas doesn't contain the right-aligned line:\n%s", strings.Join(c.RenderPlain(), "\n"))
+	}
+}
+
+// TestRenderMarkdown tests rendering inline Markdown in the message
+func TestRenderMarkdown(t *testing.T) {
+	c, err := RenderCanvas("**Deployed** to _prod_", Options{Markdown: true})
+	if err != nil {
+		t.Fatalf("RenderCanvas failed: %v", err)
+	}
+	if !strings.Contains(strings.Join(c.RenderPlain(), "\n"), "< Deployed to prod >") {
+		t.Errorf("Canvas doesn't contain the message without markers:\n%s", strings.Join(c.RenderPlain(), "\n"))
+	}
+}
+
+// TestRenderOverflow tests text longer than MaxLines
+func TestRenderOverflow(t *testing.T) {
+	tests := []struct {
+		overflow string
+		want     string
+	}{
+		{"", "\\ …         /"},
+		{"split", "< four five >"},
+		{"page", "< four five >"}, // Pages are split outside Animate
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.overflow, func(t *testing.T) {
+			c, err := RenderCanvas("one two three four five", Options{Width: 9, MaxLines: 2, Overflow: tt.overflow})
+			if err != nil {
+				t.Fatalf("RenderCanvas failed: %v", err)
+			}
+			if text := strings.Join(c.RenderPlain(), "\n"); !strings.Contains(text, tt.want) {
+				t.Errorf("Canvas doesn't contain %q:\n%s", tt.want, text)
+			}
+		})
+	}
+}
+
+// TestRenderInvalidOptions tests that bad option values are reported
+func TestRenderInvalidOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"unknown theme", Options{Theme: "nope"}},
+		{"unknown mood", Options{Mood: "nope"}},
+		{"unknown bubble style", Options{BubbleStyle: "nope"}},
+		{"unknown tail direction", Options{TailDirection: "sideways"}},
+		{"unknown effect", Options{Effect: "nope"}},
+		{"invalid color", Options{Colors: Colors{Eyes: "not-a-color"}}},
+		{"unknown character", Options{Character: "nonexistent"}},
+		{"negative width", Options{Width: -1}},
+		{"unknown code language", Options{BubbleStyle: "code", CodeLanguage: "nope"}},
+		{"unknown code style", Options{BubbleStyle: "code", CodeStyle: "nope"}},
+		{"unknown alignment", Options{Align: "middle"}},
+		{"negative max lines", Options{MaxLines: -1}},
+		{"unknown overflow", Options{Overflow: "scroll"}},
+		{"truncate to one line", Options{MaxLines: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Render("test", tt.opts); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+// TestFprint tests writing rendered output to a writer
+func TestFprint(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Fprint(&buf, "Hoot", Options{Character: "owl"}); err != nil {
+		t.Fatalf("Fprint failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Hoot") {
+		t.Error("Written output doesn't contain the message")
+	}
+}
+
+// TestAnimateWithoutAnimation tests that Animate falls back to plain output
+func TestAnimateWithoutAnimation(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Animate(context.Background(), &buf, "Static", Options{}); err != nil {
+		t.Fatalf("Animate failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Static") {
+		t.Error("Written output doesn't contain the message")
+	}
+}
+
+// TestAnimatePageNotTerminal tests that paged text written to a buffer is
+// split instead of waiting for keys
+func TestAnimatePageNotTerminal(t *testing.T) {
+	var buf bytes.Buffer
+	opts := Options{Width: 9, MaxLines: 2, Overflow: "page"}
+	if err := Animate(context.Background(), &buf, "one two three four five", opts); err != nil {
+		t.Fatalf("Animate failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "one") || !strings.Contains(buf.String(), "five") {
+		t.Errorf("Expected every page as split bubbles, got:\n%s", buf.String())
+	}
+}
+
+// TestLists tests the name listing helpers
+func TestLists(t *testing.T) {
+	if len(Characters()) == 0 {
+		t.Error("Characters returned empty list")
+	}
+	if len(Themes()) == 0 {
+		t.Error("Themes returned empty list")
+	}
+	if len(Moods()) == 0 {
+		t.Error("Moods returned empty list")
+	}
+	if len(BubbleStyles()) == 0 {
+		t.Error("BubbleStyles returned empty list")
+	}
+	if len(Effects()) == 0 {
+		t.Error("Effects returned empty list")
+	}
+}
+
+// TestCharacterMoods tests moods defined by a character's own expressions
+func TestCharacterMoods(t *testing.T) {
+	lines, err := Render("Hoot", Options{Character: "owl", Mood: "wise"})
+	if err != nil {
+		t.Fatalf("Render with character mood failed: %v", err)
+	}
+	if !strings.Contains(strings.Join(lines, "\n"), "==") {
+		t.Error("Expected the owl's wise eyes in the output")
+	}
+
+	moods, err := MoodsFor(Options{Character: "owl"})
+	if err != nil {
+		t.Fatalf("MoodsFor failed: %v", err)
+	}
+	if !slices.Contains(moods, "wise") || !slices.Contains(moods, "happy") {
+		t.Errorf("MoodsFor(owl) = %v, want it to include wise and happy", moods)
+	}
+
+	if _, err := Render("Hoot", Options{Character: "cat", Mood: "wise"}); err == nil {
+		t.Error("Expected error for a mood the cat does not define")
+	}
+}
+
+// TestRenderSVG tests rendering to an SVG image
+func TestRenderSVG(t *testing.T) {
+	svg, err := RenderSVG("Meow", Options{Character: "cat"}, SVGOptions{})
+	if err != nil {
+		t.Fatalf("RenderSVG failed: %v", err)
+	}
+	if !bytes.HasPrefix(svg, []byte("<svg ")) || !bytes.Contains(svg, []byte("Meow")) {
+		t.Errorf("Unexpected SVG output:\n%s", svg)
+	}
+
+	if _, err := RenderSVG("Meow", Options{Theme: "nope"}, SVGOptions{}); err == nil {
+		t.Error("Expected an error for an unknown theme")
+	}
+}
+
+// TestRenderHTML tests rendering still and animated HTML
+func TestRenderHTML(t *testing.T) {
+	still, err := RenderHTML("Meow", Options{Character: "cat"}, HTMLOptions{})
+	if err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+	if !bytes.HasPrefix(still, []byte(`<pre class="familiar"`)) || bytes.Contains(still, []byte("@keyframes")) {
+		t.Errorf("Expected a static <pre> block:\n%s", still)
+	}
+
+	animated, err := RenderHTML("Meow", Options{Character: "cat", Action: "wave"}, HTMLOptions{Standalone: true})
+	if err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+	if !bytes.Contains(animated, []byte("<!DOCTYPE html>")) || !bytes.Contains(animated, []byte("@keyframes")) {
+		t.Errorf("Expected an animated page:\n%s", animated)
+	}
+}
+
+// TestRenderChat tests rendering chat-safe code blocks
+func TestRenderChat(t *testing.T) {
+	md, err := RenderMarkdown("Meow", Options{Character: "cat", Theme: "rainbow"}, ChatOptions{})
+	if err != nil {
+		t.Fatalf("RenderMarkdown failed: %v", err)
+	}
+	if !bytes.HasPrefix(md, []byte("```\n")) || !bytes.Contains(md, []byte("< Meow >")) || bytes.Contains(md, []byte("\x1b")) {
+		t.Errorf("Expected a plain code block:\n%s", md)
+	}
+
+	slack, err := RenderSlack("Meow", Options{Character: "cat"}, ChatOptions{})
+	if err != nil {
+		t.Fatalf("RenderSlack failed: %v", err)
+	}
+	if !bytes.Contains(slack, []byte("< Meow >")) {
+		t.Errorf("Expected bubble edges as they are for pasting:\n%s", slack)
+	}
+
+	slack, err = RenderSlack("Meow", Options{Character: "cat"}, ChatOptions{SlackAPI: true})
+	if err != nil {
+		t.Fatalf("RenderSlack failed: %v", err)
+	}
+	if !bytes.Contains(slack, []byte("&lt; Meow &gt;")) {
+		t.Errorf("Expected escaped bubble edges for the API:\n%s", slack)
+	}
+}
+
+// TestRenderJSON tests rendering a JSON scene description
+func TestRenderJSON(t *testing.T) {
+	data, err := RenderJSON("Meow", Options{Character: "cat", Mood: "happy", Theme: "rainbow", BubbleStyle: "think"})
+	if err != nil {
+		t.Fatalf("RenderJSON failed: %v", err)
+	}
+
+	var scene struct {
+		Version int      `json:"version"`
+		Theme   string   `json:"theme"`
+		Lines   []string `json:"lines"`
+		Panels  []struct {
+			Character string `json:"character"`
+			Mood      string `json:"mood"`
+			Template  string `json:"template"`
+		} `json:"panels"`
+	}
+	if err := json.Unmarshal(data, &scene); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	if scene.Version != 1 || scene.Theme != "rainbow" || !strings.Contains(strings.Join(scene.Lines, "\n"), "( Meow )") {
+		t.Errorf("Unexpected scene:\n%s", data)
+	}
+	if len(scene.Panels) != 1 || scene.Panels[0].Character != "cat" || scene.Panels[0].Mood != "happy" || scene.Panels[0].Template != "think" {
+		t.Errorf("Unexpected panels: %+v", scene.Panels)
+	}
+}
+
+// TestRenderPNG tests rendering to a PNG image
+func TestRenderPNG(t *testing.T) {
+	data, err := RenderPNG("Meow", Options{Character: "cat"}, PNGOptions{Scale: 1})
+	if err != nil {
+		t.Fatalf("RenderPNG failed: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("\x89PNG")) {
+		t.Error("Output is not a PNG image")
+	}
+}
+
+// TestRenderGIF tests rendering an animation to a GIF image
+func TestRenderGIF(t *testing.T) {
+	data, err := RenderGIF("Meow", Options{Character: "cat", Action: "wave"}, PNGOptions{Scale: 1})
+	if err != nil {
+		t.Fatalf("RenderGIF failed: %v", err)
+	}
+	decoded, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Output is not a GIF image: %v", err)
+	}
+	if len(decoded.Image) < 2 {
+		t.Errorf("Expected several frames, got %d", len(decoded.Image))
+	}
+}